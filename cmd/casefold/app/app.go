@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	pgrepo "code/internal/adapters/postgres"
+	"code/internal/platform/config"
+	"code/internal/platform/postgres"
+)
+
+var errCollisions = errors.New("short names differ only in case")
+
+type report struct {
+	CaseInsensitive bool        `json:"case_insensitive"`
+	Collisions      []collision `json:"collisions"`
+}
+
+type collision struct {
+	Domain string          `json:"domain,omitempty"`
+	Folded string          `json:"folded"`
+	Links  []collisionLink `json:"links"`
+}
+
+type collisionLink struct {
+	ID        int64  `json:"id"`
+	ShortName string `json:"short_name"`
+}
+
+// Run reports links whose short names differ only in case and, with -apply
+// and no collisions left, makes short names unique regardless of case so
+// SHORT_NAME_CASE_INSENSITIVE can be enabled. It prints a JSON report to
+// stdout and fails while collisions exist.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("casefold", flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "create the case-insensitive unique index when there are no collisions")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: casefold [-apply]")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := postgres.Open(ctx, postgres.OpenConfig{
+		DSN:             cfg.DatabaseURL,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+	})
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	repo := pgrepo.NewRepo(db)
+
+	found, err := repo.ShortNameCollisions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *apply && len(found) == 0 {
+		if err := repo.EnableCaseInsensitiveShortNames(ctx); err != nil {
+			return err
+		}
+	}
+
+	enabled, err := repo.CaseInsensitiveShortNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(newReport(enabled, found)); err != nil {
+		return err
+	}
+
+	if len(found) > 0 {
+		return fmt.Errorf("%w: %d groups; rename all but one link in each", errCollisions, len(found))
+	}
+
+	return nil
+}
+
+func newReport(enabled bool, found []pgrepo.ShortNameCollision) report {
+	out := report{CaseInsensitive: enabled, Collisions: make([]collision, 0, len(found))}
+
+	for _, c := range found {
+		item := collision{Domain: c.Domain, Folded: c.Folded}
+		for _, l := range c.Links {
+			item.Links = append(item.Links, collisionLink{ID: l.ID, ShortName: l.ShortName})
+		}
+
+		out.Collisions = append(out.Collisions, item)
+	}
+
+	return out
+}
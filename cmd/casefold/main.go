@@ -0,0 +1,15 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"code/cmd/casefold/app"
+)
+
+func main() {
+	if err := app.Run(os.Args[1:]); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"code/internal/adapters/blocklist"
+	"code/internal/adapters/linkio"
+	"code/internal/app/links"
+	"code/internal/assembly/apiapp"
+	"code/internal/platform/config"
+	"code/internal/platform/postgres"
+)
+
+const defaultActor = "cli:import"
+
+var errRowsFailed = errors.New("some rows failed")
+
+type summary struct {
+	DryRun  bool `json:"dry_run"`
+	Rows    int  `json:"rows"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Skipped int  `json:"skipped"`
+	Failed  int  `json:"failed"`
+}
+
+// Run imports links from a CSV or NDJSON file using the same validation,
+// quotas and audit trail as the API. It prints a JSON summary to stdout and
+// fails when any row could not be imported.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "input format: csv or ndjson (default: from file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate and report without saving")
+	onConflict := fs.String("on-conflict", string(links.ConflictFail), "existing short names: skip, overwrite or fail")
+	reportPath := fs.String("report", "", "write failed rows as CSV to this file")
+	actor := fs.String("actor", defaultActor, "actor recorded in the audit log")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import [flags] <file>")
+	}
+
+	path := fs.Arg(0)
+
+	f, err := resolveFormat(*format, path)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := postgres.Open(ctx, postgres.OpenConfig{
+		DSN:             cfg.DatabaseURL,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+	})
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	src, err := linkio.NewReader(in, f)
+	if err != nil {
+		return err
+	}
+
+	shortNames, err := apiapp.LoadShortNamePolicy(cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := []links.Option{links.WithShortNamePolicy(shortNames)}
+
+	if cfg.BlocklistFile != "" {
+		bl, err := blocklist.Open(cfg.BlocklistFile)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, links.WithBlocklist(bl))
+	}
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	svc := apiapp.NewLinksService(db, cfg, logger, opts...)
+
+	ctx = links.ContextWithAuditMeta(ctx, links.AuditMeta{Actor: *actor})
+
+	res, err := svc.ImportLinks(ctx, src, links.ImportOptions{
+		DryRun:     *dryRun,
+		OnConflict: links.ConflictPolicy(*onConflict),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(summary{
+		DryRun:  *dryRun,
+		Rows:    res.Rows,
+		Created: res.Created,
+		Updated: res.Updated,
+		Skipped: res.Skipped,
+		Failed:  res.Failed,
+	}); err != nil {
+		return err
+	}
+
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, res.Errors); err != nil {
+			return err
+		}
+	}
+
+	if res.Failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errRowsFailed, res.Failed, res.Rows)
+	}
+
+	return nil
+}
+
+func resolveFormat(flagValue, path string) (linkio.Format, error) {
+	switch linkio.Format(flagValue) {
+	case linkio.FormatCSV, linkio.FormatNDJSON:
+		return linkio.Format(flagValue), nil
+	case "":
+		return linkio.FormatFromPath(path)
+	default:
+		return "", linkio.ErrUnsupportedFormat
+	}
+}
+
+func writeReport(path string, rows []links.ImportRowError) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := linkio.WriteErrorReport(out, rows); err != nil {
+		_ = out.Close()
+
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS usage_counters (
+  name   TEXT NOT NULL,
+  period DATE NOT NULL,
+  used   BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (name, period)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS usage_counters;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- link_id has no foreign key on purpose: events must outlive deleted links.
+CREATE TABLE IF NOT EXISTS audit_events (
+  id          BIGSERIAL PRIMARY KEY,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  action      TEXT NOT NULL,
+  link_id     BIGINT NOT NULL,
+  actor       TEXT NOT NULL,
+  request_id  TEXT NOT NULL DEFAULT '',
+  ip          TEXT NOT NULL DEFAULT '',
+  before_link JSONB NOT NULL DEFAULT 'null',
+  after_link  JSONB NOT NULL DEFAULT 'null'
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at
+  ON audit_events (created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_link_id_created_at
+  ON audit_events (link_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
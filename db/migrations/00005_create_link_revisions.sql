@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS link_revisions (
+  id           BIGSERIAL PRIMARY KEY,
+  link_id      BIGINT NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+  original_url TEXT NOT NULL,
+  short_name   TEXT NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_revisions_link_id_id
+  ON link_revisions (link_id, id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS link_revisions;
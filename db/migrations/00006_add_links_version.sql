@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE links
+  DROP COLUMN IF EXISTS version;
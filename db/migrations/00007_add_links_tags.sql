@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS links_tags_idx ON links USING GIN (tags jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS links_tags_idx;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE links
+  DROP COLUMN IF EXISTS disabled;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- status is NULL while the first request holding the key is still running.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  key          TEXT PRIMARY KEY,
+  request_hash BYTEA NOT NULL,
+  status       INTEGER,
+  headers      JSONB NOT NULL DEFAULT '{}',
+  body         BYTEA NOT NULL DEFAULT '',
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+  ON idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
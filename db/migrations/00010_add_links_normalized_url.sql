@@ -0,0 +1,18 @@
+-- +goose Up
+-- Existing rows are backfilled with their original URL; they are normalized
+-- on their next update.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS normalized_url TEXT NOT NULL DEFAULT '';
+
+UPDATE links
+SET normalized_url = original_url
+WHERE normalized_url = '';
+
+CREATE INDEX IF NOT EXISTS idx_links_normalized_url
+  ON links (normalized_url);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_links_normalized_url;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS normalized_url;
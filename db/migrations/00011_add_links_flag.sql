@@ -0,0 +1,17 @@
+-- +goose Up
+-- Links disabled by the blocklist rescan carry the time and the matching
+-- entry, so they can be reviewed.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS flagged_at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS flag_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_links_flagged_at
+  ON links (flagged_at)
+  WHERE flagged_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_links_flagged_at;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS flag_reason,
+  DROP COLUMN IF EXISTS flagged_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Feeds the sequential and hashid short name strategies. Values are never
+-- reused, so the codes they encode never collide with each other.
+CREATE SEQUENCE IF NOT EXISTS links_short_name_seq AS BIGINT;
+
+-- +goose Down
+DROP SEQUENCE IF EXISTS links_short_name_seq;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Serves case-insensitive short name lookups. Case-insensitive uniqueness
+-- needs the unique links_short_name_lower_key index instead, which
+-- cmd/casefold creates once existing case collisions are resolved.
+CREATE INDEX IF NOT EXISTS idx_links_short_name_lower
+  ON links (lower(short_name));
+
+-- +goose Down
+DROP INDEX IF EXISTS links_short_name_lower_key;
+DROP INDEX IF EXISTS idx_links_short_name_lower;
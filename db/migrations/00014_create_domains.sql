@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS domains (
+  id         BIGSERIAL PRIMARY KEY,
+  host       TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Links without a domain are served on the BASE_URL host. Short names are
+-- unique per domain, and domains cannot be removed while links use them.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS domain TEXT NULL REFERENCES domains (host) ON DELETE RESTRICT;
+
+ALTER TABLE links
+  DROP CONSTRAINT IF EXISTS links_short_name_key,
+  ADD CONSTRAINT links_short_name_domain_key UNIQUE NULLS NOT DISTINCT (short_name, domain);
+
+-- The case-insensitive unique index, when cmd/casefold created it, becomes
+-- per domain as well. The old index was stricter, so this cannot fail.
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM pg_indexes
+    WHERE tablename = 'links' AND indexname = 'links_short_name_lower_key'
+  ) THEN
+    DROP INDEX links_short_name_lower_key;
+    CREATE UNIQUE INDEX links_short_name_lower_key
+      ON links (lower(short_name), domain) NULLS NOT DISTINCT;
+  END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- Fails while two domains share a short name.
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM pg_indexes
+    WHERE tablename = 'links' AND indexname = 'links_short_name_lower_key'
+  ) THEN
+    DROP INDEX links_short_name_lower_key;
+    CREATE UNIQUE INDEX links_short_name_lower_key
+      ON links (lower(short_name));
+  END IF;
+END $$;
+-- +goose StatementEnd
+
+ALTER TABLE links
+  DROP CONSTRAINT IF EXISTS links_short_name_domain_key,
+  ADD CONSTRAINT links_short_name_key UNIQUE (short_name);
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS domain;
+
+DROP TABLE IF EXISTS domains;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Optional human-readable title, shown on the link preview page.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE links
+  DROP COLUMN IF EXISTS title;
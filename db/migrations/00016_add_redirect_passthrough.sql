@@ -0,0 +1,18 @@
+-- +goose Up
+-- Per-link forwarding of the path and query string that follow the short
+-- name, and the requested path on each visit.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS forward_path BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS forward_query TEXT NOT NULL DEFAULT ''
+    CONSTRAINT links_forward_query_check CHECK (forward_query IN ('', 'preserve', 'override', 'append'));
+
+ALTER TABLE link_visits
+  ADD COLUMN IF NOT EXISTS path TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE link_visits
+  DROP COLUMN IF EXISTS path;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS forward_query,
+  DROP COLUMN IF EXISTS forward_path;
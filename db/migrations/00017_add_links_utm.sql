@@ -0,0 +1,23 @@
+-- +goose Up
+-- UTM parameters are kept apart from the destination and added to it on
+-- redirect.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS utm_source TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS utm_medium TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS utm_campaign TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS utm_term TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS utm_content TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_links_utm_campaign
+  ON links (utm_campaign)
+  WHERE utm_campaign <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_links_utm_campaign;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS utm_content,
+  DROP COLUMN IF EXISTS utm_term,
+  DROP COLUMN IF EXISTS utm_campaign,
+  DROP COLUMN IF EXISTS utm_medium,
+  DROP COLUMN IF EXISTS utm_source;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Ordered per-link rules sending matching visitors to other destinations,
+-- and the index of the rule each visit matched.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS rules JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE link_visits
+  ADD COLUMN IF NOT EXISTS rule_index INTEGER;
+
+-- +goose Down
+ALTER TABLE link_visits
+  DROP COLUMN IF EXISTS rule_index;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS rules;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Weighted A/B destinations per link, and the index of the variant drawn for
+-- each visit.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]',
+  ADD COLUMN IF NOT EXISTS sticky_variant BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE link_visits
+  ADD COLUMN IF NOT EXISTS variant_index INTEGER;
+
+-- +goose Down
+ALTER TABLE link_visits
+  DROP COLUMN IF EXISTS variant_index;
+
+ALTER TABLE links
+  DROP COLUMN IF EXISTS sticky_variant,
+  DROP COLUMN IF EXISTS variants;
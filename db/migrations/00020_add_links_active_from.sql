@@ -0,0 +1,8 @@
+-- +goose Up
+-- When a link goes live; NULL links are live as soon as they are created.
+ALTER TABLE links
+  ADD COLUMN IF NOT EXISTS active_from TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE links
+  DROP COLUMN IF EXISTS active_from;
@@ -0,0 +1,85 @@
+// Package blocklist serves a domain.Blocklist read from a local file that
+// can be replaced while the process runs.
+package blocklist
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code/internal/domain"
+)
+
+// File matches against the last valid content of a blocklist file. It is
+// safe for concurrent use.
+type File struct {
+	path string
+	list atomic.Pointer[domain.Blocklist]
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// Open loads the blocklist at path; the file must exist and be valid.
+func Open(path string) (*File, error) {
+	f := &File{path: path}
+
+	if _, err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *File) Path() string {
+	return f.path
+}
+
+// Match implements links.Blocklist.
+func (f *File) Match(rawURL string) (string, bool) {
+	return f.list.Load().Match(rawURL)
+}
+
+// Len returns the number of entries currently loaded.
+func (f *File) Len() int {
+	return f.list.Load().Len()
+}
+
+// Reload re-reads the file when its size or modification time changed and
+// reports whether the list was replaced. On error the previous list stays in
+// use.
+func (f *File) Reload() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("blocklist: stat %s: %w", f.path, err)
+	}
+
+	if f.list.Load() != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return false, nil
+	}
+
+	in, err := os.Open(f.path)
+	if err != nil {
+		return false, fmt.Errorf("blocklist: open %s: %w", f.path, err)
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	list, err := domain.ParseBlocklist(in)
+	if err != nil {
+		return false, fmt.Errorf("blocklist: %s: %w", f.path, err)
+	}
+
+	f.list.Store(list)
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+
+	return true, nil
+}
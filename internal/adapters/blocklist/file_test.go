@@ -0,0 +1,84 @@
+package blocklist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/adapters/blocklist"
+	"code/internal/domain"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestFile_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	writeFile(t, path, "evil.test\n", start)
+
+	f, err := blocklist.Open(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, f.Len())
+
+	entry, ok := f.Match("https://evil.test/login")
+	require.True(t, ok)
+	require.Equal(t, "evil.test", entry)
+
+	changed, err := f.Reload()
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	writeFile(t, path, "phish.test\n*.phish.test\n", start.Add(time.Minute))
+
+	changed, err = f.Reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, 2, f.Len())
+
+	_, ok = f.Match("https://evil.test/login")
+	require.False(t, ok)
+
+	_, ok = f.Match("https://www.phish.test/")
+	require.True(t, ok)
+}
+
+func TestFile_ReloadKeepsListOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	writeFile(t, path, "evil.test\n", start)
+
+	f, err := blocklist.Open(path)
+	require.NoError(t, err)
+
+	writeFile(t, path, "evil.test/path\n", start.Add(time.Minute))
+
+	changed, err := f.Reload()
+	require.ErrorIs(t, err, domain.ErrInvalidBlocklistEntry)
+	require.False(t, changed)
+
+	_, ok := f.Match("https://evil.test/")
+	require.True(t, ok)
+
+	require.NoError(t, os.Remove(path))
+
+	_, err = f.Reload()
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, ok = f.Match("https://evil.test/")
+	require.True(t, ok)
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	_, err := blocklist.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,150 @@
+// Package geoip resolves the country of IP addresses from a local database
+// file, for country redirect rules.
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"code/internal/domain"
+)
+
+// unknownCountry marks reserved and unassigned ranges in DB-IP files.
+const unknownCountry = "ZZ"
+
+// ErrInvalidDatabase is returned for malformed or overlapping ranges.
+var ErrInvalidDatabase = errors.New("invalid geoip database")
+
+type ipRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// File holds the ranges of a CSV database in the DB-IP "IP to Country" layout:
+// one "first_ip,last_ip,country_code" row per range, IPv4 and IPv6 mixed;
+// further columns are ignored. It is read-only and safe for concurrent use.
+type File struct {
+	ranges []ipRange
+}
+
+// Open loads the database at path.
+func Open(path string) (*File, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: open %s: %w", path, err)
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	f, err := Parse(in)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Parse reads a database from r.
+func Parse(r io.Reader) (*File, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	var ranges []ipRange
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDatabase, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		rng, err := parseRange(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if rng.country != unknownCountry {
+			ranges = append(ranges, rng)
+		}
+	}
+
+	slices.SortFunc(ranges, func(a, b ipRange) int {
+		return a.start.Compare(b.start)
+	})
+
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].start.Compare(ranges[i-1].end) <= 0 {
+			return nil, fmt.Errorf("%w: %s overlaps %s", ErrInvalidDatabase, ranges[i].start, ranges[i-1].start)
+		}
+	}
+
+	return &File{ranges: ranges}, nil
+}
+
+func parseRange(record []string) (ipRange, error) {
+	if len(record) < 3 {
+		return ipRange{}, fmt.Errorf("%w: want at least 3 columns", ErrInvalidDatabase)
+	}
+
+	start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+	if err != nil {
+		return ipRange{}, fmt.Errorf("%w: %w", ErrInvalidDatabase, err)
+	}
+
+	end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+	if err != nil {
+		return ipRange{}, fmt.Errorf("%w: %w", ErrInvalidDatabase, err)
+	}
+
+	start, end = start.Unmap(), end.Unmap()
+	if start.Is4() != end.Is4() || start.Compare(end) > 0 {
+		return ipRange{}, fmt.Errorf("%w: bad range %s-%s", ErrInvalidDatabase, start, end)
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(record[2]))
+	if !domain.IsCountryCode(country) {
+		return ipRange{}, fmt.Errorf("%w: bad country code %q", ErrInvalidDatabase, record[2])
+	}
+
+	return ipRange{start: start, end: end, country: country}, nil
+}
+
+// Country implements links.GeoIP.
+func (f *File) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	addr = addr.Unmap().WithZone("")
+
+	// The first range ending at or after addr is the only one that can
+	// hold it.
+	i, _ := slices.BinarySearchFunc(f.ranges, addr, func(r ipRange, addr netip.Addr) int {
+		return r.end.Compare(addr)
+	})
+	if i == len(f.ranges) || f.ranges[i].start.Compare(addr) > 0 {
+		return ""
+	}
+
+	return f.ranges[i].country
+}
+
+// Len returns the number of ranges loaded.
+func (f *File) Len() int {
+	return len(f.ranges)
+}
@@ -0,0 +1,61 @@
+package geoip_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/adapters/geoip"
+)
+
+const database = `1.0.0.0,1.0.0.255,AU
+2.16.0.0,2.16.255.255,de
+10.0.0.0,10.255.255.255,ZZ
+2001:db8::,2001:db8:ffff:ffff:ffff:ffff:ffff:ffff,FR,extra
+`
+
+func TestFile_Country(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dbip-country-lite.csv")
+	require.NoError(t, os.WriteFile(path, []byte(database), 0o600))
+
+	f, err := geoip.Open(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, f.Len())
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "1.0.0.0", want: "AU"},
+		{ip: "1.0.0.255", want: "AU"},
+		{ip: "1.0.1.0", want: ""},
+		{ip: "2.16.3.4", want: "DE"},
+		{ip: "::ffff:2.16.3.4", want: "DE"},
+		{ip: "10.1.2.3", want: ""},
+		{ip: "2001:db8::1", want: "FR"},
+		{ip: "2001:db9::1", want: ""},
+		{ip: "0.0.0.1", want: ""},
+		{ip: "not an ip", want: ""},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, f.Country(tt.ip), tt.ip)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"1.0.0.0,1.0.0.255\n",
+		"1.0.0.0,bad,AU\n",
+		"1.0.0.255,1.0.0.0,AU\n",
+		"1.0.0.0,2001:db8::,AU\n",
+		"1.0.0.0,1.0.0.255,Australia\n",
+		"1.0.0.0,1.0.0.255,AU\n1.0.0.128,1.0.1.0,NZ\n",
+	} {
+		_, err := geoip.Parse(strings.NewReader(content))
+		require.ErrorIs(t, err, geoip.ErrInvalidDatabase, content)
+	}
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"code/internal/domain"
+)
+
+type LinkSnapshot struct {
+	ID            int64          `json:"id" example:"1"`
+	OriginalURL   string         `json:"original_url" example:"https://example.com"`
+	ShortName     string         `json:"short_name" example:"abc123"`
+	CreatedAt     time.Time      `json:"created_at" example:"2025-10-31T13:01:43Z"`
+	Tags          []string       `json:"tags,omitempty"`
+	Disabled      bool           `json:"disabled,omitempty"`
+	FlagReason    string         `json:"flag_reason,omitempty" example:"phish.example"`
+	Domain        string         `json:"domain,omitempty" example:"go.example.com"`
+	Title         string         `json:"title,omitempty" example:"Product launch"`
+	ForwardPath   bool           `json:"forward_path,omitempty"`
+	ForwardQuery  string         `json:"forward_query,omitempty" example:"preserve"`
+	UTM           *UTM           `json:"utm,omitempty"`
+	Rules         []RedirectRule `json:"rules,omitempty"`
+	Variants      []Variant      `json:"variants,omitempty"`
+	StickyVariant bool           `json:"sticky_variant,omitempty"`
+	ActiveFrom    *time.Time     `json:"active_from,omitempty" example:"2026-11-01T09:00:00Z"`
+}
+
+type AuditEventResponse struct {
+	ID        int64         `json:"id" example:"1"`
+	CreatedAt time.Time     `json:"created_at" example:"2025-10-31T13:01:43Z"`
+	Action    string        `json:"action" example:"update"`
+	LinkID    int64         `json:"link_id" example:"1"`
+	Actor     string        `json:"actor" example:"anonymous"`
+	RequestID string        `json:"request_id" example:"3f2c9a0e1b7d4c8a9e6f5d4c3b2a1908"`
+	IP        string        `json:"ip" example:"172.18.0.1"`
+	Before    *LinkSnapshot `json:"before"`
+	After     *LinkSnapshot `json:"after"`
+}
+
+func FromAuditEvent(event domain.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		ID:        event.ID,
+		CreatedAt: event.CreatedAt,
+		Action:    event.Action,
+		LinkID:    event.LinkID,
+		Actor:     event.Actor,
+		RequestID: event.RequestID,
+		IP:        event.IP,
+		Before:    fromSnapshot(event.Before),
+		After:     fromSnapshot(event.After),
+	}
+}
+
+func fromSnapshot(link *domain.Link) *LinkSnapshot {
+	if link == nil {
+		return nil
+	}
+
+	return &LinkSnapshot{
+		ID:            link.ID,
+		OriginalURL:   link.OriginalURL,
+		ShortName:     link.ShortName,
+		CreatedAt:     link.CreatedAt,
+		Tags:          link.Tags,
+		Disabled:      link.Disabled,
+		FlagReason:    link.FlagReason,
+		Domain:        link.Domain,
+		Title:         link.Title,
+		ForwardPath:   link.ForwardPath,
+		ForwardQuery:  link.ForwardQuery,
+		UTM:           FromUTM(link.UTM),
+		Rules:         FromRules(link.Rules),
+		Variants:      FromVariants(link.Variants),
+		StickyVariant: link.StickyVariant,
+		ActiveFrom:    link.ActiveFrom,
+	}
+}
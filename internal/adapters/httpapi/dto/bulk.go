@@ -0,0 +1,20 @@
+package dto
+
+type BulkItemResponse struct {
+	Index  int               `json:"index" example:"0"`
+	Status string            `json:"status" example:"created"`
+	Link   *LinkResponse     `json:"link,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type BulkCreateResponse struct {
+	Mode    string             `json:"mode" example:"atomic"`
+	Created int                `json:"created" example:"1"`
+	Failed  int                `json:"failed" example:"0"`
+	Items   []BulkItemResponse `json:"items"`
+}
+
+// BulkIDsResponse lists the links a bulk delete or patch affected.
+type BulkIDsResponse struct {
+	IDs []int64 `json:"ids" example:"1"`
+}
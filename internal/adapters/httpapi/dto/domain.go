@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"code/internal/domain"
+)
+
+type DomainResponse struct {
+	ID        int64     `json:"id" example:"1"`
+	Host      string    `json:"host" example:"go.example.com"`
+	CreatedAt time.Time `json:"created_at" example:"2025-10-31T13:01:43Z"`
+}
+
+func FromDomainModel(d domain.Domain) DomainResponse {
+	return DomainResponse{
+		ID:        d.ID,
+		Host:      d.Host,
+		CreatedAt: d.CreatedAt,
+	}
+}
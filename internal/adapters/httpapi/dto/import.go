@@ -0,0 +1,19 @@
+package dto
+
+type ImportRowErrorResponse struct {
+	Line        int               `json:"line" example:"3"`
+	OriginalURL string            `json:"original_url" example:"not-a-url"`
+	ShortName   string            `json:"short_name" example:"abc123"`
+	Errors      map[string]string `json:"errors"`
+}
+
+type ImportResponse struct {
+	DryRun          bool                     `json:"dry_run" example:"false"`
+	Rows            int                      `json:"rows" example:"3"`
+	Created         int                      `json:"created" example:"1"`
+	Updated         int                      `json:"updated" example:"1"`
+	Skipped         int                      `json:"skipped" example:"0"`
+	Failed          int                      `json:"failed" example:"1"`
+	Errors          []ImportRowErrorResponse `json:"errors"`
+	ErrorsTruncated bool                     `json:"errors_truncated" example:"false"`
+}
@@ -1,19 +1,186 @@
 package dto
 
-import "code/internal/domain"
+import (
+	"strings"
+	"time"
+
+	"code/internal/domain"
+)
 
 type LinkResponse struct {
-	ID          int64  `json:"id" example:"1"`
-	OriginalURL string `json:"original_url" example:"https://example.com"`
-	ShortName   string `json:"short_name" example:"abc123"`
-	ShortURL    string `json:"short_url" example:"https://example.com/r/abc123"`
+	ID          int64    `json:"id" example:"1"`
+	OriginalURL string   `json:"original_url" example:"https://example.com"`
+	ShortName   string   `json:"short_name" example:"abc123"`
+	ShortURL    string   `json:"short_url" example:"https://example.com/r/abc123"`
+	Tags        []string `json:"tags"`
+	Disabled    bool     `json:"disabled" example:"false"`
+	// FlaggedAt and FlagReason are set on links disabled by the blocklist.
+	FlaggedAt  *time.Time `json:"flagged_at,omitempty" example:"2025-10-31T13:01:43Z"`
+	FlagReason string     `json:"flag_reason,omitempty" example:"phish.example"`
+	// Domain is empty for links served on the BASE_URL host.
+	Domain      string `json:"domain,omitempty" example:"go.example.com"`
+	Title       string `json:"title" example:"Product launch"`
+	ForwardPath bool   `json:"forward_path" example:"false"`
+	// ForwardQuery is empty when the query string is not forwarded.
+	ForwardQuery string `json:"forward_query" example:"preserve"`
+	// UTM is omitted when the link has no UTM parameters.
+	UTM *UTM `json:"utm,omitempty"`
+	// Rules are listed in match order.
+	Rules []RedirectRule `json:"rules"`
+	// Variants is empty when the link has no A/B split.
+	Variants      []Variant `json:"variants"`
+	StickyVariant bool      `json:"sticky_variant" example:"false"`
+	// ActiveFrom is omitted for links live since their creation.
+	ActiveFrom *time.Time `json:"active_from,omitempty" example:"2026-11-01T09:00:00Z"`
+}
+
+// UTM holds the UTM parameters of a link, without the utm_ prefix.
+type UTM struct {
+	Source   string `json:"source,omitempty" example:"newsletter"`
+	Medium   string `json:"medium,omitempty" example:"email"`
+	Campaign string `json:"campaign,omitempty" example:"spring-sale"`
+	Term     string `json:"term,omitempty" example:"running shoes"`
+	Content  string `json:"content,omitempty" example:"header-cta"`
+}
+
+// FromUTM returns nil for the zero UTM.
+func FromUTM(u domain.UTM) *UTM {
+	if u.IsZero() {
+		return nil
+	}
+
+	return &UTM{Source: u.Source, Medium: u.Medium, Campaign: u.Campaign, Term: u.Term, Content: u.Content}
+}
+
+// Domain returns u as domain.UTM; nil is the zero UTM.
+func (u *UTM) Domain() domain.UTM {
+	if u == nil {
+		return domain.UTM{}
+	}
+
+	return domain.UTM{Source: u.Source, Medium: u.Medium, Campaign: u.Campaign, Term: u.Term, Content: u.Content}
+}
+
+// RedirectRule sends the visitors matching its conditions to URL.
+type RedirectRule struct {
+	OS      string `json:"os,omitempty" example:"ios"`
+	Device  string `json:"device,omitempty" example:"mobile"`
+	Country string `json:"country,omitempty" example:"DE"`
+	// After and Before bound when the rule applies; the window includes
+	// After and excludes Before.
+	After  time.Time `json:"after,omitzero" example:"2026-11-01T00:00:00Z"`
+	Before time.Time `json:"before,omitzero" example:"2026-12-01T00:00:00Z"`
+	URL    string    `json:"url" example:"https://apps.apple.com/app/id123"`
+}
+
+// FromRules never returns nil.
+func FromRules(rules []domain.RedirectRule) []RedirectRule {
+	out := make([]RedirectRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, RedirectRule(rule))
+	}
+
+	return out
+}
+
+// DomainRules returns nil for nil rules.
+func DomainRules(rules []RedirectRule) []domain.RedirectRule {
+	if rules == nil {
+		return nil
+	}
+
+	out := make([]domain.RedirectRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, domain.RedirectRule(rule))
+	}
+
+	return out
+}
+
+// Variant is one weighted destination of an A/B split.
+type Variant struct {
+	URL    string `json:"url" example:"https://example.com/landing-a"`
+	Weight int    `json:"weight" example:"70"`
 }
 
-func FromDomain(link domain.Link, baseURL string) LinkResponse {
+// FromVariants never returns nil.
+func FromVariants(variants []domain.Variant) []Variant {
+	out := make([]Variant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, Variant(v))
+	}
+
+	return out
+}
+
+// DomainVariants returns nil for nil variants.
+func DomainVariants(variants []Variant) []domain.Variant {
+	if variants == nil {
+		return nil
+	}
+
+	out := make([]domain.Variant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, domain.Variant(v))
+	}
+
+	return out
+}
+
+// ShortURLs describes where short links are served.
+type ShortURLs struct {
+	BaseURL string
+	// Root serves links at /<code> instead of /r/<code>.
+	Root bool
+}
+
+// URL returns the short URL of link: BASE_URL, or its scheme and the link
+// domain when the link has one, followed by the redirect path.
+func (u ShortURLs) URL(link domain.Link) string {
+	path := "/r/"
+	if u.Root {
+		path = "/"
+	}
+
+	return linkBaseURL(link, u.BaseURL) + path + link.ShortName
+}
+
+func FromDomain(link domain.Link, urls ShortURLs) LinkResponse {
 	return LinkResponse{
-		ID:          link.ID,
-		OriginalURL: link.OriginalURL,
-		ShortName:   link.ShortName,
-		ShortURL:    baseURL + "/r/" + link.ShortName,
+		ID:            link.ID,
+		OriginalURL:   link.OriginalURL,
+		ShortName:     link.ShortName,
+		ShortURL:      urls.URL(link),
+		Tags:          tagsOrEmpty(link.Tags),
+		Disabled:      link.Disabled,
+		FlaggedAt:     link.FlaggedAt,
+		FlagReason:    link.FlagReason,
+		Domain:        link.Domain,
+		Title:         link.Title,
+		ForwardPath:   link.ForwardPath,
+		ForwardQuery:  link.ForwardQuery,
+		UTM:           FromUTM(link.UTM),
+		Rules:         FromRules(link.Rules),
+		Variants:      FromVariants(link.Variants),
+		StickyVariant: link.StickyVariant,
+		ActiveFrom:    link.ActiveFrom,
+	}
+}
+
+func linkBaseURL(link domain.Link, baseURL string) string {
+	if link.Domain == "" {
+		return baseURL
 	}
+
+	scheme, _, _ := strings.Cut(baseURL, "://")
+
+	return scheme + "://" + link.Domain
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
 }
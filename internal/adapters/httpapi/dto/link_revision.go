@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"code/internal/domain"
+)
+
+type LinkRevisionResponse struct {
+	ID          int64     `json:"id" example:"3"`
+	LinkID      int64     `json:"link_id" example:"1"`
+	OriginalURL string    `json:"original_url" example:"https://example.com/old"`
+	ShortName   string    `json:"short_name" example:"abc123"`
+	CreatedAt   time.Time `json:"created_at" example:"2025-10-31T13:01:43Z"`
+}
+
+func FromRevision(rev domain.LinkRevision) LinkRevisionResponse {
+	return LinkRevisionResponse{
+		ID:          rev.ID,
+		LinkID:      rev.LinkID,
+		OriginalURL: rev.OriginalURL,
+		ShortName:   rev.ShortName,
+		CreatedAt:   rev.CreatedAt,
+	}
+}
@@ -14,6 +14,22 @@ type LinkVisitResponse struct {
 	UserAgent string    `json:"user_agent" example:"curl/8.5.0"`
 	Referer   string    `json:"reffer" example:"https://example.com"`
 	Status    int       `json:"status" example:"302"`
+	Path      string    `json:"path" example:"/r/abc123/getting-started?utm_source=x"`
+	// Rule is the index of the redirect rule the visit matched, null when
+	// it went to the link destination.
+	Rule *int `json:"rule" example:"0"`
+	// Variant is the index of the A/B variant drawn for the visit, null when
+	// there was none.
+	Variant *int `json:"variant" example:"1"`
+}
+
+type VisitStatResponse struct {
+	Key    string `json:"key" example:"spring-sale"`
+	Visits int64  `json:"visits" example:"42"`
+}
+
+func FromVisitStat(stat domain.VisitStat) VisitStatResponse {
+	return VisitStatResponse{Key: stat.Key, Visits: stat.Visits}
 }
 
 func FromVisit(visit domain.LinkVisit) LinkVisitResponse {
@@ -25,5 +41,8 @@ func FromVisit(visit domain.LinkVisit) LinkVisitResponse {
 		UserAgent: visit.UserAgent,
 		Referer:   visit.Referer,
 		Status:    visit.Status,
+		Path:      visit.Path,
+		Rule:      visit.Rule,
+		Variant:   visit.Variant,
 	}
 }
@@ -0,0 +1,35 @@
+package dto
+
+import "code/internal/app/links"
+
+const usagePeriodLayout = "2006-01"
+
+type UsageCounterResponse struct {
+	Used  int64  `json:"used" example:"42"`
+	Limit *int64 `json:"limit" example:"100"`
+}
+
+type UsageResponse struct {
+	Period        string               `json:"period" example:"2025-10"`
+	Links         UsageCounterResponse `json:"links"`
+	MonthlyClicks UsageCounterResponse `json:"monthly_clicks"`
+}
+
+func FromUsage(u links.Usage) UsageResponse {
+	return UsageResponse{
+		Period:        u.Period.Format(usagePeriodLayout),
+		Links:         fromUsageCounter(u.Links),
+		MonthlyClicks: fromUsageCounter(u.MonthlyClicks),
+	}
+}
+
+// fromUsageCounter reports an unlimited counter as a null limit.
+func fromUsageCounter(c links.UsageCounter) UsageCounterResponse {
+	resp := UsageCounterResponse{Used: c.Used}
+	if c.Limit > 0 {
+		limit := c.Limit
+		resp.Limit = &limit
+	}
+
+	return resp
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/app/links"
+)
+
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	rng, _, hasRange, err := parseRangeFromRequest(c)
+	if err != nil {
+		writeInvalidRange(c)
+
+		return
+	}
+
+	rawSort, ok := parseReactAdminSort(c.Query("sort"))
+	if !ok {
+		h.fail(c, links.ErrInvalidSort)
+
+		return
+	}
+
+	sort, err := links.NormalizeAndValidateSort(rawSort, links.DefaultAuditSort, links.AllowedAuditSortFields())
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	filter, err := parseAuditFilter(c.Query("filter"))
+	if err != nil {
+		writeInvalidFilter(c)
+
+		return
+	}
+
+	query := links.AuditQuery{Sort: sort, Filter: filter}
+	if hasRange {
+		query.Range = &rng
+	}
+
+	events, total, err := h.svc.ListAuditEvents(c.Request.Context(), query)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	items := make([]dto.AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		items = append(items, dto.FromAuditEvent(event))
+	}
+
+	if hasRange {
+		if len(items) == 0 {
+			c.Header("Content-Range", fmt.Sprintf("audit */%d", total))
+		} else {
+			end := rng.Start + len(items) - 1
+			c.Header("Content-Range", fmt.Sprintf("audit %d-%d/%d", rng.Start, end, total))
+		}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func parseAuditFilter(raw string) (links.AuditFilter, error) {
+	f, err := parseReactAdminFilter(raw)
+	if err != nil {
+		return links.AuditFilter{}, err
+	}
+
+	if err := f.only("action", "link_id", "actor", "request_id"); err != nil {
+		return links.AuditFilter{}, err
+	}
+
+	var out links.AuditFilter
+
+	if out.Action, err = f.string("action"); err != nil {
+		return links.AuditFilter{}, err
+	}
+
+	if out.LinkID, err = f.int64("link_id"); err != nil {
+		return links.AuditFilter{}, err
+	}
+
+	if out.Actor, err = f.string("actor"); err != nil {
+		return links.AuditFilter{}, err
+	}
+
+	if out.RequestID, err = f.string("request_id"); err != nil {
+		return links.AuditFilter{}, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/middleware"
+	"code/internal/app/links"
+)
+
+// actorHeader identifies the caller in audit events. The API has no
+// authentication yet, so the value is self-reported.
+const actorHeader = "X-Actor"
+
+// AuditMeta attaches actor, request ID and client IP to the request context.
+func AuditMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := links.AuditMeta{
+			Actor:     strings.TrimSpace(c.GetHeader(actorHeader)),
+			RequestID: c.GetString(middleware.RequestIDKey),
+			IP:        c.ClientIP(),
+		}
+
+		c.Request = c.Request.WithContext(links.ContextWithAuditMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
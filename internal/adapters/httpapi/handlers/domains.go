@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/domain"
+)
+
+type CreateDomainRequest struct {
+	Host string `json:"host" binding:"required" example:"go.example.com"`
+}
+
+func (h *Handler) ListDomains(c *gin.Context) {
+	items, err := h.svc.ListDomains(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	resp := make([]dto.DomainResponse, 0, len(items))
+	for _, it := range items {
+		resp = append(resp, dto.FromDomainModel(it))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) GetDomain(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	d, err := h.svc.GetDomain(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromDomainModel(d))
+}
+
+func (h *Handler) CreateDomain(c *gin.Context) {
+	var req CreateDomainRequest
+
+	if err := BindJSONStrict(c, &req); err != nil {
+		badJSON(c, err)
+
+		return
+	}
+
+	req.Host = strings.TrimSpace(req.Host)
+
+	if errs, ok := validateStruct(req); ok {
+		writeValidationErrors(c, errs)
+
+		return
+	}
+
+	d, err := h.svc.CreateDomain(c.Request.Context(), req.Host)
+	if errors.Is(err, domain.ErrInvalidDomain) {
+		writeValidationErrors(c, map[string]string{"host": "invalid host"})
+
+		return
+	}
+
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/domains/%d", d.ID))
+	c.JSON(http.StatusCreated, dto.FromDomainModel(d))
+}
+
+func (h *Handler) DeleteDomain(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteDomain(c.Request.Context(), id); err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/problems"
+	"code/internal/domain"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+
+	etagAny    = "*"
+	etagWeakPf = "W/"
+)
+
+func linkETag(link domain.Link) string {
+	return strconv.Quote(strconv.FormatInt(link.Version, 10))
+}
+
+func setLinkETag(c *gin.Context, link domain.Link) {
+	c.Header(headerETag, linkETag(link))
+}
+
+// parseIfMatch returns the link version required by If-Match; zero means
+// the request is unconditional. Only a single entity tag is supported.
+func parseIfMatch(c *gin.Context) (int64, bool) {
+	raw := strings.TrimSpace(c.GetHeader(headerIfMatch))
+	if raw == "" || raw == etagAny {
+		return 0, true
+	}
+
+	if strings.Contains(raw, ",") {
+		problems.WriteProblem(c, problems.Problem{
+			Type:   problems.ProblemTypeValidation,
+			Title:  problems.TitleBadRequest,
+			Status: http.StatusBadRequest,
+			Detail: problems.DetailInvalidIfMatch,
+		})
+
+		return 0, false
+	}
+
+	version, ok := parseVersionETag(raw)
+	if !ok {
+		// A tag we never issued cannot match the current representation.
+		problems.WriteProblem(c, problemFromError(domain.ErrVersionMismatch))
+
+		return 0, false
+	}
+
+	return version, true
+}
+
+// notModified reports whether If-None-Match matches the current link using
+// weak comparison, as required for GET.
+func notModified(c *gin.Context, link domain.Link) bool {
+	raw := strings.TrimSpace(c.GetHeader(headerIfNoneMatch))
+	if raw == "" {
+		return false
+	}
+
+	if raw == etagAny {
+		return true
+	}
+
+	for tag := range strings.SplitSeq(raw, ",") {
+		version, ok := parseVersionETag(strings.TrimSpace(tag))
+		if ok && version == link.Version {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseVersionETag(tag string) (int64, bool) {
+	tag = strings.TrimPrefix(tag, etagWeakPf)
+
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
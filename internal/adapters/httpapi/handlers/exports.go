@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/adapters/httpapi/problems"
+	"code/internal/adapters/linkio"
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+var linkColumns = linkio.Columns[dto.LinkResponse]{
+	Header: []string{
+		"id", "original_url", "short_name", "short_url", "tags", "disabled", "domain", "title", "forward_path",
+		"forward_query", "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content", "rules",
+		"variants", "sticky_variant", "active_from",
+	},
+	Row: func(l dto.LinkResponse) []string {
+		var utm dto.UTM
+		if l.UTM != nil {
+			utm = *l.UTM
+		}
+
+		return []string{
+			strconv.FormatInt(l.ID, 10),
+			l.OriginalURL,
+			l.ShortName,
+			l.ShortURL,
+			strings.Join(l.Tags, "|"),
+			strconv.FormatBool(l.Disabled),
+			l.Domain,
+			l.Title,
+			strconv.FormatBool(l.ForwardPath),
+			l.ForwardQuery,
+			utm.Source,
+			utm.Medium,
+			utm.Campaign,
+			utm.Term,
+			utm.Content,
+			jsonField(l.Rules),
+			jsonField(l.Variants),
+			strconv.FormatBool(l.StickyVariant),
+			timeField(l.ActiveFrom),
+		}
+	},
+}
+
+var visitColumns = linkio.Columns[dto.LinkVisitResponse]{
+	Header: []string{"id", "link_id", "created_at", "ip", "user_agent", "reffer", "status", "path", "rule", "variant"},
+	Row: func(v dto.LinkVisitResponse) []string {
+		return []string{
+			strconv.FormatInt(v.ID, 10),
+			strconv.FormatInt(v.LinkID, 10),
+			v.CreatedAt.UTC().Format(time.RFC3339),
+			v.IP,
+			v.UserAgent,
+			v.Referer,
+			strconv.Itoa(v.Status),
+			v.Path,
+			indexField(v.Rule),
+			indexField(v.Variant),
+		}
+	},
+}
+
+// jsonField writes a list as a JSON array, or an empty field for none.
+func jsonField[T any](items []T) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return ""
+	}
+
+	return string(raw)
+}
+
+func indexField(index *int) string {
+	if index == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*index)
+}
+
+func timeField(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ExportLinks accepts the filter param of ListLinks too.
+func (h *Handler) ExportLinks(c *gin.Context) {
+	sort, ok := h.exportSort(c, links.DefaultLinksSort, links.AllowedLinksSortFields())
+	if !ok {
+		return
+	}
+
+	filter, err := parseLinksFilter(c.Query("filter"))
+	if err != nil {
+		writeInvalidFilter(c)
+
+		return
+	}
+
+	exportRows(c, h, "links", linkColumns, func(write func(dto.LinkResponse) error) error {
+		return h.svc.ExportLinks(c.Request.Context(), filter, sort, func(link domain.Link) error {
+			return write(dto.FromDomain(link, h.urls))
+		})
+	})
+}
+
+func (h *Handler) ExportLinkVisits(c *gin.Context) {
+	sort, ok := h.exportSort(c, links.DefaultLinkVisitsSort, links.AllowedLinkVisitsSortFields())
+	if !ok {
+		return
+	}
+
+	exportRows(c, h, "link_visits", visitColumns, func(write func(dto.LinkVisitResponse) error) error {
+		return h.svc.ExportLinkVisits(c.Request.Context(), sort, func(visit domain.LinkVisit) error {
+			return write(dto.FromVisit(visit))
+		})
+	})
+}
+
+// exportSort accepts the same sort param as the matching list endpoint.
+func (h *Handler) exportSort(c *gin.Context, def links.Sort, allowed links.AllowedSortFields) (links.Sort, bool) {
+	rawSort, ok := parseReactAdminSort(c.Query("sort"))
+	if !ok {
+		h.fail(c, links.ErrInvalidSort)
+
+		return links.Sort{}, false
+	}
+
+	sort, err := links.NormalizeAndValidateSort(rawSort, def, allowed)
+	if err != nil {
+		h.fail(c, err)
+
+		return links.Sort{}, false
+	}
+
+	return sort, true
+}
+
+// exportRows negotiates the format from Accept and streams rows produced by
+// run straight to the client. Errors before the first flushed byte become a
+// problem response; later ones can only cut the download short.
+func exportRows[T any](
+	c *gin.Context,
+	h *Handler,
+	name string,
+	cols linkio.Columns[T],
+	run func(write func(T) error) error,
+) {
+	format, err := linkio.FormatFromContentType(c.NegotiateFormat(linkio.ContentTypeCSV, linkio.ContentTypeNDJSON))
+	if err != nil {
+		problems.WriteProblem(c, problems.Problem{
+			Type:   problems.ProblemTypeNotAcceptable,
+			Title:  problems.TitleNotAcceptable,
+			Status: http.StatusNotAcceptable,
+			Detail: problems.DetailExportFormat,
+		})
+
+		return
+	}
+
+	w, err := linkio.NewWriter(c.Writer, format, cols)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	extendDeadlines(c)
+	setAttachment(c, name+format.Ext())
+	c.Header("Content-Type", format.ContentType())
+	c.Status(http.StatusOK)
+
+	err = run(w.Write)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		h.fail(c, err)
+
+		return
+	}
+
+	_ = c.Error(err)
+	c.Abort()
+	dropConnection(c)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var errInvalidFilter = errors.New("invalid filter")
+
+// reactAdminFilter is the decoded `filter` query param: a JSON object of field -> value.
+type reactAdminFilter map[string]json.RawMessage
+
+// parseReactAdminFilter parses the query param `filter` expected as JSON object: {"field":value}.
+func parseReactAdminFilter(raw string) (reactAdminFilter, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return reactAdminFilter{}, nil
+	}
+
+	var out reactAdminFilter
+	if err := json.Unmarshal([]byte(raw), &out); err != nil || out == nil {
+		return nil, errInvalidFilter
+	}
+
+	return out, nil
+}
+
+// only rejects keys outside allowed so that typos do not silently match everything.
+func (f reactAdminFilter) only(allowed ...string) error {
+	for key := range f {
+		found := false
+		for _, a := range allowed {
+			if key == a {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return errInvalidFilter
+		}
+	}
+
+	return nil
+}
+
+func (f reactAdminFilter) string(key string) (string, error) {
+	raw, ok := f[key]
+	if !ok || isJSONNull(raw) {
+		return "", nil
+	}
+
+	var v string
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", errInvalidFilter
+	}
+
+	return strings.TrimSpace(v), nil
+}
+
+func (f reactAdminFilter) int64(key string) (int64, error) {
+	raw, ok := f[key]
+	if !ok || isJSONNull(raw) {
+		return 0, nil
+	}
+
+	var v int64
+	if err := json.Unmarshal(raw, &v); err != nil || v <= 0 {
+		return 0, errInvalidFilter
+	}
+
+	return v, nil
+}
+
+// int64s accepts a single positive ID or an array of them; an empty array
+// yields an empty, non-nil slice so it matches nothing.
+func (f reactAdminFilter) int64s(key string) ([]int64, error) {
+	raw, ok := f[key]
+	if !ok || isJSONNull(raw) {
+		return nil, nil
+	}
+
+	var one int64
+	if err := json.Unmarshal(raw, &one); err == nil {
+		if one <= 0 {
+			return nil, errInvalidFilter
+		}
+
+		return []int64{one}, nil
+	}
+
+	vs := []int64{}
+	if err := json.Unmarshal(raw, &vs); err != nil {
+		return nil, errInvalidFilter
+	}
+
+	for _, v := range vs {
+		if v <= 0 {
+			return nil, errInvalidFilter
+		}
+	}
+
+	return vs, nil
+}
+
+func (f reactAdminFilter) bool(key string) (*bool, error) {
+	raw, ok := f[key]
+	if !ok || isJSONNull(raw) {
+		return nil, nil
+	}
+
+	var v bool
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, errInvalidFilter
+	}
+
+	return &v, nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -6,18 +6,19 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"code/internal/adapters/httpapi/dto"
 	"code/internal/adapters/httpapi/problems"
 	"code/internal/app/links"
 	"code/internal/domain"
 )
 
 type Handler struct {
-	svc     links.UseCase
-	baseURL string
+	svc  links.UseCase
+	urls dto.ShortURLs
 }
 
-func New(svc links.UseCase, baseURL string) *Handler {
-	return &Handler{svc: svc, baseURL: baseURL}
+func New(svc links.UseCase, urls dto.ShortURLs) *Handler {
+	return &Handler{svc: svc, urls: urls}
 }
 
 func (h *Handler) fail(c *gin.Context, err error) {
@@ -47,12 +48,46 @@ func (h *Handler) NotFound(c *gin.Context) {
 
 func validationErrorsFromDomain(err error) (map[string]string, bool) {
 	switch {
+	// Rule and variant URLs rejected by the URL checks are also
+	// ErrInvalidURL.
+	case errors.Is(err, domain.ErrInvalidRedirectRules):
+		return map[string]string{"rules": "invalid redirect rules"}, true
+	case errors.Is(err, domain.ErrInvalidVariants):
+		return map[string]string{"variants": "invalid variants"}, true
+	case errors.Is(err, domain.ErrURLPrivateHost):
+		return map[string]string{"original_url": "url points to a private or local address"}, true
+	case errors.Is(err, domain.ErrURLSelfReference):
+		return map[string]string{"original_url": "url points to this link shortener"}, true
+	case errors.Is(err, domain.ErrURLDomainDenied):
+		return map[string]string{"original_url": "url domain is denied"}, true
+	case errors.Is(err, domain.ErrURLDomainNotAllowed):
+		return map[string]string{"original_url": "url domain is not allowed"}, true
+	case errors.Is(err, domain.ErrURLBlocked):
+		return map[string]string{"original_url": "url is blocklisted"}, true
 	case errors.Is(err, domain.ErrInvalidURL):
 		return map[string]string{"original_url": "invalid url"}, true
+	case errors.Is(err, domain.ErrShortNameReserved):
+		return map[string]string{"short_name": "short name is reserved"}, true
+	case errors.Is(err, domain.ErrShortNameDenied):
+		return map[string]string{"short_name": "short name contains a denied word"}, true
 	case errors.Is(err, domain.ErrInvalidShortName):
 		return map[string]string{"short_name": "invalid short_name"}, true
 	case errors.Is(err, domain.ErrShortNameConflict):
 		return map[string]string{"short_name": "short name already in use"}, true
+	case errors.Is(err, domain.ErrInvalidTags):
+		return map[string]string{"tags": "invalid tags"}, true
+	case errors.Is(err, domain.ErrInvalidTitle):
+		return map[string]string{"title": "invalid title"}, true
+	case errors.Is(err, domain.ErrInvalidForwardQuery):
+		return map[string]string{"forward_query": "invalid forward query policy"}, true
+	case errors.Is(err, domain.ErrInvalidUTM):
+		return map[string]string{"utm": "invalid utm parameters"}, true
+	case errors.Is(err, domain.ErrInvalidDomain):
+		return map[string]string{"domain": "invalid domain"}, true
+	case errors.Is(err, domain.ErrUnknownDomain):
+		return map[string]string{"domain": "unknown domain"}, true
+	case errors.Is(err, links.ErrUnknownShortNameStrategy):
+		return map[string]string{"short_name_strategy": "unknown short name strategy"}, true
 	default:
 		return nil, false
 	}
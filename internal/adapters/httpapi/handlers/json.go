@@ -7,15 +7,19 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/middleware"
+	"code/internal/adapters/httpapi/problems"
 )
 
-// BindJSONStrict decodes JSON and rejects unknown fields and extra objects.
+// BindJSONStrict decodes JSON and rejects unknown fields, extra objects and
+// bodies over middleware.MaxJSONBodyBytes.
 func BindJSONStrict(c *gin.Context, dst any) error {
 	if c.Request.Body == nil {
 		return io.EOF
 	}
 
-	dec := json.NewDecoder(c.Request.Body)
+	dec := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, middleware.MaxJSONBodyBytes))
 	dec.DisallowUnknownFields()
 
 	if err := dec.Decode(dst); err != nil {
@@ -32,7 +36,20 @@ func BindJSONStrict(c *gin.Context, dst any) error {
 	return nil
 }
 
-func badJSON(c *gin.Context) {
+// badJSON answers a BindJSONStrict error; an oversized body gets a 413.
+func badJSON(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problems.WriteProblem(c, problems.Problem{
+			Type:   problems.ProblemTypeTooLarge,
+			Title:  problems.TitleTooLarge,
+			Status: http.StatusRequestEntityTooLarge,
+			Detail: problems.DetailBodyTooLarge,
+		})
+
+		return
+	}
+
 	c.JSON(http.StatusBadRequest, gin.H{
 		"error": "invalid request",
 	})
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/adapters/httpapi/problems"
+	"code/internal/app/links"
+)
+
+const (
+	contentTypeMergePatch = "application/merge-patch+json"
+	contentTypeJSON       = "application/json"
+)
+
+// Nullable tells an absent JSON field apart from an explicit null, which
+// JSON Merge Patch (RFC 7396) gives different meanings.
+type Nullable[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	n.Set = true
+
+	if string(b) == "null" {
+		n.Null = true
+
+		return nil
+	}
+
+	return json.Unmarshal(b, &n.Value)
+}
+
+// PatchLinkRequest accepts the read-only fields react-admin echoes back (id,
+// short_url, flagged_at, flag_reason and domain), like UpdateLinkRequest,
+// and ignores them. A null or empty short_name is rejected; a new one is
+// generated only on an explicit regenerate_short_name.
+type PatchLinkRequest struct {
+	ID                  int64                        `json:"id"`
+	OriginalURL         Nullable[string]             `json:"original_url" example:"https://example.com/updated"`
+	ShortName           Nullable[string]             `json:"short_name" example:"abc123"`
+	RegenerateShortName bool                         `json:"regenerate_short_name"`
+	ShortURL            string                       `json:"short_url"`
+	Tags                Nullable[[]string]           `json:"tags"`
+	Disabled            Nullable[bool]               `json:"disabled"`
+	FlaggedAt           *time.Time                   `json:"flagged_at"`
+	FlagReason          string                       `json:"flag_reason"`
+	Domain              string                       `json:"domain"`
+	Title               Nullable[string]             `json:"title"`
+	ForwardPath         Nullable[bool]               `json:"forward_path"`
+	ForwardQuery        Nullable[string]             `json:"forward_query"`
+	UTM                 Nullable[PatchUTMRequest]    `json:"utm"`
+	Rules               Nullable[[]dto.RedirectRule] `json:"rules"`
+	Variants            Nullable[[]dto.Variant]      `json:"variants"`
+	StickyVariant       Nullable[bool]               `json:"sticky_variant"`
+	ActiveFrom          Nullable[time.Time]          `json:"active_from"`
+}
+
+// PatchUTMRequest is merged into the stored UTM parameters like the link
+// itself: absent members are kept and null ones cleared.
+type PatchUTMRequest struct {
+	Source   Nullable[string] `json:"source"`
+	Medium   Nullable[string] `json:"medium"`
+	Campaign Nullable[string] `json:"campaign"`
+	Term     Nullable[string] `json:"term"`
+	Content  Nullable[string] `json:"content"`
+}
+
+func (r PatchUTMRequest) toPatch() links.UTMPatch {
+	return links.UTMPatch{
+		Source:   nullableValue(r.Source),
+		Medium:   nullableValue(r.Medium),
+		Campaign: nullableValue(r.Campaign),
+		Term:     nullableValue(r.Term),
+		Content:  nullableValue(r.Content),
+	}
+}
+
+// nullableValue returns nil for an absent field and the zero value for null.
+func nullableValue[T any](n Nullable[T]) *T {
+	if !n.Set {
+		return nil
+	}
+
+	v := n.Value
+
+	return &v
+}
+
+// toPatch maps a null to the field's empty value, as if it were omitted from
+// a PUT, except for short_name, which the service then rejects; a utm object
+// is merged and arrays replace the whole list.
+func (r PatchLinkRequest) toPatch() links.LinkPatch {
+	patch := links.LinkPatch{RegenerateShortName: r.RegenerateShortName}
+
+	if r.OriginalURL.Set {
+		v := strings.TrimSpace(r.OriginalURL.Value)
+		patch.OriginalURL = &v
+	}
+
+	if r.ShortName.Set {
+		v := strings.TrimSpace(r.ShortName.Value)
+		patch.ShortName = &v
+	}
+
+	if r.Tags.Set {
+		v := r.Tags.Value
+		patch.Tags = &v
+	}
+
+	if r.Disabled.Set {
+		v := r.Disabled.Value
+		patch.Disabled = &v
+	}
+
+	if r.Title.Set {
+		v := r.Title.Value
+		patch.Title = &v
+	}
+
+	if r.ForwardPath.Set {
+		v := r.ForwardPath.Value
+		patch.ForwardPath = &v
+	}
+
+	if r.ForwardQuery.Set {
+		v := r.ForwardQuery.Value
+		patch.ForwardQuery = &v
+	}
+
+	if r.UTM.Null {
+		empty := ""
+		patch.UTM = &links.UTMPatch{Source: &empty, Medium: &empty, Campaign: &empty, Term: &empty, Content: &empty}
+	} else if r.UTM.Set {
+		v := r.UTM.Value.toPatch()
+		patch.UTM = &v
+	}
+
+	if r.Rules.Set {
+		v := dto.DomainRules(r.Rules.Value)
+		patch.Rules = &v
+	}
+
+	if r.Variants.Set {
+		v := dto.DomainVariants(r.Variants.Value)
+		patch.Variants = &v
+	}
+
+	if r.StickyVariant.Set {
+		v := r.StickyVariant.Value
+		patch.StickyVariant = &v
+	}
+
+	if r.ActiveFrom.Set {
+		v := r.ActiveFrom.Value
+		patch.ActiveFrom = &v
+	}
+
+	return patch
+}
+
+func (h *Handler) PatchLink(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if !requireMergePatch(c) {
+		return
+	}
+
+	ifVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req PatchLinkRequest
+
+	err := BindJSONStrict(c, &req)
+	if err != nil {
+		badJSON(c, err)
+
+		return
+	}
+
+	link, err := h.svc.Patch(c.Request.Context(), id, req.toPatch(), ifVersion)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	setLinkETag(c, link)
+	c.JSON(http.StatusOK, dto.FromDomain(link, h.urls))
+}
+
+// requireMergePatch accepts plain JSON too, since it parses identically.
+func requireMergePatch(c *gin.Context) bool {
+	switch c.ContentType() {
+	case contentTypeMergePatch, contentTypeJSON:
+		return true
+	default:
+		problems.WriteProblem(c, problems.Problem{
+			Type:   problems.ProblemTypeMediaType,
+			Title:  problems.TitleMediaType,
+			Status: http.StatusUnsupportedMediaType,
+			Detail: problems.DetailMergePatchOnly,
+		})
+
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/adapters/httpapi/problems"
+	"code/internal/app/links"
+)
+
+func (h *Handler) ListLinkRevisions(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	rng, _, hasRange, err := parseRangeFromRequest(c)
+	if err != nil {
+		writeInvalidRange(c)
+
+		return
+	}
+
+	rawSort, ok := parseReactAdminSort(c.Query("sort"))
+	if !ok {
+		h.fail(c, links.ErrInvalidSort)
+
+		return
+	}
+
+	sort, err := links.NormalizeAndValidateSort(rawSort, links.DefaultRevisionsSort, links.AllowedRevisionsSortFields())
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	query := links.LinkRevisionsQuery{Sort: sort}
+	if hasRange {
+		query.Range = &rng
+	}
+
+	revs, total, err := h.svc.ListLinkRevisions(c.Request.Context(), id, query)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	items := make([]dto.LinkRevisionResponse, 0, len(revs))
+	for _, rev := range revs {
+		items = append(items, dto.FromRevision(rev))
+	}
+
+	if hasRange {
+		if len(items) == 0 {
+			c.Header("Content-Range", fmt.Sprintf("revisions */%d", total))
+		} else {
+			end := rng.Start + len(items) - 1
+			c.Header("Content-Range", fmt.Sprintf("revisions %d-%d/%d", rng.Start, end, total))
+		}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *Handler) RevertLink(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	rev, err := strconv.ParseInt(c.Param("rev"), 10, 64)
+	if err != nil || rev <= 0 {
+		problems.WriteProblem(c, problems.Problem{
+			Type:   problems.ProblemTypeValidation,
+			Title:  problems.TitleValidation,
+			Status: http.StatusBadRequest,
+			Detail: problems.DetailInvalidRevision,
+		})
+
+		return
+	}
+
+	link, err := h.svc.RevertLink(c.Request.Context(), id, rev)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	setLinkETag(c, link)
+
+	c.JSON(http.StatusOK, dto.FromDomain(link, h.urls))
+}
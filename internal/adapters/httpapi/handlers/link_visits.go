@@ -57,6 +57,47 @@ func (h *Handler) ListLinkVisits(c *gin.Context) {
 	c.JSON(http.StatusOK, items)
 }
 
+// VisitStats counts visits per value of the group_by param, optionally for the
+// link given as link_id in filter.
+func (h *Handler) VisitStats(c *gin.Context) {
+	linkID, err := parseVisitStatsFilter(c.Query("filter"))
+	if err != nil {
+		writeInvalidFilter(c)
+
+		return
+	}
+
+	stats, err := h.svc.VisitStats(c.Request.Context(), links.VisitStatsQuery{
+		GroupBy: c.Query("group_by"),
+		LinkID:  linkID,
+	})
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	items := make([]dto.VisitStatResponse, 0, len(stats))
+	for _, stat := range stats {
+		items = append(items, dto.FromVisitStat(stat))
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func parseVisitStatsFilter(raw string) (int64, error) {
+	f, err := parseReactAdminFilter(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := f.only("link_id"); err != nil {
+		return 0, err
+	}
+
+	return f.int64("link_id")
+}
+
 func (h *Handler) fetchVisits(
 	c *gin.Context,
 	query links.LinkVisitsQuery,
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -15,15 +16,73 @@ import (
 )
 
 type CreateLinkRequest struct {
-	OriginalURL string `json:"original_url" binding:"required" example:"https://example.com"`
-	ShortName   string `json:"short_name" binding:"omitempty,min=3,max=32" example:"abc123"`
+	OriginalURL string   `json:"original_url" binding:"required" example:"https://example.com"`
+	ShortName   string   `json:"short_name" binding:"omitempty,min=3,max=32" example:"abc123"`
+	Tags        []string `json:"tags" binding:"omitempty,max=20" example:"promo"`
+	Disabled    bool     `json:"disabled" example:"false"`
+	// ShortNameStrategy overrides the configured generation strategy when
+	// ShortName is empty.
+	ShortNameStrategy string `json:"short_name_strategy" example:"words"`
+	// Domain is the registered host serving the link; empty uses BASE_URL.
+	Domain string `json:"domain" example:"go.example.com"`
+	Title  string `json:"title" example:"Product launch"`
+	// ForwardPath and ForwardQuery pass the request path after the short
+	// name and the query string on to the destination.
+	ForwardPath  bool   `json:"forward_path" example:"false"`
+	ForwardQuery string `json:"forward_query" example:"preserve"`
+	// UTM parameters are added to the destination on redirect.
+	UTM *dto.UTM `json:"utm"`
+	// Rules send matching visitors elsewhere, first match first.
+	Rules []dto.RedirectRule `json:"rules"`
+	// Variants split the other visitors between weighted destinations;
+	// StickyVariant keeps each visitor on the first one drawn.
+	Variants      []dto.Variant `json:"variants"`
+	StickyVariant bool          `json:"sticky_variant" example:"false"`
+	// ActiveFrom delays the first redirect; until then the link is a 404.
+	ActiveFrom *time.Time `json:"active_from" example:"2026-11-01T09:00:00Z"`
 }
 
+func (r CreateLinkRequest) input() links.LinkInput {
+	return links.LinkInput{
+		OriginalURL:       r.OriginalURL,
+		ShortName:         r.ShortName,
+		Tags:              r.Tags,
+		Disabled:          r.Disabled,
+		ShortNameStrategy: r.ShortNameStrategy,
+		Domain:            r.Domain,
+		Title:             r.Title,
+		ForwardPath:       r.ForwardPath,
+		ForwardQuery:      r.ForwardQuery,
+		UTM:               r.UTM.Domain(),
+		Rules:             dto.DomainRules(r.Rules),
+		Variants:          dto.DomainVariants(r.Variants),
+		StickyVariant:     r.StickyVariant,
+		ActiveFrom:        r.ActiveFrom,
+	}
+}
+
+// UpdateLinkRequest replaces the link, so omitted optional fields take their
+// empty value. The read-only fields of LinkResponse are accepted so that
+// responses can be sent back, and ignored: the domain of a link never
+// changes and the blocklist flag is only cleared by enabling the link.
 type UpdateLinkRequest struct {
-	ID          int64  `json:"id"`
-	OriginalURL string `json:"original_url" binding:"required" example:"https://example.com/updated"`
-	ShortName   string `json:"short_name" binding:"omitempty,min=3,max=32" example:"abc123"`
-	ShortURL    string `json:"short_url" example:"https://example.com/r/abc123"`
+	ID            int64              `json:"id"`
+	OriginalURL   string             `json:"original_url" binding:"required" example:"https://example.com/updated"`
+	ShortName     string             `json:"short_name" binding:"omitempty,min=3,max=32" example:"abc123"`
+	ShortURL      string             `json:"short_url" example:"https://example.com/r/abc123"`
+	Tags          []string           `json:"tags" binding:"omitempty,max=20" example:"promo"`
+	Disabled      bool               `json:"disabled" example:"false"`
+	FlaggedAt     *time.Time         `json:"flagged_at"`
+	FlagReason    string             `json:"flag_reason"`
+	Domain        string             `json:"domain" example:"go.example.com"`
+	Title         string             `json:"title" example:"Product launch"`
+	ForwardPath   bool               `json:"forward_path" example:"false"`
+	ForwardQuery  string             `json:"forward_query" example:"preserve"`
+	UTM           *dto.UTM           `json:"utm"`
+	Rules         []dto.RedirectRule `json:"rules"`
+	Variants      []dto.Variant      `json:"variants"`
+	StickyVariant bool               `json:"sticky_variant" example:"false"`
+	ActiveFrom    *time.Time         `json:"active_from" example:"2026-11-01T09:00:00Z"`
 }
 
 func (h *Handler) ListLinks(c *gin.Context) {
@@ -48,12 +107,19 @@ func (h *Handler) ListLinks(c *gin.Context) {
 		return
 	}
 
+	filter, err := parseLinksFilter(c.Query("filter"))
+	if err != nil {
+		writeInvalidFilter(c)
+
+		return
+	}
+
 	var (
 		items []domain.Link
 		total int64
 	)
 
-	query := links.LinksQuery{Sort: sort}
+	query := links.LinksQuery{Sort: sort, Filter: filter}
 	if hasRange {
 		query.Range = &rng
 	}
@@ -68,7 +134,7 @@ func (h *Handler) ListLinks(c *gin.Context) {
 
 	resp := make([]dto.LinkResponse, 0, len(items))
 	for _, it := range items {
-		resp = append(resp, dto.FromDomain(it, h.baseURL))
+		resp = append(resp, dto.FromDomain(it, h.urls))
 	}
 
 	if hasRange {
@@ -83,12 +149,37 @@ func (h *Handler) ListLinks(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// ListFlaggedLinks returns the links disabled by the blocklist rescan, most
+// recently flagged first.
+func (h *Handler) ListFlaggedLinks(c *gin.Context) {
+	items, err := h.svc.ListFlaggedLinks(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	resp := make([]dto.LinkResponse, 0, len(items))
+	for _, it := range items {
+		resp = append(resp, dto.FromDomain(it, h.urls))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *Handler) CreateLink(c *gin.Context) {
+	reuse, err := strconv.ParseBool(c.DefaultQuery("reuse_existing", "false"))
+	if err != nil {
+		problems.WriteProblem(c, badRequestProblem(problems.DetailInvalidReuse))
+
+		return
+	}
+
 	var req CreateLinkRequest
 
-	err := BindJSONStrict(c, &req)
+	err = BindJSONStrict(c, &req)
 	if err != nil {
-		badJSON(c)
+		badJSON(c, err)
 
 		return
 	}
@@ -102,15 +193,31 @@ func (h *Handler) CreateLink(c *gin.Context) {
 		return
 	}
 
-	link, err := h.svc.Create(c.Request.Context(), req.OriginalURL, req.ShortName)
+	var (
+		link    domain.Link
+		created = true
+	)
+
+	if reuse {
+		link, created, err = h.svc.CreateOrReuse(c.Request.Context(), req.input())
+	} else {
+		link, err = h.svc.Create(c.Request.Context(), req.input())
+	}
+
 	if err != nil {
 		h.fail(c, err)
 
 		return
 	}
 
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+
 	c.Header("Location", fmt.Sprintf("/api/links/%d", link.ID))
-	c.JSON(http.StatusCreated, dto.FromDomain(link, h.baseURL))
+	setLinkETag(c, link)
+	c.JSON(status, dto.FromDomain(link, h.urls))
 }
 
 func (h *Handler) GetLink(c *gin.Context) {
@@ -126,7 +233,15 @@ func (h *Handler) GetLink(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromDomain(link, h.baseURL))
+	setLinkETag(c, link)
+
+	if notModified(c, link) {
+		c.Status(http.StatusNotModified)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromDomain(link, h.urls))
 }
 
 func (h *Handler) UpdateLink(c *gin.Context) {
@@ -135,11 +250,16 @@ func (h *Handler) UpdateLink(c *gin.Context) {
 		return
 	}
 
+	ifVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
 	var req UpdateLinkRequest
 
 	err := BindJSONStrict(c, &req)
 	if err != nil {
-		badJSON(c)
+		badJSON(c, err)
 
 		return
 	}
@@ -153,14 +273,29 @@ func (h *Handler) UpdateLink(c *gin.Context) {
 		return
 	}
 
-	link, err := h.svc.Update(c.Request.Context(), id, req.OriginalURL, req.ShortName)
+	link, err := h.svc.Update(c.Request.Context(), id, links.LinkInput{
+		OriginalURL:   req.OriginalURL,
+		ShortName:     req.ShortName,
+		Tags:          req.Tags,
+		Disabled:      req.Disabled,
+		Title:         req.Title,
+		ForwardPath:   req.ForwardPath,
+		ForwardQuery:  req.ForwardQuery,
+		UTM:           req.UTM.Domain(),
+		Rules:         dto.DomainRules(req.Rules),
+		Variants:      dto.DomainVariants(req.Variants),
+		StickyVariant: req.StickyVariant,
+		ActiveFrom:    req.ActiveFrom,
+	}, ifVersion)
 	if err != nil {
 		h.fail(c, err)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromDomain(link, h.baseURL))
+	setLinkETag(c, link)
+
+	c.JSON(http.StatusOK, dto.FromDomain(link, h.urls))
 }
 
 func (h *Handler) DeleteLink(c *gin.Context) {
@@ -169,7 +304,12 @@ func (h *Handler) DeleteLink(c *gin.Context) {
 		return
 	}
 
-	err := h.svc.Delete(c.Request.Context(), id)
+	ifVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.Delete(c.Request.Context(), id, ifVersion)
 	if err != nil {
 		h.fail(c, err)
 
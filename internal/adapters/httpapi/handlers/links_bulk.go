@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/adapters/httpapi/problems"
+	"code/internal/app/links"
+)
+
+func (h *Handler) BulkCreateLinks(c *gin.Context) {
+	mode := links.BulkMode(c.DefaultQuery("mode", string(links.BulkModeAtomic)))
+	if mode != links.BulkModeAtomic && mode != links.BulkModeBestEffort {
+		h.fail(c, links.ErrInvalidBulkMode)
+
+		return
+	}
+
+	var reqs []CreateLinkRequest
+
+	err := BindJSONStrict(c, &reqs)
+	if err != nil {
+		badJSON(c, err)
+
+		return
+	}
+
+	if len(reqs) == 0 || len(reqs) > links.MaxBulkItems {
+		h.fail(c, links.ErrInvalidBatchSize)
+
+		return
+	}
+
+	items := make([]dto.BulkItemResponse, len(reqs))
+	inputs := make([]links.LinkInput, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs))
+
+	for i, req := range reqs {
+		req.OriginalURL = strings.TrimSpace(req.OriginalURL)
+		req.ShortName = strings.TrimSpace(req.ShortName)
+
+		items[i] = dto.BulkItemResponse{Index: i, Status: string(links.BulkItemSkipped)}
+
+		if errs, ok := validateStruct(req); ok {
+			items[i].Status = string(links.BulkItemFailed)
+			items[i].Errors = errs
+
+			continue
+		}
+
+		inputs = append(inputs, req.input())
+		indexes = append(indexes, i)
+	}
+
+	// An atomic batch with malformed items is rejected before touching the DB.
+	if len(inputs) > 0 && (mode == links.BulkModeBestEffort || len(inputs) == len(reqs)) {
+		results, err := h.svc.BulkCreate(c.Request.Context(), inputs, mode)
+		if err != nil {
+			h.fail(c, err)
+
+			return
+		}
+
+		for j, res := range results {
+			items[indexes[j]] = h.bulkItemResponse(indexes[j], res)
+		}
+	}
+
+	resp := dto.BulkCreateResponse{Mode: string(mode), Items: items}
+	for _, it := range items {
+		switch it.Status {
+		case string(links.BulkItemCreated):
+			resp.Created++
+		case string(links.BulkItemFailed):
+			resp.Failed++
+		}
+	}
+
+	status := http.StatusOK
+	if mode == links.BulkModeAtomic {
+		status = http.StatusCreated
+		if resp.Failed > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+	}
+
+	c.JSON(status, resp)
+}
+
+func (h *Handler) bulkItemResponse(index int, res links.BulkItemResult) dto.BulkItemResponse {
+	out := dto.BulkItemResponse{Index: index, Status: string(res.Status)}
+
+	switch res.Status {
+	case links.BulkItemCreated:
+		link := dto.FromDomain(res.Link, h.urls)
+		out.Link = &link
+	case links.BulkItemFailed:
+		out.Errors = itemErrors(res.Err)
+	}
+
+	return out
+}
+
+// itemErrors renders a per-item failure like writeValidationErrors does.
+func itemErrors(err error) map[string]string {
+	if errs, ok := validationErrorsFromDomain(err); ok {
+		return errs
+	}
+
+	if errors.Is(err, links.ErrLinkQuotaExceeded) {
+		return map[string]string{"quota": problems.DetailLinkQuota}
+	}
+
+	return map[string]string{"error": err.Error()}
+}
+
+// BulkDeleteLinks handles DELETE /api/links?filter=..., which react-admin
+// sends for deleteMany as filter={"id":[...]}.
+func (h *Handler) BulkDeleteLinks(c *gin.Context) {
+	filter, err := parseLinksFilter(c.Query("filter"))
+	if err != nil {
+		writeInvalidFilter(c)
+
+		return
+	}
+
+	ids, err := h.svc.BulkDelete(c.Request.Context(), filter)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BulkIDsResponse{IDs: ids})
+}
+
+// BulkPatchLinks applies one JSON Merge Patch to every link matching the
+// filter; short_name cannot be set in bulk.
+func (h *Handler) BulkPatchLinks(c *gin.Context) {
+	if !requireMergePatch(c) {
+		return
+	}
+
+	filter, err := parseLinksFilter(c.Query("filter"))
+	if err != nil {
+		writeInvalidFilter(c)
+
+		return
+	}
+
+	var req PatchLinkRequest
+
+	if err := BindJSONStrict(c, &req); err != nil {
+		badJSON(c, err)
+
+		return
+	}
+
+	ids, err := h.svc.BulkPatch(c.Request.Context(), filter, req.toPatch())
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BulkIDsResponse{IDs: ids})
+}
+
+func parseLinksFilter(raw string) (links.LinksFilter, error) {
+	f, err := parseReactAdminFilter(raw)
+	if err != nil {
+		return links.LinksFilter{}, err
+	}
+
+	if err := f.only("id", "tag", "disabled", "campaign"); err != nil {
+		return links.LinksFilter{}, err
+	}
+
+	var out links.LinksFilter
+
+	if out.IDs, err = f.int64s("id"); err != nil {
+		return links.LinksFilter{}, err
+	}
+
+	if out.Tag, err = f.string("tag"); err != nil {
+		return links.LinksFilter{}, err
+	}
+
+	out.Tag = strings.ToLower(out.Tag)
+
+	if out.Disabled, err = f.bool("disabled"); err != nil {
+		return links.LinksFilter{}, err
+	}
+
+	if out.Campaign, err = f.string("campaign"); err != nil {
+		return links.LinksFilter{}, err
+	}
+
+	return out, nil
+}
@@ -6,10 +6,12 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"code/internal/adapters/httpapi/middleware"
 	"code/internal/app/links"
 	testhttp "code/internal/testing/httptest"
 )
@@ -188,6 +190,17 @@ func TestLinksAPI_Errors(t *testing.T) {
 			title:  "Not Found",
 			detail: "not found",
 		},
+		{
+			name:    "body_too_large",
+			method:  http.MethodPost,
+			path:    apiLinksPath,
+			headers: map[string]string{"Content-Type": "application/json"},
+			rawBody: `{"title":"` + strings.Repeat("a", middleware.MaxJSONBodyBytes) + `"}`,
+			status:  http.StatusRequestEntityTooLarge,
+			typeID:  "payload_too_large",
+			title:   "Payload Too Large",
+			detail:  "request body too large",
+		},
 	}
 
 	for _, tc := range problemTests {
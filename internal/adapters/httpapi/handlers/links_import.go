@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+	"code/internal/adapters/httpapi/problems"
+	"code/internal/adapters/linkio"
+	"code/internal/app/links"
+)
+
+const importReportFilename = "import-errors.csv"
+
+func (h *Handler) ImportLinks(c *gin.Context) {
+	format, err := linkio.FormatFromContentType(c.ContentType())
+	if err != nil {
+		problems.WriteProblem(c, problems.Problem{
+			Type:   problems.ProblemTypeMediaType,
+			Title:  problems.TitleMediaType,
+			Status: http.StatusUnsupportedMediaType,
+			Detail: problems.DetailImportFormat,
+		})
+
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		problems.WriteProblem(c, badRequestProblem(problems.DetailInvalidDryRun))
+
+		return
+	}
+
+	opts := links.ImportOptions{
+		DryRun:     dryRun,
+		OnConflict: links.ConflictPolicy(c.DefaultQuery("on_conflict", string(links.ConflictFail))),
+	}
+
+	extendDeadlines(c)
+
+	src, err := linkio.NewReader(c.Request.Body, format)
+	if err != nil {
+		problems.WriteProblem(c, badRequestProblem(problems.DetailInvalidImport))
+
+		return
+	}
+
+	res, err := h.svc.ImportLinks(c.Request.Context(), src, opts)
+	if errors.Is(err, linkio.ErrLineTooLong) {
+		problems.WriteProblem(c, badRequestProblem(problems.DetailInvalidImport))
+
+		return
+	}
+
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	if c.NegotiateFormat(gin.MIMEJSON, linkio.ContentTypeCSV) == linkio.ContentTypeCSV {
+		setAttachment(c, importReportFilename)
+		c.Header("Content-Type", linkio.ContentTypeCSV)
+		c.Status(http.StatusOK)
+
+		_ = linkio.WriteErrorReport(c.Writer, res.Errors)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, importResponse(res, dryRun))
+}
+
+func importResponse(res links.ImportResult, dryRun bool) dto.ImportResponse {
+	out := dto.ImportResponse{
+		DryRun:          dryRun,
+		Rows:            res.Rows,
+		Created:         res.Created,
+		Updated:         res.Updated,
+		Skipped:         res.Skipped,
+		Failed:          res.Failed,
+		Errors:          make([]dto.ImportRowErrorResponse, 0, len(res.Errors)),
+		ErrorsTruncated: res.ErrorsTruncated,
+	}
+
+	for _, row := range res.Errors {
+		out.Errors = append(out.Errors, dto.ImportRowErrorResponse{
+			Line:        row.Line,
+			OriginalURL: row.Input.OriginalURL,
+			ShortName:   row.Input.ShortName,
+			Errors:      itemErrors(row.Err),
+		})
+	}
+
+	return out
+}
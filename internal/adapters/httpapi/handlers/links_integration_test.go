@@ -16,6 +16,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,18 +33,39 @@ import (
 	"code/internal/adapters/httpapi/stack"
 	pgrepo "code/internal/adapters/postgres"
 	"code/internal/app/links"
+	"code/internal/domain"
 	"code/internal/platform/config"
 	"code/internal/platform/postgres"
 	"code/internal/testing/dbtest"
 )
 
 var (
-	tcCtx  = context.Background()
-	pgC    *tcpg.PostgresContainer
-	db     *sql.DB
-	router *gin.Engine
+	tcCtx     = context.Background()
+	pgC       *tcpg.PostgresContainer
+	db        *sql.DB
+	router    *gin.Engine
+	linksSvc  *links.Service
+	blocklist testBlocklist
 )
 
+// testBlocklist lets tests swap the blocklist entries of the shared service.
+type testBlocklist struct {
+	list atomic.Pointer[domain.Blocklist]
+}
+
+func (b *testBlocklist) Match(rawURL string) (string, bool) {
+	return b.list.Load().Match(rawURL)
+}
+
+func (b *testBlocklist) set(t *testing.T, src string) {
+	t.Helper()
+
+	list, err := domain.ParseBlocklist(strings.NewReader(src))
+	require.NoError(t, err)
+
+	b.list.Store(list)
+}
+
 var shortNameRe = regexp.MustCompile(`^[a-zA-Z0-9]{3,32}$`)
 
 func TestMain(m *testing.M) {
@@ -117,10 +139,25 @@ func run(m *testing.M) int {
 
 	repo := pgrepo.NewRepo(db)
 	visitsRepo := pgrepo.NewLinkVisitsRepo(db)
-	svc := links.New(repo, visitsRepo, nil)
+	baseURL, _ := url.Parse(cfg.BaseURL)
+	linksSvc = links.New(repo, visitsRepo, nil,
+		links.WithTxManager(pgrepo.NewTxManager(db)),
+		links.WithQuota(links.Quota{}, pgrepo.NewQuotaRepo(db)),
+		links.WithAudit(pgrepo.NewAuditRepo(db)),
+		links.WithRevisions(pgrepo.NewRevisionsRepo(db)),
+		links.WithURLPolicy(domain.URLPolicy{
+			BlockPrivate: true,
+			SelfHosts:    append(cfg.URLSelfHosts, "sho.rt"),
+			DenyDomains:  []string{"*.denied.test"},
+		}),
+		links.WithBlocklist(&blocklist),
+		links.WithShortNameGenerator(links.StrategySequential, links.NewSequentialGenerator(repo)),
+		links.WithDomains(pgrepo.NewDomainsRepo(db), links.DomainRouting{DefaultHost: baseURL.Host}),
+	)
 
 	router = httpapi.NewEngine(
 		stack.Logger(),
+		stack.RequestID(),
 		stack.Sentry(cfg.SentryMiddlewareTimeout),
 		stack.Recovery(),
 		stack.RequestTimeout(cfg.RequestBudget),
@@ -128,7 +165,7 @@ func run(m *testing.M) int {
 	)
 
 	httpapi.RegisterRoutes(router, httpapi.RouterDeps{
-		Links:   svc,
+		Links:   linksSvc,
 		BaseURL: cfg.BaseURL,
 	})
 
@@ -413,6 +450,93 @@ func TestAPI_Redirect(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, rec2.Code)
 }
 
+func TestAPI_RootRedirects(t *testing.T) {
+	resetLinks(t)
+
+	id := createLink(t, "https://example.com/root", "rootcode")
+
+	rootRouter := httpapi.NewEngine()
+	httpapi.RegisterRoutes(rootRouter, httpapi.RouterDeps{
+		Links:         linksSvc,
+		BaseURL:       "http://localhost:8080",
+		RootRedirects: true,
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rootRouter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	rec := serve(fmt.Sprintf("/api/links/%d", id))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"short_url":"http://localhost:8080/rootcode"`)
+
+	for _, path := range []string{"/rootcode", "/r/rootcode"} {
+		rec := serve(path)
+		require.Equal(t, http.StatusFound, rec.Code, path)
+		require.Equal(t, "https://example.com/root", rec.Header().Get("Location"), path)
+	}
+
+	require.Equal(t, http.StatusNotFound, serve("/api").Code)
+	require.Equal(t, http.StatusNotFound, serve("/unknown").Code)
+	require.Equal(t, http.StatusOK, serve("/ping").Code)
+}
+
+func TestAPI_Preview(t *testing.T) {
+	resetLinks(t)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/a?x=<b>",
+		"short_name":   "peek",
+		"title":        "<script>alert(1)</script>",
+		"rules":        []map[string]any{{"os": "ios", "url": "https://apps.apple.com/app/id1"}},
+		"variants": []map[string]any{
+			{"url": "https://example.com/a?x=<b>", "weight": 1},
+			{"url": "https://example.com/b", "weight": 1},
+		},
+	}, http.StatusCreated)
+	require.Equal(t, "<script>alert(1)</script>", created["title"])
+
+	for _, path := range []string{"/r/peek+", "/r/peek?preview=1"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		require.Contains(t, rec.Header().Get("Content-Security-Policy"), "default-src 'none'")
+
+		body := rec.Body.String()
+		require.NotContains(t, body, "<script>")
+		require.Contains(t, body, "&lt;script&gt;alert(1)&lt;/script&gt;")
+		require.Contains(t, body, "https://example.com/a?x=&lt;b&gt;")
+		require.Contains(t, body, "https://apps.apple.com/app/id1")
+		require.Contains(t, body, "https://example.com/b")
+		require.Equal(t, 1, strings.Count(body, "https://example.com/a?x=&lt;b&gt;"))
+		require.Contains(t, body, `href="/r/peek"`)
+	}
+
+	// The "+" ends the code; the forwarded path after it is kept.
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/peek+/docs/start", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `href="/r/peek/docs/start"`)
+
+	var visits int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM link_visits`).Scan(&visits))
+	require.Zero(t, visits)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/nope+", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com",
+		"title":        strings.Repeat("a", 201),
+	}, http.StatusUnprocessableEntity)
+}
+
 func TestAPI_Redirect_ByShortName_StatusAndLocation(t *testing.T) {
 	resetLinks(t)
 
@@ -465,6 +589,265 @@ func TestAPI_Redirect_WritesVisit(t *testing.T) {
 	require.False(t, createdAt.IsZero())
 }
 
+func TestAPI_RedirectPassthrough(t *testing.T) {
+	resetLinks(t)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url":  "https://example.com/docs?lang=en",
+		"short_name":    "docs",
+		"forward_path":  true,
+		"forward_query": "override",
+	}, http.StatusCreated)
+	require.Equal(t, true, created["forward_path"])
+	require.Equal(t, "override", created["forward_query"])
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/docs/getting-started/../setup?lang=de", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://example.com/docs/setup?lang=de", rec.Header().Get("Location"))
+
+	var path string
+	require.NoError(t, db.QueryRowContext(tcCtx,
+		`SELECT path FROM link_visits ORDER BY id DESC LIMIT 1`).Scan(&path))
+	require.Equal(t, "/r/docs/getting-started/../setup?lang=de", path)
+
+	createLink(t, "https://example.com/plain", "plain")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/plain/extra?x=1", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://example.com/plain", rec.Header().Get("Location"))
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url":  "https://example.com",
+		"forward_query": "merge",
+	}, http.StatusUnprocessableEntity)
+}
+
+func TestAPI_UTM(t *testing.T) {
+	resetLinks(t)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/sale?utm_source=old",
+		"short_name":   "sale",
+		"utm":          map[string]any{"source": "news", "medium": "email", "campaign": "spring"},
+	}, http.StatusCreated)
+	require.Equal(t, "https://example.com/sale?utm_source=old", created["original_url"])
+	require.Equal(t, map[string]any{"source": "news", "medium": "email", "campaign": "spring"}, created["utm"])
+
+	createLink(t, "https://example.com/plain", "plain")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/sale", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://example.com/sale?utm_campaign=spring&utm_medium=email&utm_source=news",
+		rec.Header().Get("Location"))
+
+	doRequest(t, http.MethodGet, "/r/sale", nil)
+	doRequest(t, http.MethodGet, "/r/plain", nil)
+
+	rec = doRequest(t, http.MethodGet, apiLinksPath+"?filter="+url.QueryEscape(`{"campaign":"spring"}`), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var listed []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	require.Equal(t, "sale", listed[0]["short_name"])
+
+	rec = doRequest(t, http.MethodGet, apiLinkVisitsPath+"/stats?group_by=campaign", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `[{"key":"spring","visits":2},{"key":"","visits":1}]`, rec.Body.String())
+
+	rec = doRequest(t, http.MethodGet, apiLinkVisitsPath+"/stats?group_by=nope", nil)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com",
+		"utm":          map[string]any{"campaign": "no-source"},
+	}, http.StatusUnprocessableEntity)
+
+	// PATCH merges utm members: absent ones are kept, null ones cleared.
+	path := apiLinksPath + "/" + itoa(asInt64(t, created["id"]))
+	mergePatch := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path,
+		map[string]any{"utm": map[string]any{"source": "blog", "medium": nil}}, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var patched map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &patched))
+	require.Equal(t, map[string]any{"source": "blog", "campaign": "spring"}, patched["utm"])
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"utm": nil}, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &patched))
+	require.NotContains(t, patched, "utm")
+}
+
+func TestAPI_RedirectRules(t *testing.T) {
+	resetLinks(t)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/app",
+		"short_name":   "app",
+		"rules": []map[string]any{
+			{"os": "ios", "url": "https://apps.apple.com/app/id1"},
+			{"os": "android", "url": "https://play.google.com/store/apps/details?id=app"},
+		},
+	}, http.StatusCreated)
+	require.Len(t, created["rules"], 2)
+
+	redirect := func(userAgent string) string {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/r/app", nil)
+		req.Header.Set("User-Agent", userAgent)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusFound, rec.Code)
+
+		return rec.Header().Get("Location")
+	}
+
+	require.Equal(t, "https://apps.apple.com/app/id1",
+		redirect("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"))
+	require.Equal(t, "https://play.google.com/store/apps/details?id=app",
+		redirect("Mozilla/5.0 (Linux; Android 14; Pixel 8) Mobile"))
+	require.Equal(t, "https://example.com/app", redirect("Mozilla/5.0 (Windows NT 10.0; Win64; x64)"))
+
+	rec := doRequest(t, http.MethodGet, apiLinkVisitsPath+"/stats?group_by=rule", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `[{"key":"","visits":1},{"key":"0","visits":1},{"key":"1","visits":1}]`, rec.Body.String())
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com",
+		"rules":        []map[string]any{{"url": "https://example.com/any"}},
+	}, http.StatusUnprocessableEntity)
+}
+
+func TestAPI_CountryRules(t *testing.T) {
+	resetLinks(t)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/sale",
+		"short_name":   "sale",
+		"rules": []map[string]any{
+			{"country": "de", "url": "https://example.de/sale"},
+		},
+	}, http.StatusCreated)
+	require.Equal(t, []any{map[string]any{"country": "DE", "url": "https://example.de/sale"}}, created["rules"])
+
+	redirect := func(country string) string {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/r/sale", nil)
+		req.Header.Set("CF-IPCountry", country)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusFound, rec.Code)
+
+		return rec.Header().Get("Location")
+	}
+
+	require.Equal(t, "https://example.de/sale", redirect("DE"))
+	require.Equal(t, "https://example.com/sale", redirect("FR"))
+	require.Equal(t, "https://example.com/sale", redirect("XX"))
+	require.Equal(t, "https://example.com/sale", redirect(""))
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com",
+		"rules":        []map[string]any{{"country": "Germany", "url": "https://example.de"}},
+	}, http.StatusUnprocessableEntity)
+}
+
+func TestAPI_Variants(t *testing.T) {
+	resetLinks(t)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/landing",
+		"short_name":   "split",
+		"variants": []map[string]any{
+			{"url": "https://example.com/a", "weight": 0},
+			{"url": "https://example.com/b", "weight": 1},
+		},
+		"sticky_variant": true,
+	}, http.StatusCreated)
+
+	rec := doRequest(t, http.MethodGet, "/r/split", nil)
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://example.com/b", rec.Header().Get("Location"))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, "variant", cookies[0].Name)
+	require.Equal(t, "1", cookies[0].Value)
+	require.Equal(t, "/r/split", cookies[0].Path)
+
+	// Raising the weight of the first variant does not move the visitor.
+	rec = doRequestWithHeaders(t, http.MethodPatch, apiLinksPath+"/"+itoa(int64(created["id"].(float64))), map[string]any{
+		"variants": []map[string]any{
+			{"url": "https://example.com/a", "weight": 1000},
+			{"url": "https://example.com/b", "weight": 1},
+		},
+	}, map[string]string{"Content-Type": "application/merge-patch+json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/r/split", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://example.com/b", rec.Header().Get("Location"))
+
+	rec = doRequest(t, http.MethodGet, apiLinkVisitsPath+"/stats?group_by=variant", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `[{"key":"1","visits":2}]`, rec.Body.String())
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com",
+		"variants":     []map[string]any{{"url": "https://example.com/a", "weight": 1}},
+	}, http.StatusUnprocessableEntity)
+}
+
+func TestAPI_ActiveFromAndTimeRules(t *testing.T) {
+	resetLinks(t)
+
+	launch := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	created := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/product",
+		"short_name":   "drop",
+		"active_from":  launch.Format(time.RFC3339),
+	}, http.StatusCreated)
+	require.Equal(t, launch.Format(time.RFC3339), created["active_from"])
+
+	rec := doRequest(t, http.MethodGet, "/r/drop", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Going live early with a teaser until the launch.
+	rec = doRequestWithHeaders(t, http.MethodPatch, apiLinksPath+"/"+itoa(int64(created["id"].(float64))), map[string]any{
+		"active_from": nil,
+		"rules": []map[string]any{
+			{"before": launch.Format(time.RFC3339), "url": "https://example.com/teaser"},
+		},
+	}, map[string]string{"Content-Type": "application/merge-patch+json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotContains(t, rec.Body.String(), "active_from")
+
+	rec = doRequest(t, http.MethodGet, "/r/drop", nil)
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "https://example.com/teaser", rec.Header().Get("Location"))
+
+	doJSONExpectError(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com",
+		"rules": []map[string]any{{
+			"after":  launch.Format(time.RFC3339),
+			"before": launch.Add(-time.Hour).Format(time.RFC3339),
+			"url":    "https://example.com/teaser",
+		}},
+	}, http.StatusUnprocessableEntity)
+}
+
 func TestAPI_ListLinkVisits_Range(t *testing.T) {
 	resetLinks(t)
 
@@ -902,7 +1285,7 @@ func seedLinks(t *testing.T, count int) {
 func truncateLinks(t *testing.T) {
 	t.Helper()
 
-	_, err := db.ExecContext(tcCtx, `TRUNCATE link_visits, links RESTART IDENTITY`)
+	_, err := db.ExecContext(tcCtx, `TRUNCATE link_visits, link_revisions, links, domains, usage_counters, audit_events RESTART IDENTITY`)
 	require.NoError(t, err)
 }
 
@@ -921,3 +1304,822 @@ func projectRoot() string {
 		dir = parent
 	}
 }
+
+func TestAPI_Usage(t *testing.T) {
+	resetLinks(t)
+
+	createLink(t, "https://example.com/usage", "usage")
+
+	rec := doRequest(t, http.MethodGet, redirectPathPrefx+"usage", nil)
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	body := doJSON(t, http.MethodGet, "/api/usage", nil, http.StatusOK)
+	require.NotEmpty(t, asString(t, body["period"]))
+
+	linksUsage, ok := body["links"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, int64(1), asInt64(t, linksUsage["used"]))
+	require.Nil(t, linksUsage["limit"])
+
+	// Without a click limit the monthly counter is not kept.
+	clicks, ok := body["monthly_clicks"].(map[string]any)
+	require.True(t, ok)
+	require.Zero(t, asInt64(t, clicks["used"]))
+	require.Nil(t, clicks["limit"])
+}
+
+func TestAPI_Audit_RecordsMutations(t *testing.T) {
+	resetLinks(t)
+
+	rec := doRequestWithHeaders(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/before",
+		"short_name":   "audited",
+	}, map[string]string{
+		"X-Actor":      "alice",
+		"X-Request-ID": "req-create",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	id := asInt64(t, created["id"])
+
+	_ = doJSON(t, http.MethodPut, apiLinksPath+"/"+itoa(id), map[string]any{
+		"original_url": "https://example.com/after",
+		"short_name":   "audited",
+	}, http.StatusOK)
+	doNoContent(t, http.MethodDelete, apiLinksPath+"/"+itoa(id), http.StatusNoContent)
+
+	filter := url.QueryEscape(fmt.Sprintf(`{"link_id":%d}`, id))
+	sortParam := sortJSON(t, string(links.SortFieldID), links.SortAsc)
+	rec = doRequest(t, http.MethodGet, "/api/audit?range=[0,9]&sort="+sortParam+"&filter="+filter, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "audit 0-2/3", rec.Header().Get("Content-Range"))
+
+	var events []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+	require.Len(t, events, 3)
+
+	require.Equal(t, "create", asString(t, events[0]["action"]))
+	require.Equal(t, "alice", asString(t, events[0]["actor"]))
+	require.Equal(t, "req-create", asString(t, events[0]["request_id"]))
+	require.Nil(t, events[0]["before"])
+
+	require.Equal(t, "update", asString(t, events[1]["action"]))
+	require.Equal(t, "anonymous", asString(t, events[1]["actor"]))
+	before, ok := events[1]["before"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "https://example.com/before", asString(t, before["original_url"]))
+	after, ok := events[1]["after"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "https://example.com/after", asString(t, after["original_url"]))
+
+	require.Equal(t, "delete", asString(t, events[2]["action"]))
+	require.Nil(t, events[2]["after"])
+
+	rec = doRequest(t, http.MethodGet, "/api/audit?filter="+url.QueryEscape(`{"nope":1}`), nil)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+}
+
+func TestAPI_LinkRevisions_Revert(t *testing.T) {
+	resetLinks(t)
+
+	id := createLink(t, "https://example.com/v1", "rev-one")
+
+	_ = doJSON(t, http.MethodPut, apiLinksPath+"/"+itoa(id), map[string]any{
+		"original_url": "https://example.com/v2",
+		"short_name":   "rev-two",
+	}, http.StatusOK)
+
+	revs := doJSONArray(t, http.MethodGet, apiLinksPath+"/"+itoa(id)+"/revisions", nil, http.StatusOK)
+	require.Len(t, revs, 1)
+	require.Equal(t, "https://example.com/v1", asString(t, revs[0]["original_url"]))
+	require.Equal(t, "rev-one", asString(t, revs[0]["short_name"]))
+
+	revID := asInt64(t, revs[0]["id"])
+	reverted := doJSON(t, http.MethodPost,
+		fmt.Sprintf("%s/%d/revisions/%d/revert", apiLinksPath, id, revID), nil, http.StatusOK)
+	require.Equal(t, "https://example.com/v1", asString(t, reverted["original_url"]))
+	require.Equal(t, "rev-one", asString(t, reverted["short_name"]))
+
+	revs = doJSONArray(t, http.MethodGet, apiLinksPath+"/"+itoa(id)+"/revisions", nil, http.StatusOK)
+	require.Len(t, revs, 2)
+	require.Equal(t, "https://example.com/v2", asString(t, revs[0]["original_url"]))
+
+	doJSONExpectError(t, http.MethodPost,
+		fmt.Sprintf("%s/%d/revisions/%d/revert", apiLinksPath, id, revID+100), nil, http.StatusNotFound)
+	doJSONExpectError(t, http.MethodGet, apiLinksPath+"/999999/revisions", nil, http.StatusNotFound)
+}
+
+func TestAPI_LinkRevisions_OnlyDestinationChanges(t *testing.T) {
+	resetLinks(t)
+
+	id := createLink(t, "https://example.com/v1", "rev-tags")
+	path := apiLinksPath + "/" + itoa(id)
+	mergePatch := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	for _, patch := range []map[string]any{
+		{"tags": []string{"promo"}},
+		{"title": "Launch"},
+		{"disabled": true},
+	} {
+		rec := doRequestWithHeaders(t, http.MethodPatch, path, patch, mergePatch)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	require.Empty(t, doJSONArray(t, http.MethodGet, path+"/revisions", nil, http.StatusOK))
+
+	rec := doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"original_url": "https://example.com/v2"}, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	revs := doJSONArray(t, http.MethodGet, path+"/revisions", nil, http.StatusOK)
+	require.Len(t, revs, 1)
+	require.Equal(t, "https://example.com/v1", asString(t, revs[0]["original_url"]))
+}
+
+func TestAPI_Links_ETag(t *testing.T) {
+	resetLinks(t)
+
+	id := createLink(t, "https://example.com/v1", "etag-one")
+	path := apiLinksPath + "/" + itoa(id)
+
+	rec := doRequest(t, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	etag := rec.Header().Get("ETag")
+	require.Equal(t, `"1"`, etag)
+
+	rec = doRequestWithHeaders(t, http.MethodGet, path, nil, map[string]string{"If-None-Match": etag})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Equal(t, etag, rec.Header().Get("ETag"))
+	require.Empty(t, rec.Body.String())
+
+	rec = doRequestWithHeaders(t, http.MethodPut, path, map[string]any{
+		"original_url": "https://example.com/v2",
+		"short_name":   "etag-one",
+	}, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	// A stale ETag must not overwrite the newer state.
+	rec = doRequestWithHeaders(t, http.MethodPut, path, map[string]any{
+		"original_url": "https://example.com/v3",
+		"short_name":   "etag-one",
+	}, map[string]string{"If-Match": etag})
+	p := requireProblem(t, rec, http.StatusPreconditionFailed, "precondition_failed")
+	require.Equal(t, "Precondition Failed", p.Title)
+
+	got := doJSON(t, http.MethodGet, path, nil, http.StatusOK)
+	require.Equal(t, "https://example.com/v2", asString(t, got["original_url"]))
+
+	rec = doRequestWithHeaders(t, http.MethodGet, path, nil, map[string]string{"If-None-Match": etag})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequestWithHeaders(t, http.MethodDelete, path, nil, map[string]string{"If-Match": etag})
+	requireProblem(t, rec, http.StatusPreconditionFailed, "precondition_failed")
+
+	rec = doRequestWithHeaders(t, http.MethodDelete, path, nil, map[string]string{"If-Match": `"2"`})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = doRequestWithHeaders(t, http.MethodDelete, path, nil, map[string]string{"If-Match": `"2"`})
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestAPI_PatchLink_MergePatch(t *testing.T) {
+	resetLinks(t)
+
+	id := createLink(t, "https://example.com/v1", "patch-one")
+	path := apiLinksPath + "/" + itoa(id)
+	mergePatch := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	rec := doRequestWithHeaders(t, http.MethodPatch, path,
+		map[string]any{"original_url": "https://example.com/v2"}, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, "https://example.com/v2", asString(t, out["original_url"]))
+	require.Equal(t, "patch-one", asString(t, out["short_name"]))
+	require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	// A null or empty short_name is rejected rather than regenerated.
+	for _, name := range []any{nil, "", " "} {
+		rec = doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"short_name": name}, mergePatch)
+		errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+		require.Equal(t, "invalid short_name", errs["short_name"])
+	}
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"regenerate_short_name": true}, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.NotEqual(t, "patch-one", asString(t, out["short_name"]))
+	require.Equal(t, "https://example.com/v2", asString(t, out["original_url"]))
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"original_url": nil}, mergePatch)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"short_name": "x"},
+		map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": `"1"`})
+	requireProblem(t, rec, http.StatusPreconditionFailed, "precondition_failed")
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"short_name": "patch-two"},
+		map[string]string{"Content-Type": "text/plain"})
+	requireProblem(t, rec, http.StatusUnsupportedMediaType, "unsupported_media_type")
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, apiLinksPath+"/999999",
+		map[string]any{"short_name": "patch-two"}, mergePatch)
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestAPI_BulkCreateLinks(t *testing.T) {
+	resetLinks(t)
+
+	createLink(t, "https://example.com/existing", "bulk-taken")
+
+	batch := []map[string]any{
+		{"original_url": "https://example.com/1", "short_name": "bulk-one"},
+		{"original_url": "https://example.com/2", "short_name": "bulk-taken"},
+		{"original_url": "https://example.com/3", "short_name": "x"},
+		{"original_url": "https://example.com/4"},
+	}
+
+	rec := doRequest(t, http.MethodPost, apiLinksPath+"/bulk", batch)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	type bulkResp struct {
+		Created int `json:"created"`
+		Failed  int `json:"failed"`
+		Items   []struct {
+			Index  int               `json:"index"`
+			Status string            `json:"status"`
+			Link   map[string]any    `json:"link"`
+			Errors map[string]string `json:"errors"`
+		} `json:"items"`
+	}
+
+	var out bulkResp
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, 0, out.Created)
+	require.Equal(t, 1, out.Failed)
+	require.Equal(t, "failed", out.Items[2].Status)
+	require.Contains(t, out.Items[2].Errors, "short_name")
+	require.Equal(t, "skipped", out.Items[1].Status)
+
+	list := doJSONArray(t, http.MethodGet, apiLinksPath, nil, http.StatusOK)
+	require.Len(t, list, 1)
+
+	rec = doRequest(t, http.MethodPost, apiLinksPath+"/bulk?mode=best_effort", batch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	out = bulkResp{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, 2, out.Created)
+	require.Equal(t, 2, out.Failed)
+	require.Equal(t, "created", out.Items[0].Status)
+	require.Equal(t, "bulk-one", asString(t, out.Items[0].Link["short_name"]))
+	require.Equal(t, map[string]string{"short_name": "short name already in use"}, out.Items[1].Errors)
+	require.Equal(t, "created", out.Items[3].Status)
+
+	list = doJSONArray(t, http.MethodGet, apiLinksPath, nil, http.StatusOK)
+	require.Len(t, list, 3)
+
+	rec = doRequest(t, http.MethodPost, apiLinksPath+"/bulk", []map[string]any{
+		{"original_url": "https://example.com/5", "short_name": "bulk-five"},
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, http.MethodPost, apiLinksPath+"/bulk", []map[string]any{})
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+
+	rec = doRequest(t, http.MethodPost, apiLinksPath+"/bulk?mode=sometimes", batch)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+}
+
+func doImport(t *testing.T, query, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, apiLinksPath+"/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAPI_ImportLinks(t *testing.T) {
+	resetLinks(t)
+
+	createLink(t, "https://example.com/existing", "import-taken")
+
+	csvBody := "url,slug,tags\n" +
+		"https://example.com/1,import-one,\"a,b\"\n" +
+		"https://example.com/2,import-taken,\n" +
+		"not-a-url,import-three,\n"
+
+	type importResp struct {
+		DryRun  bool `json:"dry_run"`
+		Rows    int  `json:"rows"`
+		Created int  `json:"created"`
+		Updated int  `json:"updated"`
+		Skipped int  `json:"skipped"`
+		Failed  int  `json:"failed"`
+		Errors  []struct {
+			Line   int               `json:"line"`
+			Errors map[string]string `json:"errors"`
+		} `json:"errors"`
+	}
+
+	rec := doImport(t, "?dry_run=true", "text/csv", csvBody, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var out importResp
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.True(t, out.DryRun)
+	require.Equal(t, 3, out.Rows)
+	require.Equal(t, 1, out.Created)
+	require.Equal(t, 2, out.Failed)
+	require.Equal(t, 3, out.Errors[0].Line)
+	require.Contains(t, out.Errors[0].Errors, "short_name")
+	require.Contains(t, out.Errors[1].Errors, "original_url")
+
+	list := doJSONArray(t, http.MethodGet, apiLinksPath, nil, http.StatusOK)
+	require.Len(t, list, 1)
+
+	rec = doImport(t, "?on_conflict=overwrite", "text/csv", csvBody, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	out = importResp{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, 1, out.Created)
+	require.Equal(t, 1, out.Updated)
+	require.Equal(t, 1, out.Failed)
+
+	one := getLinkByShortName(t, "import-one")
+	require.Equal(t, []any{"a", "b"}, one["tags"])
+
+	taken := getLinkByShortName(t, "import-taken")
+	require.Equal(t, "https://example.com/2", asString(t, taken["original_url"]))
+
+	ndjsonBody := `{"original_url":"https://example.com/4","short_name":"import-four"}` + "\n" +
+		`{"original_url":"https://example.com/5","short_name":"import-one"}` + "\n" +
+		`{broken` + "\n"
+
+	rec = doImport(t, "?on_conflict=skip", "application/x-ndjson", ndjsonBody, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	out = importResp{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, 1, out.Created)
+	require.Equal(t, 1, out.Skipped)
+	require.Equal(t, 1, out.Failed)
+
+	rec = doImport(t, "", "text/csv", csvBody, map[string]string{"Accept": "text/csv"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Header().Get("Content-Disposition"), "import-errors.csv")
+	require.True(t, strings.HasPrefix(rec.Body.String(), "line,original_url,short_name,tags,disabled,"))
+
+	rec = doImport(t, "", "application/xml", "<links/>", nil)
+	requireProblem(t, rec, http.StatusUnsupportedMediaType, "unsupported_media_type")
+
+	rec = doImport(t, "?on_conflict=merge", "text/csv", csvBody, nil)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+
+	rec = doImport(t, "", "text/csv", "tags\na\n", nil)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+}
+
+func TestAPI_ExportImportRoundTrip(t *testing.T) {
+	for _, accept := range []string{"text/csv", "application/x-ndjson"} {
+		resetLinks(t)
+
+		createLink(t, "https://example.com/on", "trip-on")
+		offID := createLink(t, "https://example.com/off", "trip-off")
+
+		rec := doRequestWithHeaders(t, http.MethodPatch, apiLinksPath+"/"+itoa(offID),
+			map[string]any{"disabled": true}, map[string]string{"Content-Type": "application/merge-patch+json"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doRequestWithHeaders(t, http.MethodGet, apiLinksPath+"/export", nil, map[string]string{"Accept": accept})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		backup := rec.Body.String()
+
+		resetLinks(t)
+
+		rec = doImport(t, "", accept, backup, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		require.Equal(t, false, getLinkByShortName(t, "trip-on")["disabled"], accept)
+		require.Equal(t, true, getLinkByShortName(t, "trip-off")["disabled"], accept)
+	}
+}
+
+func TestAPI_Export(t *testing.T) {
+	resetLinks(t)
+
+	seedLinks(t, 3)
+
+	rec := doRequestWithHeaders(t, http.MethodGet, apiLinksPath+"/export?sort="+sortJSON(t, "id", links.SortDesc), nil,
+		map[string]string{"Accept": "text/csv"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="links.csv"`, rec.Header().Get("Content-Disposition"))
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "id,original_url,short_name,short_url,tags,disabled,domain,title,forward_path,forward_query,"+
+		"utm_source,utm_medium,utm_campaign,utm_term,utm_content,rules,variants,sticky_variant,active_from", lines[0])
+	require.True(t, strings.HasPrefix(lines[1], "3,"))
+
+	rec = doRequestWithHeaders(t, http.MethodGet, apiLinksPath+"/export", nil,
+		map[string]string{"Accept": "application/x-ndjson"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, `attachment; filename="links.ndjson"`, rec.Header().Get("Content-Disposition"))
+
+	lines = strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Equal(t, int64(1), asInt64(t, first["id"]))
+
+	doRequest(t, http.MethodGet, redirectPathPrefx+asString(t, first["short_name"]), nil)
+
+	rec = doRequestWithHeaders(t, http.MethodGet, apiLinkVisitsPath+"/export", nil,
+		map[string]string{"Accept": "text/csv"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	lines = strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "id,link_id,created_at,ip,user_agent,reffer,status,path,rule,variant", lines[0])
+
+	rec = doRequestWithHeaders(t, http.MethodGet, apiLinksPath+"/export", nil,
+		map[string]string{"Accept": "application/xml"})
+	requireProblem(t, rec, http.StatusNotAcceptable, "not_acceptable")
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, apiLinksPath+"/"+itoa(asInt64(t, first["id"])),
+		map[string]any{"disabled": true}, map[string]string{"Content-Type": "application/merge-patch+json"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequestWithHeaders(t, http.MethodGet,
+		apiLinksPath+"/export?filter="+url.QueryEscape(`{"disabled":true}`), nil,
+		map[string]string{"Accept": "application/x-ndjson"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	lines = strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], `"short_name":"`+asString(t, first["short_name"])+`"`)
+
+	rec = doRequestWithHeaders(t, http.MethodGet, apiLinksPath+"/export?filter=nope", nil,
+		map[string]string{"Accept": "text/csv"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequestWithHeaders(t, http.MethodGet, apiLinksPath+"/export?sort="+sortJSONRaw(t, "nope", "ASC"), nil,
+		map[string]string{"Accept": "text/csv"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, rec.Header().Get("Content-Disposition"))
+}
+
+func TestAPI_BulkPatchAndDelete(t *testing.T) {
+	resetLinks(t)
+
+	first := createLink(t, "https://example.com/1", "bulk-a")
+	second := createLink(t, "https://example.com/2", "bulk-b")
+	third := createLink(t, "https://example.com/3", "bulk-c")
+
+	type idsResp struct {
+		IDs []int64 `json:"ids"`
+	}
+
+	filter := url.QueryEscape(fmt.Sprintf(`{"id":[%d,%d,9999]}`, first, second))
+
+	rec := doRequest(t, http.MethodPatch, apiLinksPath+"?filter="+filter, map[string]any{
+		"tags":     []string{"promo"},
+		"disabled": true,
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var out idsResp
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, []int64{first, second}, out.IDs)
+
+	link := doJSON(t, http.MethodGet, apiLinksPath+"/"+itoa(first), nil, http.StatusOK)
+	require.Equal(t, true, link["disabled"])
+	require.Equal(t, []any{"promo"}, link["tags"])
+	require.Equal(t, "bulk-a", asString(t, link["short_name"]))
+
+	rec = doRequest(t, http.MethodGet, redirectPathPrefx+"bulk-a", nil)
+	requireProblem(t, rec, http.StatusGone, "gone")
+
+	rec = doRequest(t, http.MethodGet, redirectPathPrefx+"bulk-c", nil)
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	rec = doRequest(t, http.MethodPatch, apiLinksPath+"?filter="+filter, map[string]any{"short_name": "clash"})
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+
+	rec = doRequest(t, http.MethodDelete, apiLinksPath, nil)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+
+	rec = doRequest(t, http.MethodDelete, apiLinksPath+"?filter="+url.QueryEscape(`{"name":"x"}`), nil)
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+
+	rec = doRequest(t, http.MethodDelete, apiLinksPath+"?filter="+url.QueryEscape(`{"tag":"promo","disabled":true}`), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	out = idsResp{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, []int64{first, second}, out.IDs)
+
+	list := doJSONArray(t, http.MethodGet, apiLinksPath, nil, http.StatusOK)
+	require.Len(t, list, 1)
+	require.Equal(t, third, asInt64(t, list[0]["id"]))
+
+	var deletes int
+	err := db.QueryRowContext(tcCtx, `SELECT COUNT(*) FROM audit_events WHERE action = 'delete'`).Scan(&deletes)
+	require.NoError(t, err)
+	require.Equal(t, 2, deletes)
+}
+
+func TestAPI_CreateLink_ReuseExisting(t *testing.T) {
+	resetLinks(t)
+
+	reusePath := apiLinksPath + "?reuse_existing=true"
+
+	first := doJSON(t, http.MethodPost, reusePath, map[string]any{"original_url": "https://Example.com:443"}, http.StatusCreated)
+	again := doJSON(t, http.MethodPost, reusePath, map[string]any{"original_url": "https://example.com/"}, http.StatusOK)
+	require.Equal(t, asInt64(t, first["id"]), asInt64(t, again["id"]))
+	require.Equal(t, asString(t, first["short_name"]), asString(t, again["short_name"]))
+
+	plain := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{"original_url": "https://example.com/"}, http.StatusCreated)
+	require.NotEqual(t, asInt64(t, first["id"]), asInt64(t, plain["id"]))
+
+	named := doJSON(t, http.MethodPost, reusePath, map[string]any{
+		"original_url": "https://example.com/",
+		"short_name":   "reuse-named",
+	}, http.StatusCreated)
+	require.Equal(t, "reuse-named", asString(t, named["short_name"]))
+
+	rec := doRequest(t, http.MethodPost, apiLinksPath+"?reuse_existing=maybe", map[string]any{"original_url": "https://example.com/"})
+	requireProblem(t, rec, http.StatusBadRequest, "validation_error")
+}
+
+func TestAPI_FlaggedLinkEchoedBack(t *testing.T) {
+	resetLinks(t)
+	t.Cleanup(func() { blocklist.list.Store(nil) })
+
+	id := createLink(t, "https://later.test/echo", "bl-echo")
+	path := apiLinksPath + "/" + itoa(id)
+	mergePatch := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	blocklist.set(t, "later.test\n")
+
+	res, err := linksSvc.RescanBlocklist(tcCtx)
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Flagged)
+
+	// react-admin sends the fetched record back as is, read-only fields
+	// included.
+	link := doJSON(t, http.MethodGet, path, nil, http.StatusOK)
+	require.NotEmpty(t, link["flagged_at"])
+	require.Equal(t, "later.test", link["flag_reason"])
+
+	rec := doRequest(t, http.MethodPut, path, link)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, link, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	link["disabled"] = false
+	rec = doRequestWithHeaders(t, http.MethodPatch, path, link, mergePatch)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, false, out["disabled"])
+	require.NotContains(t, out, "flagged_at")
+}
+
+func TestAPI_URLPolicy(t *testing.T) {
+	resetLinks(t)
+
+	cases := map[string]string{
+		"http://127.0.0.1:5432/":    "url points to a private or local address",
+		"http://intranet/wiki":      "url points to a private or local address",
+		"https://sho.rt/r/abc":      "url points to this link shortener",
+		"https://cdn.denied.test/x": "url domain is denied",
+	}
+
+	for target, msg := range cases {
+		rec := doRequest(t, http.MethodPost, apiLinksPath, map[string]any{"original_url": target})
+		errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+		require.Equal(t, msg, errs["original_url"], target)
+	}
+
+	id := createLink(t, "https://example.com/policy", "policy-ok")
+
+	rec := doRequest(t, http.MethodPut, apiLinksPath+"/"+itoa(id), map[string]any{
+		"original_url": "http://10.0.0.1/",
+		"short_name":   "policy-ok",
+	})
+	errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "url points to a private or local address", errs["original_url"])
+}
+
+func TestAPI_Blocklist(t *testing.T) {
+	resetLinks(t)
+	blocklist.set(t, "phish.test\n")
+	t.Cleanup(func() { blocklist.list.Store(nil) })
+
+	rec := doRequest(t, http.MethodPost, apiLinksPath, map[string]any{"original_url": "https://phish.test/login"})
+	errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "url is blocklisted", errs["original_url"])
+
+	okID := createLink(t, "https://example.com/fine", "bl-ok")
+	badID := createLink(t, "https://later.test/login", "bl-bad")
+
+	blocklist.set(t, "phish.test\n*.test\n")
+
+	res, err := linksSvc.RescanBlocklist(tcCtx)
+	require.NoError(t, err)
+	require.Equal(t, links.BlocklistRescan{Scanned: 2, Flagged: 1}, res)
+
+	flagged := doJSONArray(t, http.MethodGet, apiLinksPath+"/flagged", nil, http.StatusOK)
+	require.Len(t, flagged, 1)
+	require.Equal(t, badID, asInt64(t, flagged[0]["id"]))
+	require.Equal(t, true, flagged[0]["disabled"])
+	require.Equal(t, "*.test", flagged[0]["flag_reason"])
+	require.NotEmpty(t, flagged[0]["flagged_at"])
+
+	events := doJSONArray(t, http.MethodGet, "/api/audit?filter="+url.QueryEscape(`{"action":"flag"}`), nil, http.StatusOK)
+	require.Len(t, events, 1)
+	require.Equal(t, links.BlocklistActor, events[0]["actor"])
+
+	// A second rescan leaves the disabled link alone.
+	res, err = linksSvc.RescanBlocklist(tcCtx)
+	require.NoError(t, err)
+	require.Equal(t, 0, res.Flagged)
+
+	// Re-enabling an unchanged destination is allowed and clears the flag.
+	rec = doRequest(t, http.MethodPut, apiLinksPath+"/"+itoa(badID), map[string]any{
+		"original_url": "https://later.test/login",
+		"short_name":   "bl-bad",
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, doJSONArray(t, http.MethodGet, apiLinksPath+"/flagged", nil, http.StatusOK))
+
+	rec = doRequest(t, http.MethodPut, apiLinksPath+"/"+itoa(okID), map[string]any{
+		"original_url": "https://phish.test/",
+		"short_name":   "bl-ok",
+	})
+	errs = requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "url is blocklisted", errs["original_url"])
+}
+
+func TestAPI_ReservedShortName(t *testing.T) {
+	resetLinks(t)
+
+	rec := doRequest(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/",
+		"short_name":   "Admin",
+	})
+	errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "short name is reserved", errs["short_name"])
+}
+
+func TestAPI_ShortNameStrategy(t *testing.T) {
+	resetLinks(t)
+
+	var names []string
+
+	for range 2 {
+		got := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+			"original_url":        "https://example.com/",
+			"short_name_strategy": "sequential",
+		}, http.StatusCreated)
+
+		names = append(names, got["short_name"].(string))
+	}
+
+	require.NotEqual(t, names[0], names[1])
+
+	got := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url":        "https://example.com/",
+		"short_name_strategy": "words",
+	}, http.StatusCreated)
+	require.Regexp(t, `^[a-z]+-[a-z]+-[0-9]+$`, got["short_name"])
+
+	rec := doRequest(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url":        "https://example.com/",
+		"short_name_strategy": "uuid",
+	})
+	errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "unknown short name strategy", errs["short_name_strategy"])
+}
+
+func TestAPI_CaseInsensitiveShortNames(t *testing.T) {
+	resetLinks(t)
+
+	t.Cleanup(func() {
+		_, err := db.ExecContext(tcCtx, "DROP INDEX IF EXISTS links_short_name_lower_key")
+		require.NoError(t, err)
+	})
+
+	repo := pgrepo.NewRepo(db)
+	lowerID := createLink(t, "https://example.com/lower", "case-a")
+	upperID := createLink(t, "https://example.com/upper", "CASE-A")
+
+	found, err := repo.ShortNameCollisions(tcCtx)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, "case-a", found[0].Folded)
+	require.Equal(t, []pgrepo.ShortNameCollisionLink{
+		{ID: lowerID, ShortName: "case-a"},
+		{ID: upperID, ShortName: "CASE-A"},
+	}, found[0].Links)
+
+	require.ErrorIs(t, repo.EnableCaseInsensitiveShortNames(tcCtx), domain.ErrShortNameConflict)
+
+	// Until the collision is resolved an exact match wins.
+	link, err := repo.GetByShortNameFold(tcCtx, "", "CASE-A")
+	require.NoError(t, err)
+	require.Equal(t, upperID, link.ID)
+
+	rec := doRequest(t, http.MethodPut, apiLinksPath+"/"+itoa(upperID), map[string]any{
+		"original_url": "https://example.com/upper",
+		"short_name":   "case-b",
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.NoError(t, repo.EnableCaseInsensitiveShortNames(tcCtx))
+
+	enabled, err := repo.CaseInsensitiveShortNames(tcCtx)
+	require.NoError(t, err)
+	require.True(t, enabled)
+
+	link, err = repo.GetByShortNameFold(tcCtx, "", "Case-A")
+	require.NoError(t, err)
+	require.Equal(t, lowerID, link.ID)
+
+	rec = doRequest(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/",
+		"short_name":   "Case-B",
+	})
+	errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "short name already in use", errs["short_name"])
+}
+
+func TestAPI_Domains(t *testing.T) {
+	resetLinks(t)
+
+	createLink(t, "https://example.com/default", "promo")
+
+	created := doJSON(t, http.MethodPost, "/api/domains", map[string]any{"host": "Go.Brand-A.test"}, http.StatusCreated)
+	require.Equal(t, "go.brand-a.test", asString(t, created["host"]))
+	domainID := asInt64(t, created["id"])
+
+	rec := doRequest(t, http.MethodPost, "/api/domains", map[string]any{"host": "go.brand-a.test"})
+	requireProblem(t, rec, http.StatusConflict, "conflict")
+
+	rec = doRequest(t, http.MethodPost, "/api/domains", map[string]any{"host": "localhost"})
+	errs := requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "invalid host", errs["host"])
+
+	// The same short name is free on another domain.
+	link := doJSON(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/brand-a",
+		"short_name":   "promo",
+		"domain":       "go.brand-a.test",
+	}, http.StatusCreated)
+	require.Equal(t, "go.brand-a.test", asString(t, link["domain"]))
+	require.Equal(t, "http://go.brand-a.test/r/promo", asString(t, link["short_url"]))
+
+	rec = doRequest(t, http.MethodPost, apiLinksPath, map[string]any{
+		"original_url": "https://example.com/brand-b",
+		"domain":       "go.brand-b.test",
+	})
+	errs = requireValidationErrors(t, rec, http.StatusUnprocessableEntity)
+	require.Equal(t, "unknown domain", errs["domain"])
+
+	redirect := func(host string) string {
+		req := httptest.NewRequest(http.MethodGet, "/r/promo", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusFound, rec.Code, host)
+
+		return rec.Header().Get("Location")
+	}
+
+	require.Equal(t, "https://example.com/brand-a", redirect("go.brand-a.test"))
+	require.Equal(t, "https://example.com/brand-a", redirect("GO.BRAND-A.TEST:443"))
+	require.Equal(t, "https://example.com/default", redirect("localhost:8080"))
+	require.Equal(t, "https://example.com/default", redirect("unknown.test"))
+
+	list := doJSONArray(t, http.MethodGet, "/api/domains", nil, http.StatusOK)
+	require.Len(t, list, 1)
+
+	rec = doRequest(t, http.MethodDelete, "/api/domains/"+itoa(domainID), nil)
+	requireProblem(t, rec, http.StatusConflict, "conflict")
+
+	doNoContent(t, http.MethodDelete, "/api/links/"+itoa(asInt64(t, link["id"])), http.StatusNoContent)
+	doNoContent(t, http.MethodDelete, "/api/domains/"+itoa(domainID), http.StatusNoContent)
+	doNoContent(t, http.MethodGet, "/api/domains/"+itoa(domainID), http.StatusNotFound)
+}
@@ -53,6 +53,15 @@ func writeInvalidRange(c *gin.Context) {
 	})
 }
 
+func writeInvalidFilter(c *gin.Context) {
+	problems.WriteProblem(c, problems.Problem{
+		Type:   problems.ProblemTypeValidation,
+		Title:  problems.TitleValidation,
+		Status: http.StatusBadRequest,
+		Detail: problems.DetailInvalidFilter,
+	})
+}
+
 func writeInvalidSort(c *gin.Context) {
 	problems.WriteProblem(c, problems.Problem{
 		Type:   problems.ProblemTypeValidation,
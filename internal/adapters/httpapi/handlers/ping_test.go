@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 
+	"code/internal/adapters/httpapi/dto"
 	"code/internal/adapters/httpapi/handlers"
 )
 
@@ -15,7 +16,7 @@ func TestPing(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	router := gin.New()
-	h := handlers.New(nil, "")
+	h := handlers.New(nil, dto.ShortURLs{})
 	router.GET("/ping", h.Ping)
 
 	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
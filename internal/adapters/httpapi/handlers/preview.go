@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// previewSuffix appended to a short URL asks for the preview page.
+const previewSuffix = "+"
+
+const previewStyle = `body{font-family:system-ui,sans-serif;max-width:40rem;margin:3rem auto;padding:0 1rem;color:#222}` +
+	`dt{font-weight:600;margin-top:1rem}dd{margin:0;overflow-wrap:anywhere}` +
+	`a.go{display:inline-block;margin-top:2rem;padding:.5rem 1rem;background:#0b57d0;color:#fff;text-decoration:none;border-radius:4px}`
+
+// Destinations are only shown as text; html/template escapes it like every
+// other value.
+var previewTmpl = template.Must(template.New("preview").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Preview: {{.ShortName}}</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<h1>{{if .Title}}{{.Title}}{{else}}{{.ShortName}}{{end}}</h1>
+<p>This short link leads to:</p>
+<dl>
+<dt>{{if gt (len .Destinations) 1}}Destinations{{else}}Destination{{end}}</dt>
+{{range .Destinations}}<dd>{{.}}</dd>
+{{end -}}
+<dt>Created</dt>
+<dd><time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.CreatedAt.Format "2 Jan 2006 15:04 MST"}}</time></dd>
+<dt>Clicks</dt>
+<dd>{{.Clicks}}</dd>
+</dl>
+<a class="go" href="{{.ContinueURL}}" rel="nofollow">Continue to the destination</a>
+</body>
+</html>
+`))
+
+// previewCSP only allows the inline style above, by hash, so nothing else
+// on the page can load or run even if escaping were bypassed.
+var previewCSP = "default-src 'none'; style-src '" + styleHash(previewStyle) + "'; " +
+	"base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+type previewPage struct {
+	ShortName    string
+	Title        string
+	Destinations []string
+	CreatedAt    time.Time
+	Clicks       int64
+	ContinueURL  string
+	Style        template.CSS
+}
+
+// previewCode returns the short name of the request and whether the preview
+// page was asked for, with a "+" suffix or preview=1.
+func previewCode(c *gin.Context) (string, bool) {
+	code := c.Param("code")
+	if trimmed, ok := strings.CutSuffix(code, previewSuffix); ok {
+		return trimmed, true
+	}
+
+	return code, c.Query("preview") == "1"
+}
+
+// Preview renders the destinations, creation date, title and click count of a
+// link instead of redirecting; no visit is recorded.
+func (h *Handler) Preview(c *gin.Context, code string) {
+	preview, err := h.svc.Preview(c.Request.Context(), c.Request.Host, code)
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	// Following the short link itself records the visit. The "+" ends the
+	// code, not the path, when a forwarded path follows it.
+	rest := c.Param("rest")
+	prefix := strings.TrimSuffix(c.Request.URL.Path, c.Param("code")+rest)
+
+	page := previewPage{
+		ShortName:    preview.Link.ShortName,
+		Title:        preview.Link.Title,
+		Destinations: preview.Destinations,
+		CreatedAt:    preview.Link.CreatedAt.UTC(),
+		Clicks:       preview.Clicks,
+		ContinueURL:  prefix + code + rest,
+		Style:        template.CSS(previewStyle),
+	}
+
+	var buf bytes.Buffer
+	if err := previewTmpl.Execute(&buf, page); err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Security-Policy", previewCSP)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("Cache-Control", "no-store")
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func styleHash(style string) string {
+	sum := sha256.Sum256([]byte(style))
+
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"code/internal/adapters/httpapi/problems"
+	"code/internal/app/links"
 	"code/internal/domain"
 )
 
@@ -19,6 +20,47 @@ func problemFromError(err error) problems.Problem {
 			Status: http.StatusNotFound,
 			Detail: problems.DetailNotFound,
 		}
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return problems.Problem{
+			Type:   problems.ProblemTypePrecondition,
+			Title:  problems.TitlePrecondition,
+			Status: http.StatusPreconditionFailed,
+			Detail: problems.DetailVersionMismatch,
+		}
+	case errors.Is(err, domain.ErrLinkDisabled):
+		return problems.Problem{
+			Type:   problems.ProblemTypeGone,
+			Title:  problems.TitleGone,
+			Status: http.StatusGone,
+			Detail: problems.DetailLinkDisabled,
+		}
+	case errors.Is(err, domain.ErrLinkNotActive):
+		return problems.Problem{
+			Type:   problems.ProblemTypeNotFound,
+			Title:  problems.TitleNotFound,
+			Status: http.StatusNotFound,
+			Detail: problems.DetailLinkNotActive,
+		}
+	case errors.Is(err, domain.ErrDomainConflict):
+		return conflictProblem(problems.DetailDomainConflict)
+	case errors.Is(err, domain.ErrDomainInUse):
+		return conflictProblem(problems.DetailDomainInUse)
+	case errors.Is(err, links.ErrInvalidBatchSize):
+		return badRequestProblem(problems.DetailInvalidBatchSize)
+	case errors.Is(err, links.ErrInvalidBulkMode):
+		return badRequestProblem(problems.DetailInvalidBulkMode)
+	case errors.Is(err, links.ErrEmptyBulkFilter):
+		return badRequestProblem(problems.DetailEmptyBulkFilter)
+	case errors.Is(err, links.ErrInvalidBulkPatch):
+		return badRequestProblem(problems.DetailInvalidBulkPatch)
+	case errors.Is(err, links.ErrInvalidConflictPolicy):
+		return badRequestProblem(problems.DetailInvalidConflict)
+	case errors.Is(err, links.ErrInvalidStatsGroup):
+		return badRequestProblem(problems.DetailInvalidStatsGroup)
+	case errors.Is(err, links.ErrLinkQuotaExceeded):
+		return quotaProblem(problems.DetailLinkQuota)
+	case errors.Is(err, links.ErrClickQuotaExceeded):
+		return quotaProblem(problems.DetailClickQuota)
 	case isTimeout(err):
 		return problems.Problem{
 			Type:   problems.ProblemTypeTimeout,
@@ -43,6 +85,33 @@ func problemFromError(err error) problems.Problem {
 	}
 }
 
+func badRequestProblem(detail string) problems.Problem {
+	return problems.Problem{
+		Type:   problems.ProblemTypeValidation,
+		Title:  problems.TitleValidation,
+		Status: http.StatusBadRequest,
+		Detail: detail,
+	}
+}
+
+func conflictProblem(detail string) problems.Problem {
+	return problems.Problem{
+		Type:   problems.ProblemTypeConflict,
+		Title:  problems.TitleConflict,
+		Status: http.StatusConflict,
+		Detail: detail,
+	}
+}
+
+func quotaProblem(detail string) problems.Problem {
+	return problems.Problem{
+		Type:   problems.ProblemTypeQuota,
+		Title:  problems.TitleForbidden,
+		Status: http.StatusForbidden,
+		Detail: detail,
+	}
+}
+
 func isTimeout(err error) bool {
 	if err == nil {
 		return false
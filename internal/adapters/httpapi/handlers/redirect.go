@@ -1,26 +1,107 @@
 package handlers
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+// variantCookie remembers the A/B variant of a sticky link. It is scoped to
+// the short link path, so each link keeps its own.
+const (
+	variantCookie    = "variant"
+	variantCookieTTL = 30 * 24 * time.Hour
 )
 
+// countryHeader carries the country Cloudflare resolved for the client. The
+// engine already trusts the platform for the client IP, so the header is
+// trusted the same way.
+const countryHeader = "CF-IPCountry"
+
+// RootRedirect serves short links at the root path. Reserved names are top
+// level paths of the service and its proxy, so they are never looked up even
+// when a link predating the reserved list claims one.
+func (h *Handler) RootRedirect(c *gin.Context) {
+	if code, _ := previewCode(c); domain.IsReservedShortName(code) {
+		h.NotFound(c)
+
+		return
+	}
+
+	h.Redirect(c)
+}
+
+// Redirect also serves the catch-all route, where the path after the short
+// name is in the "rest" param.
 func (h *Handler) Redirect(c *gin.Context) {
-	code := c.Param("code")
+	code, preview := previewCode(c)
+	if preview {
+		h.Preview(c, code)
+
+		return
+	}
 
 	meta := links.VisitMeta{
 		IP:        c.ClientIP(),
 		UserAgent: c.GetHeader("User-Agent"),
 		Referer:   c.GetHeader("Referer"),
+		Path:      c.Request.URL.RequestURI(),
+		ExtraPath: c.Param("rest"),
+		RawQuery:  c.Request.URL.RawQuery,
+		Country:   countryFromHeader(c),
+		Variant:   variantFromCookie(c),
 	}
 
-	url, status, err := h.svc.Redirect(c.Request.Context(), code, meta)
+	redirection, err := h.svc.Redirect(c.Request.Context(), c.Request.Host, code, meta)
 	if err != nil {
 		h.fail(c, err)
 
 		return
 	}
 
-	c.Redirect(status, url)
+	if redirection.StickyVariant {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     variantCookie,
+			Value:    strconv.Itoa(*redirection.Variant),
+			Path:     strings.TrimSuffix(c.Request.URL.Path, c.Param("rest")),
+			MaxAge:   int(variantCookieTTL.Seconds()),
+			Secure:   strings.HasPrefix(h.urls.BaseURL, "https://"),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	c.Redirect(redirection.Status, redirection.URL)
+}
+
+// countryFromHeader drops the codes Cloudflare uses for unknown countries
+// ("XX") and Tor ("T1"), which leaves the lookup to the GeoIP resolver.
+func countryFromHeader(c *gin.Context) string {
+	country := strings.ToUpper(strings.TrimSpace(c.GetHeader(countryHeader)))
+	if country == "XX" || !domain.IsCountryCode(country) {
+		return ""
+	}
+
+	return country
+}
+
+// variantFromCookie ignores malformed values; the service checks the index.
+func variantFromCookie(c *gin.Context) *int {
+	raw, err := c.Cookie(variantCookie)
+	if err != nil {
+		return nil
+	}
+
+	variant, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil
+	}
+
+	return &variant
 }
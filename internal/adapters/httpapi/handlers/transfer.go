@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// extendDeadlines lets long-running transfers outlive the server-wide read
+// and write timeouts, up to the request context deadline set for the route
+// (none when the route has no budget).
+func extendDeadlines(c *gin.Context) {
+	deadline, _ := c.Request.Context().Deadline()
+
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+}
+
+func setAttachment(c *gin.Context, filename string) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
+
+// dropConnection closes the connection of a response that is already under
+// way, so the client sees an incomplete transfer rather than a body that
+// merely looks short. Connections that cannot be hijacked (HTTP/2) are left
+// to end normally.
+func dropConnection(c *gin.Context) {
+	conn, _, err := http.NewResponseController(c.Writer).Hijack()
+	if err != nil {
+		return
+	}
+
+	_ = conn.Close()
+}
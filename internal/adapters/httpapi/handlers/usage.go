@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/dto"
+)
+
+func (h *Handler) GetUsage(c *gin.Context) {
+	usage, err := h.svc.Usage(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromUsage(usage))
+}
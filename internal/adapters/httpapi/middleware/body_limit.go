@@ -0,0 +1,6 @@
+package middleware
+
+// MaxJSONBodyBytes caps JSON request bodies, both in the handlers and in the
+// copy Idempotency reads to fingerprint a request. A bulk request of 500
+// links with rules and variants fits well within it.
+const MaxJSONBodyBytes = 4 << 20
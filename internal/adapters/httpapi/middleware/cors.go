@@ -8,9 +8,9 @@ import (
 )
 
 const (
-	allowedMethods = "GET,POST,PUT,DELETE,OPTIONS"
-	allowedHeaders = "Content-Type, Authorization, Range"
-	exposeHeaders  = "Content-Range, Location"
+	allowedMethods = "GET,POST,PUT,PATCH,DELETE,OPTIONS"
+	allowedHeaders = "Content-Type, Authorization, Range, X-Actor, If-Match, If-None-Match, Idempotency-Key"
+	exposeHeaders  = "Content-Range, Location, ETag"
 )
 
 func CORS(allowedOrigins []string) gin.HandlerFunc {
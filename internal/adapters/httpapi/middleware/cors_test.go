@@ -30,7 +30,7 @@ func TestCORS_AllowsOrigin(t *testing.T) {
 	require.Equal(t, "Origin", rec.Header().Get("Vary"))
 	require.NotEmpty(t, rec.Header().Get("Access-Control-Allow-Methods"))
 	require.NotEmpty(t, rec.Header().Get("Access-Control-Allow-Headers"))
-	require.Equal(t, "Content-Range, Location", rec.Header().Get("Access-Control-Expose-Headers"))
+	require.Equal(t, "Content-Range, Location, ETag", rec.Header().Get("Access-Control-Expose-Headers"))
 }
 
 func TestCORS_HandlesPreflight(t *testing.T) {
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"code/internal/adapters/httpapi/problems"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+)
+
+// replayedHeaders are the response headers stored alongside the body.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// ErrIdempotencyKeyTaken is returned by IdempotencyStore.Claim when the key
+// is held by a live entry.
+var ErrIdempotencyKeyTaken = errors.New("idempotency key taken")
+
+// IdempotentResponse is a response recorded for replay.
+type IdempotentResponse struct {
+	Status int
+	Header map[string]string
+	Body   []byte
+}
+
+// IdempotencyEntry is the live entry holding a key; Response is nil while the
+// request that claimed it is still running.
+type IdempotencyEntry struct {
+	RequestHash []byte
+	Response    *IdempotentResponse
+}
+
+type IdempotencyStore interface {
+	// Claim reserves key for a request until expiresAt. It fails with
+	// ErrIdempotencyKeyTaken and returns the current entry when the key is
+	// held by an unexpired one.
+	Claim(ctx context.Context, key string, requestHash []byte, expiresAt time.Time) (IdempotencyEntry, error)
+	// Save stores the response of a claimed key and keeps the key until
+	// expiresAt.
+	Save(ctx context.Context, key string, resp IdempotentResponse, expiresAt time.Time) error
+	// Release frees a claimed key whose response was not stored, so the
+	// request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the first completed response for requests carrying the
+// same Idempotency-Key for ttl. Keys are scoped to the route; reusing one with
+// a different body or query is rejected with 422 and a retry that overlaps
+// the original gets 409. Server errors are not stored, so they can be retried.
+//
+// A running request holds its key for lease only, so a crashed instance does
+// not block retries for the whole ttl; storing the response extends it.
+func Idempotency(store IdempotencyStore, ttl, lease time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLen {
+			abortProblem(c, http.StatusBadRequest, problems.ProblemTypeValidation,
+				problems.TitleBadRequest, problems.DetailInvalidIdempotencyKey)
+
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxJSONBodyBytes))
+
+		var tooLarge *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &tooLarge):
+			abortProblem(c, http.StatusRequestEntityTooLarge, problems.ProblemTypeTooLarge,
+				problems.TitleTooLarge, problems.DetailBodyTooLarge)
+
+			return
+		case err != nil:
+			abortProblem(c, http.StatusBadRequest, problems.ProblemTypeInvalidJSON,
+				problems.TitleBadRequest, problems.DetailInvalidJSON)
+
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scoped := c.Request.Method + " " + c.FullPath() + " " + key
+		hash := requestHash(c.Request.URL.RawQuery, body)
+
+		entry, err := store.Claim(c.Request.Context(), scoped, hash, time.Now().Add(lease))
+		switch {
+		case errors.Is(err, ErrIdempotencyKeyTaken):
+			replayIdempotent(c, entry, hash)
+
+			return
+		case err != nil:
+			log.Printf("idempotency: claim key: %v", err)
+			abortProblem(c, http.StatusInternalServerError, problems.ProblemTypeInternal,
+				problems.TitleInternalError, problems.DetailInternalError)
+
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = rec
+
+		// The key is released unless the response is stored, including when
+		// a handler panics or returns without responding; the store outlives
+		// a canceled request.
+		ctx := context.WithoutCancel(c.Request.Context())
+		saved := false
+
+		defer func() {
+			if saved {
+				return
+			}
+
+			if err := store.Release(ctx, scoped); err != nil {
+				log.Printf("idempotency: release key: %v", err)
+			}
+		}()
+
+		c.Next()
+
+		status := rec.Status()
+		if !rec.responded || status >= http.StatusInternalServerError || status == problems.StatusClientClosedRequest {
+			return
+		}
+
+		err = store.Save(ctx, scoped, IdempotentResponse{
+			Status: status,
+			Header: pickHeaders(rec.Header()),
+			Body:   rec.body.Bytes(),
+		}, time.Now().Add(ttl))
+		if err != nil {
+			log.Printf("idempotency: save response: %v", err)
+
+			return
+		}
+
+		saved = true
+	}
+}
+
+func replayIdempotent(c *gin.Context, entry IdempotencyEntry, hash []byte) {
+	if !bytes.Equal(entry.RequestHash, hash) {
+		abortProblem(c, http.StatusUnprocessableEntity, problems.ProblemTypeIdempotency,
+			problems.TitleUnprocessable, problems.DetailIdempotencyMismatch)
+
+		return
+	}
+
+	if entry.Response == nil {
+		abortProblem(c, http.StatusConflict, problems.ProblemTypeConflict,
+			problems.TitleConflict, problems.DetailIdempotencyInFlight)
+
+		return
+	}
+
+	for name, value := range entry.Response.Header {
+		c.Header(name, value)
+	}
+
+	c.Header(HeaderIdempotentReplayed, "true")
+	c.Status(entry.Response.Status)
+	_, _ = c.Writer.Write(entry.Response.Body)
+	c.Abort()
+}
+
+// requestHash fingerprints what a retry must repeat: the query (bulk filters)
+// and the body.
+func requestHash(query string, body []byte) []byte {
+	h := sha256.New()
+	_, _ = io.WriteString(h, query)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(body)
+
+	return h.Sum(nil)
+}
+
+func pickHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(replayedHeaders))
+
+	for _, name := range replayedHeaders {
+		if v := h.Get(name); v != "" {
+			out[name] = v
+		}
+	}
+
+	return out
+}
+
+func abortProblem(c *gin.Context, status int, typ, title, detail string) {
+	problems.WriteProblem(c, problems.Problem{
+		Type:   typ,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	})
+	c.Abort()
+}
+
+// recordingWriter keeps a copy of the response body and notes whether the
+// handler set a status or wrote anything.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	responded bool
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	w.responded = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.responded = true
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.responded = true
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,233 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"code/internal/adapters/httpapi/middleware"
+)
+
+type memIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]middleware.IdempotencyEntry
+	// expires holds the latest expiry passed for each key.
+	expires map[string]time.Time
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{
+		entries: make(map[string]middleware.IdempotencyEntry),
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (s *memIdempotencyStore) Claim(
+	_ context.Context,
+	key string,
+	requestHash []byte,
+	expiresAt time.Time,
+) (middleware.IdempotencyEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		return entry, middleware.ErrIdempotencyKeyTaken
+	}
+
+	s.entries[key] = middleware.IdempotencyEntry{RequestHash: requestHash}
+	s.expires[key] = expiresAt
+
+	return middleware.IdempotencyEntry{}, nil
+}
+
+func (s *memIdempotencyStore) Save(
+	_ context.Context,
+	key string,
+	resp middleware.IdempotentResponse,
+	expiresAt time.Time,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	entry.Response = &resp
+	s.entries[key] = entry
+	s.expires[key] = expiresAt
+
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	delete(s.expires, key)
+
+	return nil
+}
+
+func newIdempotencyRouter(store middleware.IdempotencyStore, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/links", middleware.Idempotency(store, time.Hour, time.Minute), handler)
+
+	return r
+}
+
+func postWithKey(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	if key != "" {
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	r := newIdempotencyRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		calls++
+
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+
+		c.Header("Location", "/api/links/"+strconv.Itoa(calls))
+		c.Data(http.StatusCreated, "application/json", body)
+	})
+
+	first := postWithKey(r, "k1", `{"original_url":"https://example.com"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(middleware.HeaderIdempotentReplayed))
+
+	second := postWithKey(r, "k1", `{"original_url":"https://example.com"}`)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, "true", second.Header().Get(middleware.HeaderIdempotentReplayed))
+	require.Equal(t, first.Header().Get("Location"), second.Header().Get("Location"))
+	require.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Equal(t, 1, calls)
+
+	postWithKey(r, "", `{"original_url":"https://example.com"}`)
+	postWithKey(r, "k2", `{"original_url":"https://example.com"}`)
+	require.Equal(t, 3, calls)
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	r := newIdempotencyRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	require.Equal(t, http.StatusCreated, postWithKey(r, "k1", `{"a":1}`).Code)
+
+	rec := postWithKey(r, "k1", `{"a":2}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+}
+
+func TestIdempotency_ConflictWhileInFlight(t *testing.T) {
+	store := newMemIdempotencyStore()
+	r := newIdempotencyRouter(store, func(c *gin.Context) {
+		rec := postWithKey(nestedRouter(store), "k1", `{}`)
+		require.Equal(t, http.StatusConflict, rec.Code)
+
+		c.Status(http.StatusCreated)
+	})
+
+	require.Equal(t, http.StatusCreated, postWithKey(r, "k1", `{}`).Code)
+}
+
+// nestedRouter shares the store and route of the outer router, so a request
+// sent from inside the handler overlaps the one being served.
+func nestedRouter(store middleware.IdempotencyStore) *gin.Engine {
+	return newIdempotencyRouter(store, func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+}
+
+func TestIdempotency_ReleasesKeyOnServerError(t *testing.T) {
+	status := http.StatusInternalServerError
+	r := newIdempotencyRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		c.Status(status)
+	})
+
+	require.Equal(t, http.StatusInternalServerError, postWithKey(r, "k1", `{}`).Code)
+
+	status = http.StatusCreated
+	rec := postWithKey(r, "k1", `{}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+}
+
+func TestIdempotency_ReleasesKeyWithoutResponse(t *testing.T) {
+	calls := 0
+	r := newIdempotencyRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		calls++
+		if calls > 1 {
+			c.Status(http.StatusCreated)
+		}
+	})
+
+	postWithKey(r, "k1", `{}`)
+
+	rec := postWithKey(r, "k1", `{}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotency_LeaseExtendedOnSave(t *testing.T) {
+	store := newMemIdempotencyStore()
+
+	var leased time.Time
+	r := newIdempotencyRouter(store, func(c *gin.Context) {
+		store.mu.Lock()
+		leased = store.expires["POST /links k1"]
+		store.mu.Unlock()
+
+		c.Status(http.StatusCreated)
+	})
+
+	start := time.Now()
+	require.Equal(t, http.StatusCreated, postWithKey(r, "k1", `{}`).Code)
+
+	require.WithinDuration(t, start.Add(time.Minute), leased, 5*time.Second)
+	require.WithinDuration(t, start.Add(time.Hour), store.expires["POST /links k1"], 5*time.Second)
+}
+
+func TestIdempotency_RejectsLargeBody(t *testing.T) {
+	store := newMemIdempotencyStore()
+	r := newIdempotencyRouter(store, func(c *gin.Context) {
+		t.Fatal("handler called for an oversized body")
+	})
+
+	rec := postWithKey(r, "k1", `"`+strings.Repeat("a", middleware.MaxJSONBodyBytes)+`"`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	require.Empty(t, store.entries)
+}
+
+func TestIdempotency_RejectsLongKey(t *testing.T) {
+	r := newIdempotencyRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	rec := postWithKey(r, strings.Repeat("k", 256), `{}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -12,6 +12,9 @@ import (
 
 const requestIDHeader = "X-Request-ID"
 
+// RequestIDKey is the gin context key holding the current request ID.
+const RequestIDKey = "request_id"
+
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := strings.TrimSpace(c.GetHeader(requestIDHeader))
@@ -20,7 +23,7 @@ func RequestID() gin.HandlerFunc {
 		}
 
 		c.Header(requestIDHeader, id)
-		c.Set("request_id", id)
+		c.Set(RequestIDKey, id)
 
 		c.Next()
 	}
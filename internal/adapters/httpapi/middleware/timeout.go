@@ -7,11 +7,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RequestTimeout(d time.Duration) gin.HandlerFunc {
+// RouteTimeout overrides the request budget for a registered route pattern.
+type RouteTimeout struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func RequestTimeout(d time.Duration, overrides ...RouteTimeout) gin.HandlerFunc {
 	if d <= 0 {
 		return func(c *gin.Context) { c.Next() }
 	}
 
+	byPath := make(map[string]time.Duration, len(overrides))
+	for _, o := range overrides {
+		byPath[o.Path] = o.Timeout
+	}
+
 	return func(c *gin.Context) {
 		if _, ok := c.Request.Context().Deadline(); ok {
 			c.Next()
@@ -19,7 +30,18 @@ func RequestTimeout(d time.Duration) gin.HandlerFunc {
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		budget := d
+		if o, ok := byPath[c.FullPath()]; ok {
+			budget = o
+		}
+
+		if budget <= 0 {
+			c.Next()
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), budget)
 		defer cancel()
 
 		c.Request = c.Request.WithContext(ctx)
@@ -29,7 +29,7 @@ type slowRepo struct {
 	errCh chan error
 }
 
-func (r slowRepo) ListAll(ctx context.Context, _ links.Sort) ([]domain.Link, error) {
+func (r slowRepo) ListAll(ctx context.Context, _ links.LinksFilter, _ links.Sort) ([]domain.Link, error) {
 	_, err := r.db.ExecContext(ctx, "SELECT pg_sleep($1)", 0.2)
 	select {
 	case r.errCh <- err:
@@ -41,36 +41,65 @@ func (r slowRepo) ListAll(ctx context.Context, _ links.Sort) ([]domain.Link, err
 	return nil, nil
 }
 
-func (slowRepo) ListPage(ctx context.Context, _, _ int32, _ links.Sort) ([]domain.Link, error) {
+func (slowRepo) ListPage(ctx context.Context, _ links.LinksFilter, _, _ int32, _ links.Sort) ([]domain.Link, error) {
 	<-ctx.Done()
 	return nil, ctx.Err()
 }
 
-func (slowRepo) Count(ctx context.Context) (int64, error) {
+func (slowRepo) Count(ctx context.Context, _ links.LinksFilter) (int64, error) {
 	<-ctx.Done()
 	return 0, ctx.Err()
 }
 
+func (slowRepo) LockMatching(_ context.Context, _ links.LinksFilter) ([]int64, error) {
+	return nil, nil
+}
+
+func (slowRepo) StreamAll(ctx context.Context, _ links.LinksFilter, _ links.Sort, _ func(domain.Link) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
 func (slowRepo) GetByID(_ context.Context, _ int64) (domain.Link, error) {
 	return domain.Link{}, domain.ErrNotFound
 }
 
-func (slowRepo) GetByShortName(_ context.Context, _ string) (domain.Link, error) {
+func (slowRepo) GetByShortName(_ context.Context, _, _ string) (domain.Link, error) {
 	return domain.Link{}, domain.ErrNotFound
 }
 
-func (slowRepo) Create(_ context.Context, _, _ string) (domain.Link, error) {
+func (slowRepo) GetByShortNameFold(_ context.Context, _, _ string) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
+func (slowRepo) GetByNormalizedURL(_ context.Context, _, _ string) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
+func (slowRepo) Flag(_ context.Context, _ int64, _ string) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
+func (slowRepo) ListFlagged(_ context.Context) ([]domain.Link, error) {
+	return nil, nil
+}
+
+func (slowRepo) Create(_ context.Context, _ domain.Link) (domain.Link, error) {
 	return domain.Link{}, domain.ErrShortNameConflict
 }
 
-func (slowRepo) Update(_ context.Context, _ int64, _, _ string) (domain.Link, error) {
+func (slowRepo) Update(_ context.Context, _ domain.Link, _ int64) (domain.Link, error) {
 	return domain.Link{}, domain.ErrNotFound
 }
 
-func (slowRepo) Delete(_ context.Context, _ int64) error {
+func (slowRepo) Delete(_ context.Context, _, _ int64) error {
 	return domain.ErrNotFound
 }
 
+func (slowRepo) GetByIDForUpdate(_ context.Context, _ int64) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
 func TestAPI_RequestTimeout_CancelsDBQuery(t *testing.T) {
 	ctx := context.Background()
 
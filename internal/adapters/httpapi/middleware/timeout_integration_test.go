@@ -20,41 +20,70 @@ import (
 
 type timeoutRepo struct{}
 
-func (timeoutRepo) ListAll(ctx context.Context, _ links.Sort) ([]domain.Link, error) {
+func (timeoutRepo) ListAll(ctx context.Context, _ links.LinksFilter, _ links.Sort) ([]domain.Link, error) {
 	<-ctx.Done()
 	return nil, ctx.Err()
 }
 
-func (timeoutRepo) ListPage(ctx context.Context, _, _ int32, _ links.Sort) ([]domain.Link, error) {
+func (timeoutRepo) ListPage(ctx context.Context, _ links.LinksFilter, _, _ int32, _ links.Sort) ([]domain.Link, error) {
 	<-ctx.Done()
 	return nil, ctx.Err()
 }
 
-func (timeoutRepo) Count(ctx context.Context) (int64, error) {
+func (timeoutRepo) Count(ctx context.Context, _ links.LinksFilter) (int64, error) {
 	<-ctx.Done()
 	return 0, ctx.Err()
 }
 
+func (timeoutRepo) LockMatching(_ context.Context, _ links.LinksFilter) ([]int64, error) {
+	return nil, nil
+}
+
+func (timeoutRepo) StreamAll(ctx context.Context, _ links.LinksFilter, _ links.Sort, _ func(domain.Link) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
 func (timeoutRepo) GetByID(_ context.Context, _ int64) (domain.Link, error) {
 	return domain.Link{}, domain.ErrNotFound
 }
 
-func (timeoutRepo) GetByShortName(_ context.Context, _ string) (domain.Link, error) {
+func (timeoutRepo) GetByShortName(_ context.Context, _, _ string) (domain.Link, error) {
 	return domain.Link{}, domain.ErrNotFound
 }
 
-func (timeoutRepo) Create(_ context.Context, _, _ string) (domain.Link, error) {
+func (timeoutRepo) GetByShortNameFold(_ context.Context, _, _ string) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
+func (timeoutRepo) GetByNormalizedURL(_ context.Context, _, _ string) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
+func (timeoutRepo) Flag(_ context.Context, _ int64, _ string) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
+func (timeoutRepo) ListFlagged(_ context.Context) ([]domain.Link, error) {
+	return nil, nil
+}
+
+func (timeoutRepo) Create(_ context.Context, _ domain.Link) (domain.Link, error) {
 	return domain.Link{}, domain.ErrShortNameConflict
 }
 
-func (timeoutRepo) Update(_ context.Context, _ int64, _, _ string) (domain.Link, error) {
+func (timeoutRepo) Update(_ context.Context, _ domain.Link, _ int64) (domain.Link, error) {
 	return domain.Link{}, domain.ErrNotFound
 }
 
-func (timeoutRepo) Delete(_ context.Context, _ int64) error {
+func (timeoutRepo) Delete(_ context.Context, _, _ int64) error {
 	return domain.ErrNotFound
 }
 
+func (timeoutRepo) GetByIDForUpdate(_ context.Context, _ int64) (domain.Link, error) {
+	return domain.Link{}, domain.ErrNotFound
+}
+
 func TestAPI_RequestTimeout(t *testing.T) {
 	svc := links.New(timeoutRepo{}, nil, nil)
 	router := httpapi.NewEngine(
@@ -4,32 +4,73 @@ const (
 	ContentTypeProblemJSON    = "application/problem+json"
 	StatusClientClosedRequest = 499
 
-	ProblemTypeValidation  = "validation_error"
-	ProblemTypeInvalidJSON = "invalid_json"
-	ProblemTypeNotFound    = "about:blank"
-	ProblemTypeConflict    = "conflict"
-	ProblemTypeTimeout     = "timeout"
-	ProblemTypeInternal    = "internal_error"
-	ProblemTypeCanceled    = "client_cancelled"
+	ProblemTypeValidation    = "validation_error"
+	ProblemTypeInvalidJSON   = "invalid_json"
+	ProblemTypeNotFound      = "about:blank"
+	ProblemTypeConflict      = "conflict"
+	ProblemTypeTimeout       = "timeout"
+	ProblemTypeInternal      = "internal_error"
+	ProblemTypeCanceled      = "client_cancelled"
+	ProblemTypeQuota         = "quota_exceeded"
+	ProblemTypePrecondition  = "precondition_failed"
+	ProblemTypeMediaType     = "unsupported_media_type"
+	ProblemTypeNotAcceptable = "not_acceptable"
+	ProblemTypeGone          = "gone"
+	ProblemTypeIdempotency   = "idempotency_key_reused"
+	ProblemTypeTooLarge      = "payload_too_large"
 
 	TitleBadRequest      = "Bad Request"
 	TitleValidation      = "Validation error"
 	TitleConflict        = "Conflict"
+	TitleForbidden       = "Forbidden"
+	TitlePrecondition    = "Precondition Failed"
+	TitleMediaType       = "Unsupported Media Type"
+	TitleNotAcceptable   = "Not Acceptable"
+	TitleGone            = "Gone"
+	TitleUnprocessable   = "Unprocessable Entity"
 	TitleNotFound        = "Not Found"
 	TitleGatewayTimeout  = "Gateway Timeout"
 	TitleRequestTimeout  = "Request Timeout"
 	TitleRequestCanceled = "Request Canceled"
 	TitleInternalError   = "Internal Server Error"
+	TitleTooLarge        = "Payload Too Large"
 
 	DetailInvalidURL        = "invalid url"
 	DetailInvalidShortName  = "invalid short_name"
 	DetailInvalidJSON       = "invalid json"
 	DetailInvalidRange      = "invalid range"
 	DetailInvalidSort       = "invalid sort"
+	DetailInvalidFilter     = "invalid filter"
 	DetailInvalidID         = "invalid id"
+	DetailInvalidRevision   = "invalid revision"
 	DetailShortNameConflict = "short_name already exists"
 	DetailNotFound          = "not found"
 	DetailTimeout           = "timeout"
 	DetailRequestCanceled   = "request canceled"
 	DetailInternalError     = "internal error"
+	DetailLinkQuota         = "link quota exceeded"
+	DetailClickQuota        = "monthly click quota exceeded"
+	DetailVersionMismatch   = "link was modified"
+	DetailInvalidIfMatch    = "invalid If-Match"
+	DetailMergePatchOnly    = "expected application/merge-patch+json"
+	DetailInvalidBatchSize  = "invalid batch size"
+	DetailInvalidBulkMode   = "invalid mode"
+	DetailInvalidConflict   = "invalid on_conflict"
+	DetailInvalidDryRun     = "invalid dry_run"
+	DetailInvalidReuse      = "invalid reuse_existing"
+	DetailInvalidImport     = "invalid import file"
+	DetailImportFormat      = "expected text/csv or application/x-ndjson"
+	DetailExportFormat      = "can only produce text/csv or application/x-ndjson"
+	DetailLinkDisabled      = "link is disabled"
+	DetailLinkNotActive     = "link is not active yet"
+	DetailEmptyBulkFilter   = "filter is required"
+	DetailInvalidBulkPatch  = "bulk patch must change original_url, tags or disabled"
+	DetailDomainConflict    = "domain already exists"
+	DetailDomainInUse       = "domain still has links"
+	DetailInvalidStatsGroup = "invalid group_by"
+	DetailBodyTooLarge      = "request body too large"
+
+	DetailInvalidIdempotencyKey = "invalid Idempotency-Key"
+	DetailIdempotencyMismatch   = "Idempotency-Key was already used with a different request"
+	DetailIdempotencyInFlight   = "a request with this Idempotency-Key is still in progress"
 )
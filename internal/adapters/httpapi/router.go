@@ -3,19 +3,56 @@ package httpapi
 import (
 	"github.com/gin-gonic/gin"
 
+	"code/internal/adapters/httpapi/dto"
 	"code/internal/adapters/httpapi/handlers"
 	"code/internal/app/links"
 )
 
 const (
-	linkByIDPath   = "/links/:id"
-	linksPath      = "/links"
-	linkVisitsPath = "/link_visits"
+	linkByIDPath     = "/links/:id"
+	revisionsPath    = "/links/:id/revisions"
+	revertPath       = "/links/:id/revisions/:rev/revert"
+	linksPath        = "/links"
+	linksBulkPath    = "/links/bulk"
+	linksImportPath  = "/links/import"
+	linksExportPath  = "/links/export"
+	linksFlaggedPath = "/links/flagged"
+	linkVisitsPath   = "/link_visits"
+	visitsExportPath = "/link_visits/export"
+	visitsStatsPath  = "/link_visits/stats"
+	usagePath        = "/usage"
+	auditPath        = "/audit"
+	domainsPath      = "/domains"
+	domainByIDPath   = "/domains/:id"
 )
 
+const apiPrefix = "/api"
+
+const (
+	redirectPath = "/r/:code"
+	// restPath catches the path after the short name, for links that
+	// forward it.
+	restPath = "/*rest"
+)
+
+// TransferRoutes lists the full paths of long-running import and export
+// routes, which get their own request budget.
+func TransferRoutes() []string {
+	return []string{
+		apiPrefix + linksImportPath,
+		apiPrefix + linksExportPath,
+		apiPrefix + visitsExportPath,
+	}
+}
+
 type RouterDeps struct {
 	Links   links.UseCase
 	BaseURL string
+	// RootRedirects also serves short links at /:code; /r/:code keeps
+	// working for the URLs already handed out.
+	RootRedirects bool
+	// Idempotency, when set, guards the link-creating and bulk routes.
+	Idempotency gin.HandlerFunc
 }
 
 type EnginePlugin func(*gin.Engine)
@@ -34,20 +71,48 @@ func NewEngine(plugins ...EnginePlugin) *gin.Engine {
 
 // RegisterRoutes attaches routes/handlers to an existing engine.
 func RegisterRoutes(r *gin.Engine, deps RouterDeps) {
-	h := handlers.New(deps.Links, deps.BaseURL)
+	h := handlers.New(deps.Links, dto.ShortURLs{BaseURL: deps.BaseURL, Root: deps.RootRedirects})
+
+	idem := deps.Idempotency
+	if idem == nil {
+		idem = func(c *gin.Context) { c.Next() }
+	}
 
 	r.NoRoute(h.NotFound)
 	r.GET("/ping", h.Ping)
 
-	api := r.Group("/api")
+	api := r.Group(apiPrefix, handlers.AuditMeta())
 	{
 		api.GET(linksPath, h.ListLinks)
-		api.POST(linksPath, h.CreateLink)
+		api.POST(linksPath, idem, h.CreateLink)
+		api.PATCH(linksPath, idem, h.BulkPatchLinks)
+		api.DELETE(linksPath, idem, h.BulkDeleteLinks)
+		api.POST(linksBulkPath, idem, h.BulkCreateLinks)
+		api.POST(linksImportPath, h.ImportLinks)
+		api.GET(linksExportPath, h.ExportLinks)
+		api.GET(linksFlaggedPath, h.ListFlaggedLinks)
 		api.GET(linkByIDPath, h.GetLink)
 		api.PUT(linkByIDPath, h.UpdateLink)
+		api.PATCH(linkByIDPath, h.PatchLink)
 		api.DELETE(linkByIDPath, h.DeleteLink)
+		api.GET(revisionsPath, h.ListLinkRevisions)
+		api.POST(revertPath, h.RevertLink)
 		api.GET(linkVisitsPath, h.ListLinkVisits)
+		api.GET(visitsExportPath, h.ExportLinkVisits)
+		api.GET(visitsStatsPath, h.VisitStats)
+		api.GET(usagePath, h.GetUsage)
+		api.GET(auditPath, h.ListAuditEvents)
+		api.GET(domainsPath, h.ListDomains)
+		api.POST(domainsPath, h.CreateDomain)
+		api.GET(domainByIDPath, h.GetDomain)
+		api.DELETE(domainByIDPath, h.DeleteDomain)
 	}
 
-	r.GET("/r/:code", h.Redirect)
+	r.GET(redirectPath, h.Redirect)
+	r.GET(redirectPath+restPath, h.Redirect)
+
+	if deps.RootRedirects {
+		r.GET("/:code", h.RootRedirect)
+		r.GET("/:code"+restPath, h.RootRedirect)
+	}
 }
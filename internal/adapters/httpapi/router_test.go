@@ -0,0 +1,53 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	httpapi "code/internal/adapters/httpapi"
+	"code/internal/domain"
+)
+
+func TestRouteRootsAreReservedShortNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := httpapi.NewEngine()
+	httpapi.RegisterRoutes(r, httpapi.RouterDeps{RootRedirects: true})
+
+	for _, route := range r.Routes() {
+		root, _, _ := strings.Cut(strings.TrimPrefix(route.Path, "/"), "/")
+		if root == ":code" {
+			continue
+		}
+
+		require.True(t, domain.IsReservedShortName(root), "route %s", route.Path)
+	}
+}
+
+func TestRootRedirectsKeepServiceRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := httpapi.NewEngine()
+	httpapi.RegisterRoutes(r, httpapi.RouterDeps{RootRedirects: true})
+
+	cases := map[string]int{
+		"/ping":        http.StatusOK,
+		"/api":         http.StatusNotFound,
+		"/assets":      http.StatusNotFound,
+		"/Admin":       http.StatusNotFound,
+		"/api/nope":    http.StatusNotFound,
+		"/assets/x.js": http.StatusNotFound,
+	}
+
+	for path, status := range cases {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, status, rec.Code, path)
+	}
+}
@@ -30,10 +30,10 @@ func Sentry(timeout time.Duration) func(*gin.Engine) {
 	}
 }
 
-func RequestTimeout(d time.Duration) func(*gin.Engine) {
+func RequestTimeout(d time.Duration, overrides ...middleware.RouteTimeout) func(*gin.Engine) {
 	return func(r *gin.Engine) {
 		if d > 0 {
-			r.Use(middleware.RequestTimeout(d))
+			r.Use(middleware.RequestTimeout(d, overrides...))
 		}
 	}
 }
@@ -0,0 +1,68 @@
+// Package linkio reads and writes links in the CSV and NDJSON interchange
+// formats used by import and export.
+package linkio
+
+import (
+	"errors"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+
+	ContentTypeCSV    = "text/csv"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// FormatFromContentType maps a media type (parameters are ignored) to a format.
+func FormatFromContentType(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", ErrUnsupportedFormat
+	}
+
+	switch mediaType {
+	case ContentTypeCSV:
+		return FormatCSV, nil
+	case ContentTypeNDJSON, "application/ndjson", "application/jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// FormatFromPath infers the format from a file extension.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatNDJSON {
+		return ContentTypeNDJSON
+	}
+
+	return ContentTypeCSV
+}
+
+// Ext is the file extension, with the dot, used for downloads.
+func (f Format) Ext() string {
+	if f == FormatNDJSON {
+		return ".ndjson"
+	}
+
+	return ".csv"
+}
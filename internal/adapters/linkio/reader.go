@@ -0,0 +1,404 @@
+package linkio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+const (
+	colOriginalURL = "original_url"
+	colShortName   = "short_name"
+	colTags        = "tags"
+	colDisabled    = "disabled"
+	colDomain      = "domain"
+	colTitle       = "title"
+	colForwardPath = "forward_path"
+	colForwardQry  = "forward_query"
+	colUTMSource   = "utm_source"
+	colUTMMedium   = "utm_medium"
+	colUTMCampaign = "utm_campaign"
+	colUTMTerm     = "utm_term"
+	colUTMContent  = "utm_content"
+	colRules       = "rules"
+	colVariants    = "variants"
+	colSticky      = "sticky_variant"
+	colActiveFrom  = "active_from"
+
+	maxNDJSONLine = 1 << 20
+)
+
+var (
+	ErrMissingURLColumn = errors.New("csv header has no original_url column")
+	ErrMalformedRow     = errors.New("malformed row")
+	ErrLineTooLong      = errors.New("line too long")
+)
+
+// Header aliases used by exports of other shorteners.
+var columnAliases = map[string]string{
+	"url":         colOriginalURL,
+	"long_url":    colOriginalURL,
+	"destination": colOriginalURL,
+	"slug":        colShortName,
+	"code":        colShortName,
+	"short_code":  colShortName,
+	"back_half":   colShortName,
+}
+
+// NewReader returns an import source for r in the given format.
+func NewReader(r io.Reader, format Format) (links.ImportSource, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// csvReader reads rows with a header line; unknown columns are ignored.
+type csvReader struct {
+	r    *csv.Reader
+	cols map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrMissingURLColumn
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if alias, ok := columnAliases[name]; ok {
+			name = alias
+		}
+
+		if _, dup := cols[name]; !dup {
+			cols[name] = i
+		}
+	}
+
+	if _, ok := cols[colOriginalURL]; !ok {
+		return nil, ErrMissingURLColumn
+	}
+
+	return &csvReader{r: cr, cols: cols}, nil
+}
+
+func (c *csvReader) Next() (links.ImportRecord, error) {
+	for {
+		row, err := c.r.Read()
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return links.ImportRecord{Line: parseErr.StartLine, Err: ErrMalformedRow}, nil
+		}
+
+		if err != nil {
+			return links.ImportRecord{}, err
+		}
+
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+
+		line, _ := c.r.FieldPos(0)
+
+		disabled, err := parseBool(c.field(row, colDisabled))
+		if err != nil {
+			return links.ImportRecord{Line: line, Err: ErrMalformedRow}, nil
+		}
+
+		forwardPath, err := parseBool(c.field(row, colForwardPath))
+		if err != nil {
+			return links.ImportRecord{Line: line, Err: ErrMalformedRow}, nil
+		}
+
+		rules, err := parseRules(c.field(row, colRules))
+		if err != nil {
+			return links.ImportRecord{Line: line, Err: ErrMalformedRow}, nil
+		}
+
+		variants, err := parseVariants(c.field(row, colVariants))
+		if err != nil {
+			return links.ImportRecord{Line: line, Err: ErrMalformedRow}, nil
+		}
+
+		sticky, err := parseBool(c.field(row, colSticky))
+		if err != nil {
+			return links.ImportRecord{Line: line, Err: ErrMalformedRow}, nil
+		}
+
+		activeFrom, err := parseTime(c.field(row, colActiveFrom))
+		if err != nil {
+			return links.ImportRecord{Line: line, Err: ErrMalformedRow}, nil
+		}
+
+		return links.ImportRecord{
+			Line: line,
+			Input: links.LinkInput{
+				OriginalURL:  c.field(row, colOriginalURL),
+				ShortName:    c.field(row, colShortName),
+				Tags:         splitTags(c.field(row, colTags)),
+				Disabled:     disabled,
+				Domain:       c.field(row, colDomain),
+				Title:        c.field(row, colTitle),
+				ForwardPath:  forwardPath,
+				ForwardQuery: c.field(row, colForwardQry),
+				UTM: domain.UTM{
+					Source:   c.field(row, colUTMSource),
+					Medium:   c.field(row, colUTMMedium),
+					Campaign: c.field(row, colUTMCampaign),
+					Term:     c.field(row, colUTMTerm),
+					Content:  c.field(row, colUTMContent),
+				},
+				Rules:         rules,
+				Variants:      variants,
+				StickyVariant: sticky,
+				ActiveFrom:    activeFrom,
+			},
+		}, nil
+	}
+}
+
+func (c *csvReader) field(row []string, name string) string {
+	i, ok := c.cols[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+
+	return row[i]
+}
+
+// parseBool reads an empty field as false.
+func parseBool(raw string) (bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(raw)
+}
+
+// parseTime reads an RFC 3339 time; empty means none.
+func parseTime(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// parseRules reads the JSON array of a CSV rules field; empty means none.
+func parseRules(raw string) ([]domain.RedirectRule, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var rows []ndjsonRule
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, err
+	}
+
+	return domainRules(rows), nil
+}
+
+// parseVariants reads the JSON array of a CSV variants field; empty means
+// none.
+func parseVariants(raw string) ([]domain.Variant, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var rows []ndjsonVariant
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, err
+	}
+
+	return domainVariants(rows), nil
+}
+
+// splitTags accepts tags separated by commas, semicolons or pipes.
+func splitTags(raw string) []string {
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	})
+}
+
+type ndjsonReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	return &ndjsonReader{sc: sc}
+}
+
+// ndjsonRow accepts tags either as an array or as a delimited string.
+type ndjsonRow struct {
+	OriginalURL   string          `json:"original_url"`
+	ShortName     string          `json:"short_name"`
+	Tags          json.RawMessage `json:"tags"`
+	Disabled      bool            `json:"disabled"`
+	Domain        string          `json:"domain"`
+	Title         string          `json:"title"`
+	ForwardPath   bool            `json:"forward_path"`
+	ForwardQuery  string          `json:"forward_query"`
+	UTM           ndjsonUTM       `json:"utm"`
+	Rules         []ndjsonRule    `json:"rules"`
+	Variants      []ndjsonVariant `json:"variants"`
+	StickyVariant bool            `json:"sticky_variant"`
+	ActiveFrom    *time.Time      `json:"active_from"`
+}
+
+// ndjsonUTM matches the utm object of link exports.
+type ndjsonUTM struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium"`
+	Campaign string `json:"campaign"`
+	Term     string `json:"term"`
+	Content  string `json:"content"`
+}
+
+// ndjsonRule matches the rule objects of link exports.
+type ndjsonRule struct {
+	OS      string    `json:"os,omitempty"`
+	Device  string    `json:"device,omitempty"`
+	Country string    `json:"country,omitempty"`
+	After   time.Time `json:"after,omitzero"`
+	Before  time.Time `json:"before,omitzero"`
+	URL     string    `json:"url"`
+}
+
+func domainRules(rows []ndjsonRule) []domain.RedirectRule {
+	if rows == nil {
+		return nil
+	}
+
+	rules := make([]domain.RedirectRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, domain.RedirectRule(row))
+	}
+
+	return rules
+}
+
+// ndjsonVariant matches the variant objects of link exports.
+type ndjsonVariant struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+func domainVariants(rows []ndjsonVariant) []domain.Variant {
+	if rows == nil {
+		return nil
+	}
+
+	variants := make([]domain.Variant, 0, len(rows))
+	for _, row := range rows {
+		variants = append(variants, domain.Variant(row))
+	}
+
+	return variants
+}
+
+func (n *ndjsonReader) Next() (links.ImportRecord, error) {
+	for n.sc.Scan() {
+		n.line++
+
+		raw := bytes.TrimSpace(n.sc.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var row ndjsonRow
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return links.ImportRecord{Line: n.line, Err: ErrMalformedRow}, nil
+		}
+
+		tags, err := decodeTags(row.Tags)
+		if err != nil {
+			return links.ImportRecord{Line: n.line, Err: ErrMalformedRow}, nil
+		}
+
+		return links.ImportRecord{
+			Line: n.line,
+			Input: links.LinkInput{
+				OriginalURL:   row.OriginalURL,
+				ShortName:     row.ShortName,
+				Tags:          tags,
+				Disabled:      row.Disabled,
+				Domain:        row.Domain,
+				Title:         row.Title,
+				ForwardPath:   row.ForwardPath,
+				ForwardQuery:  row.ForwardQuery,
+				UTM:           domain.UTM(row.UTM),
+				Rules:         domainRules(row.Rules),
+				Variants:      domainVariants(row.Variants),
+				StickyVariant: row.StickyVariant,
+				ActiveFrom:    row.ActiveFrom,
+			},
+		}, nil
+	}
+
+	if err := n.sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return links.ImportRecord{}, ErrLineTooLong
+		}
+
+		return links.ImportRecord{}, err
+	}
+
+	return links.ImportRecord{}, io.EOF
+}
+
+func decodeTags(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+
+	return splitTags(s), nil
+}
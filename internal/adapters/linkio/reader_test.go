@@ -0,0 +1,147 @@
+package linkio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/adapters/linkio"
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+func readAll(t *testing.T, src links.ImportSource) []links.ImportRecord {
+	t.Helper()
+
+	var out []links.ImportRecord
+	for {
+		rec, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+
+		require.NoError(t, err)
+		out = append(out, rec)
+	}
+}
+
+func TestReader_CSV(t *testing.T) {
+	in := "Long_URL,slug,tags,clicks,title,forward_path,forward_query,utm_source,utm_campaign,rules,disabled\n" +
+		"https://example.com/a,abc,promo|q4,10,Q4 promo,true,preserve,news,q4," +
+		`"[{""device"":""mobile"",""url"":""https://m.example.com""}]",true` + "\n" +
+		"\n" +
+		"https://example.com/b,,,3\n" +
+		"https://example.com/d,,,,,maybe,\n" +
+		"\"https://example.com/c,oops\n"
+
+	src, err := linkio.NewReader(strings.NewReader(in), linkio.FormatCSV)
+	require.NoError(t, err)
+
+	recs := readAll(t, src)
+	require.Len(t, recs, 4)
+	require.Equal(t, 2, recs[0].Line)
+	require.Equal(t, links.LinkInput{
+		OriginalURL:  "https://example.com/a",
+		ShortName:    "abc",
+		Tags:         []string{"promo", "q4"},
+		Disabled:     true,
+		Title:        "Q4 promo",
+		ForwardPath:  true,
+		ForwardQuery: "preserve",
+		UTM:          domain.UTM{Source: "news", Campaign: "q4"},
+		Rules:        []domain.RedirectRule{{Device: "mobile", URL: "https://m.example.com"}},
+	}, recs[0].Input)
+	require.Equal(t, 4, recs[1].Line)
+	require.Empty(t, recs[1].Input.ShortName)
+	require.False(t, recs[1].Input.ForwardPath)
+	require.False(t, recs[1].Input.Disabled)
+	require.ErrorIs(t, recs[2].Err, linkio.ErrMalformedRow)
+	require.ErrorIs(t, recs[3].Err, linkio.ErrMalformedRow)
+}
+
+func TestReader_CSVMissingURLColumn(t *testing.T) {
+	_, err := linkio.NewReader(strings.NewReader("short_name\nabc\n"), linkio.FormatCSV)
+	require.ErrorIs(t, err, linkio.ErrMissingURLColumn)
+}
+
+func TestReader_NDJSON(t *testing.T) {
+	in := `{"original_url":"https://example.com/a","short_name":"abc","tags":["promo"],"disabled":true,"domain":"go.example.com","forward_path":true,"forward_query":"append","utm":{"source":"news","medium":"email"},"rules":[{"os":"ios","url":"https://example.com/ios"},{"before":"2026-11-01T00:00:00Z","url":"https://example.com/teaser"}],"variants":[{"url":"https://example.com/a","weight":70},{"url":"https://example.com/b","weight":30}],"sticky_variant":true,"active_from":"2026-10-25T09:00:00Z"}` + "\n" +
+		"\n" +
+		`{"original_url":"https://example.com/b","tags":"x;y","extra":1}` + "\n" +
+		`not json` + "\n"
+
+	src, err := linkio.NewReader(strings.NewReader(in), linkio.FormatNDJSON)
+	require.NoError(t, err)
+
+	recs := readAll(t, src)
+	require.Len(t, recs, 3)
+	require.Equal(t, []string{"promo"}, recs[0].Input.Tags)
+	require.True(t, recs[0].Input.Disabled)
+	require.Equal(t, "go.example.com", recs[0].Input.Domain)
+	require.True(t, recs[0].Input.ForwardPath)
+	require.Equal(t, "append", recs[0].Input.ForwardQuery)
+	require.Equal(t, domain.UTM{Source: "news", Medium: "email"}, recs[0].Input.UTM)
+	require.Equal(t, []domain.RedirectRule{
+		{OS: "ios", URL: "https://example.com/ios"},
+		{Before: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), URL: "https://example.com/teaser"},
+	}, recs[0].Input.Rules)
+	require.Equal(t, []domain.Variant{{URL: "https://example.com/a", Weight: 70}, {URL: "https://example.com/b", Weight: 30}},
+		recs[0].Input.Variants)
+	require.True(t, recs[0].Input.StickyVariant)
+	require.Equal(t, time.Date(2026, 10, 25, 9, 0, 0, 0, time.UTC), *recs[0].Input.ActiveFrom)
+	require.Equal(t, 3, recs[1].Line)
+	require.Equal(t, []string{"x", "y"}, recs[1].Input.Tags)
+	require.ErrorIs(t, recs[2].Err, linkio.ErrMalformedRow)
+}
+
+func TestWriteErrorReport(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := linkio.WriteErrorReport(&buf, []links.ImportRowError{{
+		Line:  3,
+		Input: links.LinkInput{OriginalURL: "bad", ShortName: "abc", Tags: []string{"a", "b"}},
+		Err:   errors.New("invalid url"),
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "line,original_url,short_name,tags,disabled,domain,title,forward_path,forward_query,"+
+		"utm_source,utm_medium,utm_campaign,utm_term,utm_content,rules,variants,sticky_variant,active_from,error\n"+
+		"3,bad,abc,a|b,false,,,false,,,,,,,,,false,,invalid url\n", buf.String())
+}
+
+func TestWriteErrorReport_RoundTrip(t *testing.T) {
+	activeFrom := time.Date(2026, 10, 25, 9, 0, 0, 0, time.UTC)
+	in := links.LinkInput{
+		OriginalURL:  "https://example.com/a",
+		ShortName:    "abc",
+		Tags:         []string{"promo", "q4"},
+		Disabled:     true,
+		Domain:       "go.example.com",
+		Title:        "Q4, \"promo\"",
+		ForwardPath:  true,
+		ForwardQuery: "append",
+		UTM:          domain.UTM{Source: "news", Medium: "email", Campaign: "q4", Term: "t", Content: "c"},
+		Rules: []domain.RedirectRule{
+			{OS: "ios", URL: "https://example.com/ios"},
+			{Before: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), URL: "https://example.com/teaser"},
+		},
+		Variants:      []domain.Variant{{URL: "https://example.com/a", Weight: 70}, {URL: "https://example.com/b", Weight: 30}},
+		StickyVariant: true,
+		ActiveFrom:    &activeFrom,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, linkio.WriteErrorReport(&buf, []links.ImportRowError{{Line: 2, Input: in, Err: errors.New("taken")}}))
+
+	src, err := linkio.NewReader(&buf, linkio.FormatCSV)
+	require.NoError(t, err)
+
+	recs := readAll(t, src)
+	require.Len(t, recs, 1)
+	require.NoError(t, recs[0].Err)
+	require.Equal(t, in, recs[0].Input)
+}
@@ -0,0 +1,119 @@
+package linkio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+var errorReportHeader = []string{
+	"line", colOriginalURL, colShortName, colTags, colDisabled, colDomain, colTitle, colForwardPath, colForwardQry,
+	colUTMSource, colUTMMedium, colUTMCampaign, colUTMTerm, colUTMContent, colRules, colVariants, colSticky,
+	colActiveFrom, "error",
+}
+
+// WriteErrorReport writes failed import rows as CSV so they can be fixed and
+// re-imported: every column the readers know is echoed back, followed by
+// the error.
+func WriteErrorReport(w io.Writer, rows []links.ImportRowError) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(errorReportHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		in := row.Input
+
+		rules, err := rulesField(in.Rules)
+		if err != nil {
+			return err
+		}
+
+		variants, err := variantsField(in.Variants)
+		if err != nil {
+			return err
+		}
+
+		err = cw.Write([]string{
+			strconv.Itoa(row.Line),
+			in.OriginalURL,
+			in.ShortName,
+			strings.Join(in.Tags, "|"),
+			strconv.FormatBool(in.Disabled),
+			in.Domain,
+			in.Title,
+			strconv.FormatBool(in.ForwardPath),
+			in.ForwardQuery,
+			in.UTM.Source,
+			in.UTM.Medium,
+			in.UTM.Campaign,
+			in.UTM.Term,
+			in.UTM.Content,
+			rules,
+			variants,
+			strconv.FormatBool(in.StickyVariant),
+			timeField(in.ActiveFrom),
+			row.Err.Error(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// rulesField is the inverse of parseRules.
+func rulesField(rules []domain.RedirectRule) (string, error) {
+	if len(rules) == 0 {
+		return "", nil
+	}
+
+	rows := make([]ndjsonRule, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, ndjsonRule(rule))
+	}
+
+	return jsonField(rows)
+}
+
+// variantsField is the inverse of parseVariants.
+func variantsField(variants []domain.Variant) (string, error) {
+	if len(variants) == 0 {
+		return "", nil
+	}
+
+	rows := make([]ndjsonVariant, 0, len(variants))
+	for _, variant := range variants {
+		rows = append(rows, ndjsonVariant(variant))
+	}
+
+	return jsonField(rows)
+}
+
+func jsonField(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+// timeField is the inverse of parseTime.
+func timeField(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
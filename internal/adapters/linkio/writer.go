@@ -0,0 +1,83 @@
+package linkio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// Columns maps a record to CSV: Header is written once, Row per record.
+// NDJSON ignores it and encodes the record itself.
+type Columns[T any] struct {
+	Header []string
+	Row    func(T) []string
+}
+
+// Writer encodes records one at a time through a small buffer, so exports
+// of any size run in constant memory.
+type Writer[T any] struct {
+	format Format
+	cols   Columns[T]
+
+	buf *bufio.Writer
+	csv *csv.Writer
+	enc *json.Encoder
+
+	started bool
+}
+
+func NewWriter[T any](w io.Writer, format Format, cols Columns[T]) (*Writer[T], error) {
+	out := &Writer[T]{format: format, cols: cols}
+
+	switch format {
+	case FormatCSV:
+		out.csv = csv.NewWriter(w)
+	case FormatNDJSON:
+		out.buf = bufio.NewWriter(w)
+		out.enc = json.NewEncoder(out.buf)
+		out.enc.SetEscapeHTML(false)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	return out, nil
+}
+
+func (w *Writer[T]) Write(v T) error {
+	if w.format == FormatNDJSON {
+		return w.enc.Encode(v)
+	}
+
+	if err := w.header(); err != nil {
+		return err
+	}
+
+	return w.csv.Write(w.cols.Row(v))
+}
+
+// Flush writes out buffered records; a CSV export with no records still
+// gets its header.
+func (w *Writer[T]) Flush() error {
+	if w.format == FormatNDJSON {
+		return w.buf.Flush()
+	}
+
+	if err := w.header(); err != nil {
+		return err
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
+
+func (w *Writer[T]) header() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+
+	return w.csv.Write(w.cols.Header)
+}
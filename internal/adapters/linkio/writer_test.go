@@ -0,0 +1,61 @@
+package linkio_test
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/adapters/linkio"
+)
+
+type row struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var rowColumns = linkio.Columns[row]{
+	Header: []string{"id", "name"},
+	Row: func(r row) []string {
+		return []string{strconv.FormatInt(r.ID, 10), r.Name}
+	},
+}
+
+func TestWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := linkio.NewWriter(&buf, linkio.FormatCSV, rowColumns)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(row{ID: 1, Name: "a,b"}))
+	require.NoError(t, w.Write(row{ID: 2, Name: "c"}))
+	require.NoError(t, w.Flush())
+
+	require.Equal(t, "id,name\n1,\"a,b\"\n2,c\n", buf.String())
+}
+
+func TestWriter_CSVEmptyHasHeader(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := linkio.NewWriter(&buf, linkio.FormatCSV, rowColumns)
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+
+	require.Equal(t, "id,name\n", buf.String())
+}
+
+func TestWriter_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := linkio.NewWriter(&buf, linkio.FormatNDJSON, rowColumns)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(row{ID: 1, Name: "<a>"}))
+	require.NoError(t, w.Flush())
+
+	require.Equal(t, "{\"id\":1,\"name\":\"<a>\"}\n", buf.String())
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := linkio.NewWriter(&bytes.Buffer{}, linkio.Format("xml"), rowColumns)
+	require.ErrorIs(t, err, linkio.ErrUnsupportedFormat)
+}
@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"code/internal/adapters/postgres/sqlcgen"
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+// linkSnapshot is the JSONB shape of audit before/after states.
+type linkSnapshot struct {
+	ID            int64           `json:"id"`
+	OriginalURL   string          `json:"original_url"`
+	ShortName     string          `json:"short_name"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Tags          []string        `json:"tags,omitempty"`
+	Disabled      bool            `json:"disabled,omitempty"`
+	FlagReason    string          `json:"flag_reason,omitempty"`
+	Domain        string          `json:"domain,omitempty"`
+	Title         string          `json:"title,omitempty"`
+	ForwardPath   bool            `json:"forward_path,omitempty"`
+	ForwardQuery  string          `json:"forward_query,omitempty"`
+	UTM           utmSnapshot     `json:"utm,omitzero"`
+	Rules         []ruleRecord    `json:"rules,omitempty"`
+	Variants      []variantRecord `json:"variants,omitempty"`
+	StickyVariant bool            `json:"sticky_variant,omitempty"`
+	ActiveFrom    *time.Time      `json:"active_from,omitempty"`
+}
+
+type utmSnapshot struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+type AuditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+var _ links.AuditRepo = (*AuditRepo)(nil)
+
+func (r *AuditRepo) Create(ctx context.Context, event domain.AuditEvent) error {
+	before, err := marshalSnapshot(event.Before)
+	if err != nil {
+		return fmt.Errorf("postgres: encode audit before: %w", err)
+	}
+
+	after, err := marshalSnapshot(event.After)
+	if err != nil {
+		return fmt.Errorf("postgres: encode audit after: %w", err)
+	}
+
+	err = queries(ctx, r.db).CreateAuditEvent(ctx, sqlcgen.CreateAuditEventParams{
+		CreatedAt:  event.CreatedAt,
+		Action:     event.Action,
+		LinkID:     event.LinkID,
+		Actor:      event.Actor,
+		RequestID:  event.RequestID,
+		Ip:         event.IP,
+		BeforeLink: before,
+		AfterLink:  after,
+	})
+	if err != nil {
+		return fmt.Errorf("postgres: create audit event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepo) ListAll(ctx context.Context, filter links.AuditFilter, sort links.Sort) ([]domain.AuditEvent, error) {
+	orderBy, err := orderByAudit(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listAuditEvents(ctx, filter, orderBy, nil, nil, "list audit events")
+}
+
+func (r *AuditRepo) ListPage(
+	ctx context.Context,
+	filter links.AuditFilter,
+	offset, limit int32,
+	sort links.Sort,
+) ([]domain.AuditEvent, error) {
+	orderBy, err := orderByAudit(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listAuditEvents(ctx, filter, orderBy, &limit, &offset, "list audit events page")
+}
+
+func (r *AuditRepo) Count(ctx context.Context, filter links.AuditFilter) (int64, error) {
+	builder := sq.Select("COUNT(*)").
+		From(sqlTableAuditEvents + " " + sqlAliasAudit).
+		Where(auditWhere(filter)).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("postgres: build count audit events: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("postgres: count audit events: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *AuditRepo) listAuditEvents(
+	ctx context.Context,
+	filter links.AuditFilter,
+	orderBy string,
+	limit, offset *int32,
+	op string,
+) ([]domain.AuditEvent, error) {
+	builder := sq.Select(sqlAuditSelectCols...).
+		From(sqlTableAuditEvents + " " + sqlAliasAudit).
+		Where(auditWhere(filter)).
+		OrderBy(orderBy).
+		PlaceholderFormat(sq.Dollar)
+
+	if limit != nil {
+		builder = builder.Limit(uint64(*limit))
+	}
+
+	if offset != nil {
+		builder = builder.Offset(uint64(*offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("postgres: build %s: %w", op, err)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf(errOpFmt, op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var out []domain.AuditEvent
+	for rows.Next() {
+		var (
+			item          domain.AuditEvent
+			before, after []byte
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.CreatedAt,
+			&item.Action,
+			&item.LinkID,
+			&item.Actor,
+			&item.RequestID,
+			&item.IP,
+			&before,
+			&after,
+		); err != nil {
+			return nil, fmt.Errorf(errOpFmt, op, err)
+		}
+
+		if item.Before, err = unmarshalSnapshot(before); err != nil {
+			return nil, fmt.Errorf(errOpFmt, op, err)
+		}
+
+		if item.After, err = unmarshalSnapshot(after); err != nil {
+			return nil, fmt.Errorf(errOpFmt, op, err)
+		}
+
+		out = append(out, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(errOpFmt, op, err)
+	}
+
+	return out, nil
+}
+
+func auditWhere(filter links.AuditFilter) sq.And {
+	where := sq.And{}
+
+	if filter.Action != "" {
+		where = append(where, sq.Eq{qualify(sqlAliasAudit, sqlColAction): filter.Action})
+	}
+
+	if filter.LinkID != 0 {
+		where = append(where, sq.Eq{qualify(sqlAliasAudit, sqlColLinkID): filter.LinkID})
+	}
+
+	if filter.Actor != "" {
+		where = append(where, sq.Eq{qualify(sqlAliasAudit, sqlColActor): filter.Actor})
+	}
+
+	if filter.RequestID != "" {
+		where = append(where, sq.Eq{qualify(sqlAliasAudit, sqlColRequestID): filter.RequestID})
+	}
+
+	return where
+}
+
+func marshalSnapshot(link *domain.Link) (json.RawMessage, error) {
+	if link == nil {
+		return json.RawMessage("null"), nil
+	}
+
+	return json.Marshal(linkSnapshot{
+		ID:            link.ID,
+		OriginalURL:   link.OriginalURL,
+		ShortName:     link.ShortName,
+		CreatedAt:     link.CreatedAt,
+		Tags:          link.Tags,
+		Disabled:      link.Disabled,
+		FlagReason:    link.FlagReason,
+		Domain:        link.Domain,
+		Title:         link.Title,
+		ForwardPath:   link.ForwardPath,
+		ForwardQuery:  link.ForwardQuery,
+		UTM:           utmSnapshot(link.UTM),
+		Rules:         ruleRecords(link.Rules),
+		Variants:      variantRecords(link.Variants),
+		StickyVariant: link.StickyVariant,
+		ActiveFrom:    link.ActiveFrom,
+	})
+}
+
+func unmarshalSnapshot(raw []byte) (*domain.Link, error) {
+	var snap *linkSnapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+
+	if snap == nil {
+		return nil, nil
+	}
+
+	return &domain.Link{
+		ID:            snap.ID,
+		OriginalURL:   snap.OriginalURL,
+		ShortName:     snap.ShortName,
+		CreatedAt:     snap.CreatedAt,
+		Tags:          snap.Tags,
+		Disabled:      snap.Disabled,
+		FlagReason:    snap.FlagReason,
+		Domain:        snap.Domain,
+		Title:         snap.Title,
+		ForwardPath:   snap.ForwardPath,
+		ForwardQuery:  snap.ForwardQuery,
+		UTM:           domain.UTM(snap.UTM),
+		Rules:         domainRules(snap.Rules),
+		Variants:      domainVariants(snap.Variants),
+		StickyVariant: snap.StickyVariant,
+		ActiveFrom:    snap.ActiveFrom,
+	}, nil
+}
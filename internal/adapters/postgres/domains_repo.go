@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"code/internal/adapters/postgres/sqlcgen"
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+type DomainsRepo struct {
+	db *sql.DB
+}
+
+func NewDomainsRepo(db *sql.DB) *DomainsRepo {
+	return &DomainsRepo{db: db}
+}
+
+var _ links.DomainsRepo = (*DomainsRepo)(nil)
+
+func (r *DomainsRepo) List(ctx context.Context) ([]domain.Domain, error) {
+	rows, err := queries(ctx, r.db).ListDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list domains: %w", err)
+	}
+
+	out := make([]domain.Domain, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, mapDomain(row))
+	}
+
+	return out, nil
+}
+
+func (r *DomainsRepo) Get(ctx context.Context, id int64) (domain.Domain, error) {
+	row, err := queries(ctx, r.db).GetDomain(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Domain{}, domain.ErrNotFound
+		}
+
+		return domain.Domain{}, fmt.Errorf("postgres: get domain: %w", err)
+	}
+
+	return mapDomain(row), nil
+}
+
+func (r *DomainsRepo) GetByHost(ctx context.Context, host string) (domain.Domain, error) {
+	row, err := queries(ctx, r.db).GetDomainByHost(ctx, host)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Domain{}, domain.ErrNotFound
+		}
+
+		return domain.Domain{}, fmt.Errorf("postgres: get domain by host: %w", err)
+	}
+
+	return mapDomain(row), nil
+}
+
+func (r *DomainsRepo) Create(ctx context.Context, host string) (domain.Domain, error) {
+	row, err := queries(ctx, r.db).CreateDomain(ctx, host)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.Domain{}, domain.ErrDomainConflict
+		}
+
+		return domain.Domain{}, fmt.Errorf("postgres: create domain: %w", err)
+	}
+
+	return mapDomain(row), nil
+}
+
+func (r *DomainsRepo) Delete(ctx context.Context, id int64) error {
+	n, err := queries(ctx, r.db).DeleteDomain(ctx, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrDomainInUse
+		}
+
+		return fmt.Errorf("postgres: delete domain: %w", err)
+	}
+
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func mapDomain(row sqlcgen.Domain) domain.Domain {
+	return domain.Domain{
+		ID:        row.ID,
+		Host:      row.Host,
+		CreatedAt: row.CreatedAt,
+	}
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"code/internal/adapters/postgres/sqlcgen"
+)
+
+// claimAttempts bounds the claim/get loop for a key that is released or
+// expires between the two statements.
+const claimAttempts = 3
+
+// IdempotencyRecord is the stored state of an idempotency key; Status is zero
+// while the request that claimed it is still running.
+type IdempotencyRecord struct {
+	RequestHash []byte
+	Status      int
+	Headers     map[string]string
+	Body        []byte
+}
+
+type IdempotencyRepo struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepo(db *sql.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+// Claim takes key for a new request, replacing an expired entry. When the
+// key is held by a live entry it returns that entry and false.
+func (r *IdempotencyRepo) Claim(
+	ctx context.Context,
+	key string,
+	requestHash []byte,
+	expiresAt time.Time,
+) (IdempotencyRecord, bool, error) {
+	q := queries(ctx, r.db)
+
+	for range claimAttempts {
+		_, err := q.ClaimIdempotencyKey(ctx, sqlcgen.ClaimIdempotencyKeyParams{
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   expiresAt,
+		})
+		if err == nil {
+			return IdempotencyRecord{}, true, nil
+		}
+
+		// The conditional upsert returns no row while the key is live.
+		if !errors.Is(err, sql.ErrNoRows) {
+			return IdempotencyRecord{}, false, fmt.Errorf("postgres: claim idempotency key: %w", err)
+		}
+
+		row, err := q.GetIdempotencyKey(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return IdempotencyRecord{}, false, fmt.Errorf("postgres: get idempotency key: %w", err)
+		}
+
+		rec, err := idempotencyRecordFromRow(row)
+		if err != nil {
+			return IdempotencyRecord{}, false, err
+		}
+
+		return rec, false, nil
+	}
+
+	return IdempotencyRecord{}, false, fmt.Errorf("postgres: claim idempotency key: %d attempts raced", claimAttempts)
+}
+
+// Complete stores the response of a claimed key and moves its expiry to
+// expiresAt.
+func (r *IdempotencyRepo) Complete(
+	ctx context.Context,
+	key string,
+	status int,
+	headers map[string]string,
+	body []byte,
+	expiresAt time.Time,
+) error {
+	rawHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("postgres: marshal idempotency headers: %w", err)
+	}
+
+	if body == nil {
+		body = []byte{}
+	}
+
+	err = queries(ctx, r.db).CompleteIdempotencyKey(ctx, sqlcgen.CompleteIdempotencyKeyParams{
+		Key:       key,
+		Status:    sql.NullInt32{Int32: int32(status), Valid: true},
+		Headers:   rawHeaders,
+		Body:      body,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("postgres: complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) Release(ctx context.Context, key string) error {
+	if err := queries(ctx, r.db).DeleteIdempotencyKey(ctx, key); err != nil {
+		return fmt.Errorf("postgres: release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes expired keys and reports how many were removed.
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	n, err := queries(ctx, r.db).DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: delete expired idempotency keys: %w", err)
+	}
+
+	return n, nil
+}
+
+func idempotencyRecordFromRow(row sqlcgen.IdempotencyKey) (IdempotencyRecord, error) {
+	rec := IdempotencyRecord{
+		RequestHash: row.RequestHash,
+		Status:      int(row.Status.Int32),
+		Body:        row.Body,
+	}
+
+	if err := json.Unmarshal(row.Headers, &rec.Headers); err != nil {
+		return IdempotencyRecord{}, fmt.Errorf("postgres: unmarshal idempotency headers: %w", err)
+	}
+
+	return rec, nil
+}
@@ -14,23 +14,25 @@ import (
 
 type LinkVisitsRepo struct {
 	db *sql.DB
-	q  *sqlcgen.Queries
 }
 
 func NewLinkVisitsRepo(db *sql.DB) *LinkVisitsRepo {
-	return &LinkVisitsRepo{db: db, q: sqlcgen.New(db)}
+	return &LinkVisitsRepo{db: db}
 }
 
 var _ links.VisitsRepo = (*LinkVisitsRepo)(nil)
 
 func (r *LinkVisitsRepo) Create(ctx context.Context, visit domain.LinkVisit) (int64, error) {
-	id, err := r.q.CreateLinkVisit(ctx, sqlcgen.CreateLinkVisitParams{
-		LinkID:    visit.LinkID,
-		CreatedAt: visit.CreatedAt,
-		Ip:        visit.IP,
-		UserAgent: visit.UserAgent,
-		Referer:   visit.Referer,
-		Status:    int32(visit.Status),
+	id, err := queries(ctx, r.db).CreateLinkVisit(ctx, sqlcgen.CreateLinkVisitParams{
+		LinkID:       visit.LinkID,
+		CreatedAt:    visit.CreatedAt,
+		Ip:           visit.IP,
+		UserAgent:    visit.UserAgent,
+		Referer:      visit.Referer,
+		Status:       int32(visit.Status),
+		Path:         visit.Path,
+		RuleIndex:    nullIndex(visit.Rule),
+		VariantIndex: nullIndex(visit.Variant),
 	})
 	if err != nil {
 		return 0, fmt.Errorf("postgres: create link visit: %w", err)
@@ -57,12 +59,44 @@ func (r *LinkVisitsRepo) ListPage(ctx context.Context, offset, limit int32, sort
 	return r.listLinkVisits(ctx, orderBy, &limit, &offset, "list link visits page")
 }
 
+func (r *LinkVisitsRepo) StreamAll(ctx context.Context, sort links.Sort, fn func(domain.LinkVisit) error) error {
+	orderBy, err := orderByLinkVisits(sort)
+	if err != nil {
+		return err
+	}
+
+	return r.eachLinkVisit(ctx, orderBy, nil, nil, "stream link visits", fn)
+}
+
 func (r *LinkVisitsRepo) listLinkVisits(
 	ctx context.Context,
 	orderBy string,
 	limit, offset *int32,
 	op string,
 ) ([]domain.LinkVisit, error) {
+	var out []domain.LinkVisit
+
+	err := r.eachLinkVisit(ctx, orderBy, limit, offset, op, func(item domain.LinkVisit) error {
+		out = append(out, item)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// eachLinkVisit scans rows one at a time; errors returned by fn are passed
+// through unwrapped.
+func (r *LinkVisitsRepo) eachLinkVisit(
+	ctx context.Context,
+	orderBy string,
+	limit, offset *int32,
+	op string,
+	fn func(domain.LinkVisit) error,
+) error {
 	builder := sq.Select(sqlVisitsSelectCols...).
 		From(sqlTableLinkVisits + " " + sqlAliasVisits).
 		OrderBy(orderBy).
@@ -78,21 +112,21 @@ func (r *LinkVisitsRepo) listLinkVisits(
 
 	query, args, err := builder.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("postgres: build %s: %w", op, err)
+		return fmt.Errorf("postgres: build %s: %w", op, err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf(errOpFmt, op, err)
+		return fmt.Errorf(errOpFmt, op, err)
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
-	var out []domain.LinkVisit
 	for rows.Next() {
 		var item domain.LinkVisit
 		var status int32
+		var rule, variant sql.NullInt32
 		if err := rows.Scan(
 			&item.ID,
 			&item.LinkID,
@@ -101,25 +135,119 @@ func (r *LinkVisitsRepo) listLinkVisits(
 			&item.UserAgent,
 			&item.Referer,
 			&status,
+			&item.Path,
+			&rule,
+			&variant,
 		); err != nil {
-			return nil, fmt.Errorf(errOpFmt, op, err)
+			return fmt.Errorf(errOpFmt, op, err)
 		}
 
 		item.Status = int(status)
-		out = append(out, item)
+		item.Rule = indexPtr(rule)
+		item.Variant = indexPtr(variant)
+
+		if err := fn(item); err != nil {
+			return err
+		}
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf(errOpFmt, op, err)
+		return fmt.Errorf(errOpFmt, op, err)
 	}
 
-	return out, nil
+	return nil
 }
 
 func (r *LinkVisitsRepo) Count(ctx context.Context) (int64, error) {
-	total, err := r.q.CountLinkVisits(ctx)
+	total, err := queries(ctx, r.db).CountLinkVisits(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("postgres: count link visits: %w", err)
 	}
 
 	return total, nil
 }
+
+func (r *LinkVisitsRepo) CountByLink(ctx context.Context, linkID int64) (int64, error) {
+	total, err := queries(ctx, r.db).CountLinkVisitsByLink(ctx, linkID)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: count link visits by link: %w", err)
+	}
+
+	return total, nil
+}
+
+func nullIndex(index *int) sql.NullInt32 {
+	if index == nil {
+		return sql.NullInt32{}
+	}
+
+	return sql.NullInt32{Int32: int32(*index), Valid: true}
+}
+
+func indexPtr(index sql.NullInt32) *int {
+	if !index.Valid {
+		return nil
+	}
+
+	i := int(index.Int32)
+
+	return &i
+}
+
+// statsGroupCols maps each stats grouping to the column it groups by.
+var statsGroupCols = map[string]string{
+	links.StatsGroupCampaign: qualify(sqlAliasLinks, sqlColUTMCampaign),
+	links.StatsGroupRule:     "COALESCE(" + qualify(sqlAliasVisits, sqlColRuleIndex) + "::text, '')",
+	links.StatsGroupVariant:  "COALESCE(" + qualify(sqlAliasVisits, sqlColVariantIndex) + "::text, '')",
+}
+
+func (r *LinkVisitsRepo) Stats(ctx context.Context, query links.VisitStatsQuery) ([]domain.VisitStat, error) {
+	const op = "link visits stats"
+
+	col, ok := statsGroupCols[query.GroupBy]
+	if !ok {
+		return nil, links.ErrInvalidStatsGroup
+	}
+
+	join := sqlTableLinks + " " + sqlAliasLinks +
+		" ON " + qualify(sqlAliasLinks, sqlColID) + " = " + qualify(sqlAliasVisits, sqlColLinkID)
+
+	builder := sq.Select(col, "COUNT(*)").
+		From(sqlTableLinkVisits+" "+sqlAliasVisits).
+		Join(join).
+		GroupBy(col).
+		OrderBy("COUNT(*) DESC", col).
+		PlaceholderFormat(sq.Dollar)
+
+	if query.LinkID != 0 {
+		builder = builder.Where(sq.Eq{qualify(sqlAliasVisits, sqlColLinkID): query.LinkID})
+	}
+
+	sqlQuery, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("postgres: build %s: %w", op, err)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf(errOpFmt, op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	stats := []domain.VisitStat{}
+	for rows.Next() {
+		var stat domain.VisitStat
+		if err := rows.Scan(&stat.Key, &stat.Visits); err != nil {
+			return nil, fmt.Errorf(errOpFmt, op, err)
+		}
+
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(errOpFmt, op, err)
+	}
+
+	return stats, nil
+}
@@ -64,3 +64,41 @@ func orderByLinkVisits(sort links.Sort) (string, error) {
 		return "", links.ErrInvalidSort
 	}
 }
+
+func orderByAudit(sort links.Sort) (string, error) {
+	ord, ok := normalizeOrder(sort.Order)
+	if !ok {
+		return "", links.ErrInvalidSort
+	}
+
+	switch sort.Field {
+	case links.SortFieldID:
+		return orderExpr(sqlAliasAudit, sqlColID, ord), nil
+	case links.SortFieldLinkID:
+		return orderExprWithTie(sqlAliasAudit, sqlColLinkID, ord), nil
+	case links.SortFieldAction:
+		return orderExprWithTie(sqlAliasAudit, sqlColAction, ord), nil
+	case links.SortFieldActor:
+		return orderExprWithTie(sqlAliasAudit, sqlColActor, ord), nil
+	case links.SortFieldCreatedAt:
+		return orderExprWithTie(sqlAliasAudit, sqlColCreatedAt, ord), nil
+	default:
+		return "", links.ErrInvalidSort
+	}
+}
+
+func orderByRevisions(sort links.Sort) (string, error) {
+	ord, ok := normalizeOrder(sort.Order)
+	if !ok {
+		return "", links.ErrInvalidSort
+	}
+
+	switch sort.Field {
+	case links.SortFieldID:
+		return orderExpr(sqlAliasRevs, sqlColID, ord), nil
+	case links.SortFieldCreatedAt:
+		return orderExprWithTie(sqlAliasRevs, sqlColCreatedAt, ord), nil
+	default:
+		return "", links.ErrInvalidSort
+	}
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"code/internal/adapters/postgres/sqlcgen"
+	"code/internal/app/links"
+)
+
+const (
+	usageCounterMonthlyClicks = "monthly_clicks"
+
+	// advisoryLockLinkQuota is an arbitrary application-wide key for pg_advisory_xact_lock.
+	advisoryLockLinkQuota int64 = 26_001
+)
+
+type QuotaRepo struct {
+	db *sql.DB
+}
+
+func NewQuotaRepo(db *sql.DB) *QuotaRepo {
+	return &QuotaRepo{db: db}
+}
+
+var _ links.QuotaRepo = (*QuotaRepo)(nil)
+
+func (r *QuotaRepo) LockLinkQuota(ctx context.Context) error {
+	if err := queries(ctx, r.db).LockAdvisoryXact(ctx, advisoryLockLinkQuota); err != nil {
+		return fmt.Errorf("postgres: lock link quota: %w", err)
+	}
+
+	return nil
+}
+
+func (r *QuotaRepo) IncrementMonthlyClicks(ctx context.Context, period time.Time, limit int64) (bool, error) {
+	_, err := queries(ctx, r.db).IncrementUsageCounter(ctx, sqlcgen.IncrementUsageCounterParams{
+		Name:    usageCounterMonthlyClicks,
+		Period:  period,
+		MaxUsed: sql.NullInt64{Int64: limit, Valid: limit > 0},
+	})
+	if err != nil {
+		// The conditional upsert returns no row once the limit is reached.
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("postgres: increment monthly clicks: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *QuotaRepo) MonthlyClicks(ctx context.Context, period time.Time) (int64, error) {
+	used, err := queries(ctx, r.db).GetUsageCounter(ctx, sqlcgen.GetUsageCounterParams{
+		Name:   usageCounterMonthlyClicks,
+		Period: period,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("postgres: get monthly clicks: %w", err)
+	}
+
+	return used, nil
+}
@@ -3,8 +3,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -17,41 +19,99 @@ import (
 // PostgreSQL SQLSTATE error codes.
 // See: https://www.postgresql.org/docs/current/errcodes-appendix.html
 const (
-	sqlStateUniqueViolation = "23505"
+	sqlStateUniqueViolation     = "23505"
+	sqlStateForeignKeyViolation = "23503"
 )
 
 type Repo struct {
 	db *sql.DB
-	q  *sqlcgen.Queries
 }
 
 func NewRepo(db *sql.DB) *Repo {
-	return &Repo{db: db, q: sqlcgen.New(db)}
+	return &Repo{db: db}
 }
 
-var _ links.Repo = (*Repo)(nil)
+var (
+	_ links.Repo              = (*Repo)(nil)
+	_ links.ShortNameSequence = (*Repo)(nil)
+)
+
+func (r *Repo) ListAll(ctx context.Context, filter links.LinksFilter, sort links.Sort) ([]domain.Link, error) {
+	orderBy, err := orderByLinks(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listLinks(ctx, filter, orderBy, nil, nil, "list all links")
+}
 
-func (r *Repo) ListAll(ctx context.Context, sort links.Sort) ([]domain.Link, error) {
+func (r *Repo) ListPage(
+	ctx context.Context,
+	filter links.LinksFilter,
+	offset, limit int32,
+	sort links.Sort,
+) ([]domain.Link, error) {
 	orderBy, err := orderByLinks(sort)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.listLinks(ctx, orderBy, nil, nil, "list all links")
+	return r.listLinks(ctx, filter, orderBy, &limit, &offset, "list links page")
 }
 
-func (r *Repo) ListPage(ctx context.Context, offset, limit int32, sort links.Sort) ([]domain.Link, error) {
+func (r *Repo) StreamAll(
+	ctx context.Context,
+	filter links.LinksFilter,
+	sort links.Sort,
+	fn func(domain.Link) error,
+) error {
 	orderBy, err := orderByLinks(sort)
+	if err != nil {
+		return err
+	}
+
+	return r.eachLink(ctx, filter, orderBy, nil, nil, "stream links", fn)
+}
+
+func (r *Repo) listLinks(
+	ctx context.Context,
+	filter links.LinksFilter,
+	orderBy string,
+	limit, offset *int32,
+	op string,
+) ([]domain.Link, error) {
+	var out []domain.Link
+
+	err := r.eachLink(ctx, filter, orderBy, limit, offset, op, func(item domain.Link) error {
+		out = append(out, item)
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return r.listLinks(ctx, orderBy, &limit, &offset, "list links page")
+	return out, nil
 }
 
-func (r *Repo) listLinks(ctx context.Context, orderBy string, limit, offset *int32, op string) ([]domain.Link, error) {
+// eachLink scans rows one at a time; errors returned by fn are passed through
+// unwrapped.
+func (r *Repo) eachLink(
+	ctx context.Context,
+	filter links.LinksFilter,
+	orderBy string,
+	limit, offset *int32,
+	op string,
+	fn func(domain.Link) error,
+) error {
+	where, err := linksWhere(filter)
+	if err != nil {
+		return fmt.Errorf("postgres: build %s: %w", op, err)
+	}
+
 	builder := sq.Select(sqlLinksSelectCols...).
 		From(sqlTableLinks + " " + sqlAliasLinks).
+		Where(where).
 		OrderBy(orderBy).
 		PlaceholderFormat(sq.Dollar)
 
@@ -63,12 +123,106 @@ func (r *Repo) listLinks(ctx context.Context, orderBy string, limit, offset *int
 		builder = builder.Offset(uint64(*offset))
 	}
 
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("postgres: build %s: %w", op, err)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf(errOpFmt, op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			item       domain.Link
+			tags       []byte
+			rules      []byte
+			variants   []byte
+			flaggedAt  sql.NullTime
+			activeFrom sql.NullTime
+			host       sql.NullString
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.OriginalURL,
+			&item.ShortName,
+			&item.CreatedAt,
+			&item.Version,
+			&tags,
+			&item.Disabled,
+			&item.NormalizedURL,
+			&flaggedAt,
+			&item.FlagReason,
+			&host,
+			&item.Title,
+			&item.ForwardPath,
+			&item.ForwardQuery,
+			&item.UTM.Source,
+			&item.UTM.Medium,
+			&item.UTM.Campaign,
+			&item.UTM.Term,
+			&item.UTM.Content,
+			&rules,
+			&variants,
+			&item.StickyVariant,
+			&activeFrom,
+		); err != nil {
+			return fmt.Errorf(errOpFmt, op, err)
+		}
+
+		item.FlaggedAt = timePtr(flaggedAt)
+		item.ActiveFrom = timePtr(activeFrom)
+		item.Domain = host.String
+
+		if item.Tags, err = decodeTags(tags); err != nil {
+			return fmt.Errorf(errOpFmt, op, err)
+		}
+
+		if item.Rules, err = decodeRules(rules); err != nil {
+			return fmt.Errorf(errOpFmt, op, err)
+		}
+
+		if item.Variants, err = decodeVariants(variants); err != nil {
+			return fmt.Errorf(errOpFmt, op, err)
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf(errOpFmt, op, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) LockMatching(ctx context.Context, filter links.LinksFilter) ([]int64, error) {
+	const op = "lock matching links"
+
+	where, err := linksWhere(filter)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: build %s: %w", op, err)
+	}
+
+	builder := sq.Select(qualify(sqlAliasLinks, sqlColID)).
+		From(sqlTableLinks + " " + sqlAliasLinks).
+		Where(where).
+		OrderBy(qualify(sqlAliasLinks, sqlColID)).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
 	query, args, err := builder.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("postgres: build %s: %w", op, err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf(errOpFmt, op, err)
 	}
@@ -76,34 +230,85 @@ func (r *Repo) listLinks(ctx context.Context, orderBy string, limit, offset *int
 		_ = rows.Close()
 	}()
 
-	var out []domain.Link
+	ids := []int64{}
 	for rows.Next() {
-		var item domain.Link
-		if err := rows.Scan(&item.ID, &item.OriginalURL, &item.ShortName, &item.CreatedAt); err != nil {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
 			return nil, fmt.Errorf(errOpFmt, op, err)
 		}
 
-		out = append(out, item)
+		ids = append(ids, id)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf(errOpFmt, op, err)
 	}
 
-	return out, nil
+	return ids, nil
 }
 
-func (r *Repo) Count(ctx context.Context) (int64, error) {
-	total, err := r.q.CountLinks(ctx)
+func (r *Repo) Count(ctx context.Context, filter links.LinksFilter) (int64, error) {
+	where, err := linksWhere(filter)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: build count links: %w", err)
+	}
+
+	query, args, err := sq.Select("COUNT(*)").
+		From(sqlTableLinks + " " + sqlAliasLinks).
+		Where(where).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
+		return 0, fmt.Errorf("postgres: build count links: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
 		return 0, fmt.Errorf("postgres: count links: %w", err)
 	}
 
 	return total, nil
 }
 
+// linksWhere combines the set fields of filter with AND.
+func linksWhere(filter links.LinksFilter) (sq.And, error) {
+	where := sq.And{}
+
+	if filter.IDs != nil {
+		where = append(where, sq.Eq{qualify(sqlAliasLinks, sqlColID): filter.IDs})
+	}
+
+	if filter.Tag != "" {
+		tag, err := encodeTags([]string{filter.Tag})
+		if err != nil {
+			return nil, err
+		}
+
+		where = append(where, sq.Expr(qualify(sqlAliasLinks, sqlColTags)+" @> ?::jsonb", string(tag)))
+	}
+
+	if filter.Disabled != nil {
+		where = append(where, sq.Eq{qualify(sqlAliasLinks, sqlColDisabled): *filter.Disabled})
+	}
+
+	if filter.Campaign != "" {
+		where = append(where, sq.Eq{qualify(sqlAliasLinks, sqlColUTMCampaign): filter.Campaign})
+	}
+
+	return where, nil
+}
+
+func (r *Repo) NextShortNameSeq(ctx context.Context) (int64, error) {
+	n, err := queries(ctx, r.db).NextShortNameSeq(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: next short name seq: %w", err)
+	}
+
+	return n, nil
+}
+
 func (r *Repo) GetByID(ctx context.Context, id int64) (domain.Link, error) {
-	row, err := r.q.GetLinkByID(ctx, id)
+	row, err := queries(ctx, r.db).GetLinkByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Link{}, domain.ErrNotFound
@@ -112,11 +317,27 @@ func (r *Repo) GetByID(ctx context.Context, id int64) (domain.Link, error) {
 		return domain.Link{}, fmt.Errorf("postgres: get link by id: %w", err)
 	}
 
-	return mapRow(row), nil
+	return mapRow(row)
 }
 
-func (r *Repo) GetByShortName(ctx context.Context, shortName string) (domain.Link, error) {
-	row, err := r.q.GetLinkByShortName(ctx, shortName)
+func (r *Repo) GetByIDForUpdate(ctx context.Context, id int64) (domain.Link, error) {
+	row, err := queries(ctx, r.db).GetLinkByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, domain.ErrNotFound
+		}
+
+		return domain.Link{}, fmt.Errorf("postgres: get link by id for update: %w", err)
+	}
+
+	return mapRow(row)
+}
+
+func (r *Repo) GetByShortName(ctx context.Context, host, shortName string) (domain.Link, error) {
+	row, err := queries(ctx, r.db).GetLinkByShortName(ctx, sqlcgen.GetLinkByShortNameParams{
+		ShortName: shortName,
+		Domain:    nullHost(host),
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Link{}, domain.ErrNotFound
@@ -125,38 +346,168 @@ func (r *Repo) GetByShortName(ctx context.Context, shortName string) (domain.Lin
 		return domain.Link{}, fmt.Errorf("postgres: get link by short name: %w", err)
 	}
 
-	return mapRow(row), nil
+	return mapRow(row)
+}
+
+func (r *Repo) GetByShortNameFold(ctx context.Context, host, shortName string) (domain.Link, error) {
+	row, err := queries(ctx, r.db).GetLinkByShortNameFold(ctx, sqlcgen.GetLinkByShortNameFoldParams{
+		ShortName: shortName,
+		Domain:    nullHost(host),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, domain.ErrNotFound
+		}
+
+		return domain.Link{}, fmt.Errorf("postgres: get link by short name fold: %w", err)
+	}
+
+	return mapRow(row)
 }
 
-func (r *Repo) Create(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
-	row, err := r.q.CreateLink(ctx, sqlcgen.CreateLinkParams{
-		OriginalUrl: originalURL,
-		ShortName:   shortName,
+func (r *Repo) GetByNormalizedURL(ctx context.Context, host, normalizedURL string) (domain.Link, error) {
+	row, err := queries(ctx, r.db).GetLinkByNormalizedURL(ctx, sqlcgen.GetLinkByNormalizedURLParams{
+		NormalizedUrl: normalizedURL,
+		Domain:        nullHost(host),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, domain.ErrNotFound
+		}
+
+		return domain.Link{}, fmt.Errorf("postgres: get link by normalized url: %w", err)
+	}
+
+	return mapRow(row)
+}
+
+// ListFlagged returns the links flagged by the blocklist, most recent first.
+func (r *Repo) ListFlagged(ctx context.Context) ([]domain.Link, error) {
+	rows, err := queries(ctx, r.db).ListFlaggedLinks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list flagged links: %w", err)
+	}
+
+	out := make([]domain.Link, 0, len(rows))
+	for _, row := range rows {
+		link, err := mapRow(row)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, link)
+	}
+
+	return out, nil
+}
+
+func (r *Repo) Flag(ctx context.Context, id int64, reason string) (domain.Link, error) {
+	row, err := queries(ctx, r.db).FlagLink(ctx, sqlcgen.FlagLinkParams{
+		ID:         id,
+		FlagReason: reason,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, domain.ErrNotFound
+		}
+
+		return domain.Link{}, fmt.Errorf("postgres: flag link: %w", err)
+	}
+
+	return mapRow(row)
+}
+
+func (r *Repo) Create(ctx context.Context, link domain.Link) (domain.Link, error) {
+	tags, err := encodeTags(link.Tags)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: create link: %w", err)
+	}
+
+	rules, err := encodeRules(link.Rules)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: create link: %w", err)
+	}
+
+	variants, err := encodeVariants(link.Variants)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: create link: %w", err)
+	}
+
+	row, err := queries(ctx, r.db).CreateLink(ctx, sqlcgen.CreateLinkParams{
+		OriginalUrl:   link.OriginalURL,
+		ShortName:     link.ShortName,
+		Tags:          tags,
+		Disabled:      link.Disabled,
+		NormalizedUrl: link.NormalizedURL,
+		Domain:        nullHost(link.Domain),
+		Title:         link.Title,
+		ForwardPath:   link.ForwardPath,
+		ForwardQuery:  link.ForwardQuery,
+		UtmSource:     link.UTM.Source,
+		UtmMedium:     link.UTM.Medium,
+		UtmCampaign:   link.UTM.Campaign,
+		UtmTerm:       link.UTM.Term,
+		UtmContent:    link.UTM.Content,
+		Rules:         rules,
+		Variants:      variants,
+		StickyVariant: link.StickyVariant,
+		ActiveFrom:    nullTime(link.ActiveFrom),
 	})
 	if err != nil {
 		if isUniqueViolation(err) {
 			return domain.Link{}, domain.ErrShortNameConflict
 		}
 
+		if isForeignKeyViolation(err) {
+			return domain.Link{}, domain.ErrUnknownDomain
+		}
+
 		return domain.Link{}, fmt.Errorf("postgres: create link: %w", err)
 	}
 
-	return mapRow(row), nil
+	return mapRow(row)
 }
 
-func (r *Repo) Update(
-	ctx context.Context,
-	id int64,
-	originalURL, shortName string,
-) (domain.Link, error) {
-	row, err := r.q.UpdateLink(ctx, sqlcgen.UpdateLinkParams{
-		ID:          id,
-		OriginalUrl: originalURL,
-		ShortName:   shortName,
+func (r *Repo) Update(ctx context.Context, link domain.Link, expectedVersion int64) (domain.Link, error) {
+	tags, err := encodeTags(link.Tags)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: update link: %w", err)
+	}
+
+	rules, err := encodeRules(link.Rules)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: update link: %w", err)
+	}
+
+	variants, err := encodeVariants(link.Variants)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: update link: %w", err)
+	}
+
+	row, err := queries(ctx, r.db).UpdateLink(ctx, sqlcgen.UpdateLinkParams{
+		ID:              link.ID,
+		OriginalUrl:     link.OriginalURL,
+		ShortName:       link.ShortName,
+		Tags:            tags,
+		Disabled:        link.Disabled,
+		ExpectedVersion: nullVersion(expectedVersion),
+		NormalizedUrl:   link.NormalizedURL,
+		Title:           link.Title,
+		ForwardPath:     link.ForwardPath,
+		ForwardQuery:    link.ForwardQuery,
+		UtmSource:       link.UTM.Source,
+		UtmMedium:       link.UTM.Medium,
+		UtmCampaign:     link.UTM.Campaign,
+		UtmTerm:         link.UTM.Term,
+		UtmContent:      link.UTM.Content,
+		Rules:           rules,
+		Variants:        variants,
+		StickyVariant:   link.StickyVariant,
+		ActiveFrom:      nullTime(link.ActiveFrom),
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Link{}, domain.ErrNotFound
+			return domain.Link{}, r.missingOrStale(ctx, link.ID, expectedVersion)
 		}
 
 		if isUniqueViolation(err) {
@@ -166,36 +517,232 @@ func (r *Repo) Update(
 		return domain.Link{}, fmt.Errorf("postgres: update link: %w", err)
 	}
 
-	return mapRow(row), nil
+	return mapRow(row)
 }
 
-func (r *Repo) Delete(ctx context.Context, id int64) error {
-	n, err := r.q.DeleteLink(ctx, id)
+func (r *Repo) Delete(ctx context.Context, id int64, expectedVersion int64) error {
+	n, err := queries(ctx, r.db).DeleteLink(ctx, sqlcgen.DeleteLinkParams{
+		ID:              id,
+		ExpectedVersion: nullVersion(expectedVersion),
+	})
 	if err != nil {
 		return fmt.Errorf("postgres: delete link: %w", err)
 	}
 
 	if n == 0 {
-		return domain.ErrNotFound
+		return r.missingOrStale(ctx, id, expectedVersion)
 	}
 
 	return nil
 }
 
+// missingOrStale tells apart a missing link from a failed version check
+// after a conditional write matched no rows.
+func (r *Repo) missingOrStale(ctx context.Context, id int64, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		return domain.ErrNotFound
+	}
+
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	return domain.ErrVersionMismatch
+}
+
+func nullVersion(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: v != 0}
+}
+
+// nullHost stores the BASE_URL domain, spelled "", as NULL.
+func nullHost(host string) sql.NullString {
+	return sql.NullString{String: host, Valid: host != ""}
+}
+
 func isUniqueViolation(err error) bool {
+	return hasSQLState(err, sqlStateUniqueViolation)
+}
+
+func isForeignKeyViolation(err error) bool {
+	return hasSQLState(err, sqlStateForeignKeyViolation)
+}
+
+func hasSQLState(err error, code string) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
-		return pgErr.Code == sqlStateUniqueViolation
+		return pgErr.Code == code
 	}
 
 	return false
 }
 
-func mapRow(row sqlcgen.Link) domain.Link {
+func mapRow(row sqlcgen.Link) (domain.Link, error) {
+	tags, err := decodeTags(row.Tags)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: decode link %d: %w", row.ID, err)
+	}
+
+	rules, err := decodeRules(row.Rules)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: decode link %d: %w", row.ID, err)
+	}
+
+	variants, err := decodeVariants(row.Variants)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("postgres: decode link %d: %w", row.ID, err)
+	}
+
 	return domain.Link{
-		ID:          row.ID,
-		OriginalURL: row.OriginalUrl,
-		ShortName:   row.ShortName,
-		CreatedAt:   row.CreatedAt,
+		ID:            row.ID,
+		OriginalURL:   row.OriginalUrl,
+		ShortName:     row.ShortName,
+		CreatedAt:     row.CreatedAt,
+		Version:       row.Version,
+		Tags:          tags,
+		Disabled:      row.Disabled,
+		NormalizedURL: row.NormalizedUrl,
+		FlaggedAt:     timePtr(row.FlaggedAt),
+		FlagReason:    row.FlagReason,
+		Domain:        row.Domain.String,
+		Title:         row.Title,
+		ForwardPath:   row.ForwardPath,
+		ForwardQuery:  row.ForwardQuery,
+		UTM: domain.UTM{
+			Source:   row.UtmSource,
+			Medium:   row.UtmMedium,
+			Campaign: row.UtmCampaign,
+			Term:     row.UtmTerm,
+			Content:  row.UtmContent,
+		},
+		Rules:         rules,
+		Variants:      variants,
+		StickyVariant: row.StickyVariant,
+		ActiveFrom:    timePtr(row.ActiveFrom),
+	}, nil
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// Tags are stored as a JSONB array; nil is written as an empty one.
+func encodeTags(tags []string) (json.RawMessage, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return json.Marshal(tags)
+}
+
+func decodeTags(raw []byte) ([]string, error) {
+	tags := []string{}
+	if len(raw) == 0 {
+		return tags, nil
+	}
+
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// ruleRecord is the stored form of a domain.RedirectRule.
+type ruleRecord struct {
+	OS      string    `json:"os,omitempty"`
+	Device  string    `json:"device,omitempty"`
+	Country string    `json:"country,omitempty"`
+	After   time.Time `json:"after,omitzero"`
+	Before  time.Time `json:"before,omitzero"`
+	URL     string    `json:"url"`
+}
+
+func ruleRecords(rules []domain.RedirectRule) []ruleRecord {
+	records := make([]ruleRecord, 0, len(rules))
+	for _, rule := range rules {
+		records = append(records, ruleRecord(rule))
+	}
+
+	return records
+}
+
+func domainRules(records []ruleRecord) []domain.RedirectRule {
+	rules := make([]domain.RedirectRule, 0, len(records))
+	for _, record := range records {
+		rules = append(rules, domain.RedirectRule(record))
+	}
+
+	return rules
+}
+
+// Rules are stored as a JSONB array in match order.
+func encodeRules(rules []domain.RedirectRule) (json.RawMessage, error) {
+	return json.Marshal(ruleRecords(rules))
+}
+
+func decodeRules(raw []byte) ([]domain.RedirectRule, error) {
+	if len(raw) == 0 {
+		return []domain.RedirectRule{}, nil
 	}
+
+	var records []ruleRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, err
+	}
+
+	return domainRules(records), nil
+}
+
+// variantRecord is the stored form of a domain.Variant.
+type variantRecord struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+func variantRecords(variants []domain.Variant) []variantRecord {
+	records := make([]variantRecord, 0, len(variants))
+	for _, v := range variants {
+		records = append(records, variantRecord(v))
+	}
+
+	return records
+}
+
+func domainVariants(records []variantRecord) []domain.Variant {
+	variants := make([]domain.Variant, 0, len(records))
+	for _, record := range records {
+		variants = append(variants, domain.Variant(record))
+	}
+
+	return variants
+}
+
+// Variants are stored as a JSONB array; their indexes are recorded on visits.
+func encodeVariants(variants []domain.Variant) (json.RawMessage, error) {
+	return json.Marshal(variantRecords(variants))
+}
+
+func decodeVariants(raw []byte) ([]domain.Variant, error) {
+	if len(raw) == 0 {
+		return []domain.Variant{}, nil
+	}
+
+	var records []variantRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, err
+	}
+
+	return domainVariants(records), nil
 }
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"code/internal/adapters/postgres/sqlcgen"
+	"code/internal/app/links"
+	"code/internal/domain"
+)
+
+type RevisionsRepo struct {
+	db *sql.DB
+}
+
+func NewRevisionsRepo(db *sql.DB) *RevisionsRepo {
+	return &RevisionsRepo{db: db}
+}
+
+var _ links.RevisionsRepo = (*RevisionsRepo)(nil)
+
+func (r *RevisionsRepo) Get(ctx context.Context, linkID, id int64) (domain.LinkRevision, error) {
+	row, err := queries(ctx, r.db).GetLinkRevision(ctx, sqlcgen.GetLinkRevisionParams{
+		LinkID: linkID,
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LinkRevision{}, domain.ErrNotFound
+		}
+
+		return domain.LinkRevision{}, fmt.Errorf("postgres: get link revision: %w", err)
+	}
+
+	return domain.LinkRevision{
+		ID:          row.ID,
+		LinkID:      row.LinkID,
+		OriginalURL: row.OriginalUrl,
+		ShortName:   row.ShortName,
+		CreatedAt:   row.CreatedAt,
+	}, nil
+}
+
+func (r *RevisionsRepo) ListAll(ctx context.Context, linkID int64, sort links.Sort) ([]domain.LinkRevision, error) {
+	orderBy, err := orderByRevisions(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listRevisions(ctx, linkID, orderBy, nil, nil, "list link revisions")
+}
+
+func (r *RevisionsRepo) ListPage(
+	ctx context.Context,
+	linkID int64,
+	offset, limit int32,
+	sort links.Sort,
+) ([]domain.LinkRevision, error) {
+	orderBy, err := orderByRevisions(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listRevisions(ctx, linkID, orderBy, &limit, &offset, "list link revisions page")
+}
+
+func (r *RevisionsRepo) Count(ctx context.Context, linkID int64) (int64, error) {
+	total, err := queries(ctx, r.db).CountLinkRevisions(ctx, linkID)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: count link revisions: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *RevisionsRepo) listRevisions(
+	ctx context.Context,
+	linkID int64,
+	orderBy string,
+	limit, offset *int32,
+	op string,
+) ([]domain.LinkRevision, error) {
+	builder := sq.Select(sqlRevisionsSelectCols...).
+		From(sqlTableRevisions + " " + sqlAliasRevs).
+		Where(sq.Eq{qualify(sqlAliasRevs, sqlColLinkID): linkID}).
+		OrderBy(orderBy).
+		PlaceholderFormat(sq.Dollar)
+
+	if limit != nil {
+		builder = builder.Limit(uint64(*limit))
+	}
+
+	if offset != nil {
+		builder = builder.Offset(uint64(*offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("postgres: build %s: %w", op, err)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf(errOpFmt, op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var out []domain.LinkRevision
+	for rows.Next() {
+		var item domain.LinkRevision
+		if err := rows.Scan(
+			&item.ID,
+			&item.LinkID,
+			&item.OriginalURL,
+			&item.ShortName,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf(errOpFmt, op, err)
+		}
+
+		out = append(out, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(errOpFmt, op, err)
+	}
+
+	return out, nil
+}
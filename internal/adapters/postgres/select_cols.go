@@ -6,6 +6,25 @@ var sqlLinksSelectCols = []string{
 	qualify(sqlAliasLinks, sqlColOriginalURL),
 	qualify(sqlAliasLinks, sqlColShortName),
 	qualify(sqlAliasLinks, sqlColCreatedAt),
+	qualify(sqlAliasLinks, sqlColVersion),
+	qualify(sqlAliasLinks, sqlColTags),
+	qualify(sqlAliasLinks, sqlColDisabled),
+	qualify(sqlAliasLinks, sqlColNormalizedURL),
+	qualify(sqlAliasLinks, sqlColFlaggedAt),
+	qualify(sqlAliasLinks, sqlColFlagReason),
+	qualify(sqlAliasLinks, sqlColDomain),
+	qualify(sqlAliasLinks, sqlColTitle),
+	qualify(sqlAliasLinks, sqlColForwardPath),
+	qualify(sqlAliasLinks, sqlColForwardQuery),
+	qualify(sqlAliasLinks, sqlColUTMSource),
+	qualify(sqlAliasLinks, sqlColUTMMedium),
+	qualify(sqlAliasLinks, sqlColUTMCampaign),
+	qualify(sqlAliasLinks, sqlColUTMTerm),
+	qualify(sqlAliasLinks, sqlColUTMContent),
+	qualify(sqlAliasLinks, sqlColRules),
+	qualify(sqlAliasLinks, sqlColVariants),
+	qualify(sqlAliasLinks, sqlColStickyVariant),
+	qualify(sqlAliasLinks, sqlColActiveFrom),
 }
 
 // Order matches Scan in listLinkVisits.
@@ -17,4 +36,29 @@ var sqlVisitsSelectCols = []string{
 	qualify(sqlAliasVisits, sqlColUserAgent),
 	qualify(sqlAliasVisits, sqlColReferer),
 	qualify(sqlAliasVisits, sqlColStatus),
+	qualify(sqlAliasVisits, sqlColPath),
+	qualify(sqlAliasVisits, sqlColRuleIndex),
+	qualify(sqlAliasVisits, sqlColVariantIndex),
+}
+
+// Order matches Scan in listAuditEvents.
+var sqlAuditSelectCols = []string{
+	qualify(sqlAliasAudit, sqlColID),
+	qualify(sqlAliasAudit, sqlColCreatedAt),
+	qualify(sqlAliasAudit, sqlColAction),
+	qualify(sqlAliasAudit, sqlColLinkID),
+	qualify(sqlAliasAudit, sqlColActor),
+	qualify(sqlAliasAudit, sqlColRequestID),
+	qualify(sqlAliasAudit, sqlColIP),
+	qualify(sqlAliasAudit, sqlColBeforeLink),
+	qualify(sqlAliasAudit, sqlColAfterLink),
+}
+
+// Order matches Scan in listRevisions.
+var sqlRevisionsSelectCols = []string{
+	qualify(sqlAliasRevs, sqlColID),
+	qualify(sqlAliasRevs, sqlColLinkID),
+	qualify(sqlAliasRevs, sqlColOriginalURL),
+	qualify(sqlAliasRevs, sqlColShortName),
+	qualify(sqlAliasRevs, sqlColCreatedAt),
 }
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+// createShortNameFoldIndex makes short names unique per domain regardless of
+// case. It fails with a unique violation while case collisions exist.
+const createShortNameFoldIndex = `CREATE UNIQUE INDEX IF NOT EXISTS links_short_name_lower_key
+  ON links (lower(short_name), domain) NULLS NOT DISTINCT`
+
+// ShortNameCollision lists links of one domain whose short names differ only
+// in case. Domain is empty for the BASE_URL domain.
+type ShortNameCollision struct {
+	Domain string
+	Folded string
+	Links  []ShortNameCollisionLink
+}
+
+type ShortNameCollisionLink struct {
+	ID        int64
+	ShortName string
+}
+
+// ShortNameCollisions returns the collisions that prevent switching to
+// case-insensitive short names, ordered by domain and folded name.
+func (r *Repo) ShortNameCollisions(ctx context.Context) ([]ShortNameCollision, error) {
+	rows, err := queries(ctx, r.db).ListShortNameCollisions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list short name collisions: %w", err)
+	}
+
+	var out []ShortNameCollision
+
+	for _, row := range rows {
+		if len(out) == 0 || out[len(out)-1].Folded != row.Folded || out[len(out)-1].Domain != row.DomainHost {
+			out = append(out, ShortNameCollision{Domain: row.DomainHost, Folded: row.Folded})
+		}
+
+		last := &out[len(out)-1]
+		last.Links = append(last.Links, ShortNameCollisionLink{ID: row.ID, ShortName: row.ShortName})
+	}
+
+	return out, nil
+}
+
+// CaseInsensitiveShortNames reports whether the case-insensitive unique
+// index exists.
+func (r *Repo) CaseInsensitiveShortNames(ctx context.Context) (bool, error) {
+	ok, err := queries(ctx, r.db).HasShortNameFoldIndex(ctx)
+	if err != nil {
+		return false, fmt.Errorf("postgres: check short name fold index: %w", err)
+	}
+
+	return ok, nil
+}
+
+// EnableCaseInsensitiveShortNames creates the case-insensitive unique index;
+// it fails with domain.ErrShortNameConflict while collisions exist.
+func (r *Repo) EnableCaseInsensitiveShortNames(ctx context.Context) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, createShortNameFoldIndex); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrShortNameConflict
+		}
+
+		return fmt.Errorf("postgres: create short name fold index: %w", err)
+	}
+
+	return nil
+}
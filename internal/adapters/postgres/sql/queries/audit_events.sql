@@ -0,0 +1,3 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (created_at, action, link_id, actor, request_id, ip, before_link, after_link)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
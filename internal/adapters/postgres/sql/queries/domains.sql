@@ -0,0 +1,23 @@
+-- name: ListDomains :many
+SELECT id, host, created_at
+FROM domains
+ORDER BY host;
+
+-- name: GetDomain :one
+SELECT id, host, created_at
+FROM domains
+WHERE id = $1;
+
+-- name: GetDomainByHost :one
+SELECT id, host, created_at
+FROM domains
+WHERE host = $1;
+
+-- name: CreateDomain :one
+INSERT INTO domains (host)
+VALUES ($1)
+RETURNING id, host, created_at;
+
+-- name: DeleteDomain :execrows
+DELETE FROM domains
+WHERE id = $1;
@@ -0,0 +1,34 @@
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (key, request_hash, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    status = NULL,
+    headers = '{}',
+    body = '',
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING key;
+
+-- name: GetIdempotencyKey :one
+SELECT key, request_hash, status, headers, body, created_at, expires_at
+FROM idempotency_keys
+WHERE key = $1
+  AND expires_at > now();
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = $2,
+    headers = $3,
+    body = $4,
+    expires_at = $5
+WHERE key = $1;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= now();
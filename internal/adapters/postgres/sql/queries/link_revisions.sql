@@ -0,0 +1,10 @@
+-- name: GetLinkRevision :one
+SELECT id, link_id, original_url, short_name, created_at
+FROM link_revisions
+WHERE link_id = $1
+  AND id = $2;
+
+-- name: CountLinkRevisions :one
+SELECT COUNT(*)
+FROM link_revisions
+WHERE link_id = $1;
@@ -1,8 +1,13 @@
 -- name: CreateLinkVisit :one
-INSERT INTO link_visits (link_id, created_at, ip, user_agent, referer, status)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO link_visits (link_id, created_at, ip, user_agent, referer, status, path, rule_index, variant_index)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id;
 
 -- name: CountLinkVisits :one
 SELECT COUNT(*)
 FROM link_visits;
+
+-- name: CountLinkVisitsByLink :one
+SELECT COUNT(*)
+FROM link_visits
+WHERE link_id = $1;
@@ -1,29 +1,125 @@
--- name: CountLinks :one
-SELECT COUNT(*)
-FROM links;
-
 -- name: GetLinkByID :one
-SELECT id, original_url, short_name, created_at
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
 FROM links
 WHERE id = $1;
 
+-- name: GetLinkByIDForUpdate :one
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE id = $1
+FOR UPDATE;
+
 -- name: GetLinkByShortName :one
-SELECT id, original_url, short_name, created_at
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE short_name = sqlc.arg(short_name)
+  AND domain IS NOT DISTINCT FROM sqlc.narg(domain);
+
+-- name: GetLinkByShortNameFold :one
+-- An exact match wins over links differing only in case, which can exist
+-- until the case-insensitive unique index is created.
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE lower(short_name) = lower(sqlc.arg(short_name))
+  AND domain IS NOT DISTINCT FROM sqlc.narg(domain)
+ORDER BY short_name = sqlc.arg(short_name) DESC, id
+LIMIT 1;
+
+-- name: ListShortNameCollisions :many
+-- Links of the same domain whose short names differ only in case, grouped by
+-- domain and folded name.
+SELECT id, short_name, lower(short_name)::text AS folded, COALESCE(domain, '')::text AS domain_host
+FROM links
+WHERE (COALESCE(domain, ''), lower(short_name)) IN (
+  SELECT COALESCE(domain, ''), lower(short_name)
+  FROM links
+  GROUP BY 1, 2
+  HAVING COUNT(*) > 1
+)
+ORDER BY domain_host, folded, id;
+
+-- name: HasShortNameFoldIndex :one
+SELECT EXISTS (
+  SELECT 1
+  FROM pg_indexes
+  WHERE tablename = 'links'
+    AND indexname = 'links_short_name_lower_key'
+);
+
+-- name: GetLinkByNormalizedURL :one
+-- The oldest enabled link wins when several share a destination.
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE normalized_url = sqlc.arg(normalized_url)
+  AND domain IS NOT DISTINCT FROM sqlc.narg(domain)
+  AND NOT disabled
+ORDER BY id
+LIMIT 1;
+
+-- name: ListFlaggedLinks :many
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
 FROM links
-WHERE short_name = $1;
+WHERE flagged_at IS NOT NULL
+ORDER BY flagged_at DESC, id DESC;
+
+-- name: FlagLink :one
+UPDATE links
+SET flagged_at  = now(),
+    flag_reason = $2
+WHERE id = $1
+RETURNING id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from;
 
 -- name: CreateLink :one
-INSERT INTO links (original_url, short_name)
-VALUES ($1, $2)
-RETURNING id, original_url, short_name, created_at;
+INSERT INTO links (original_url, short_name, tags, disabled, normalized_url, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from;
 
 -- name: UpdateLink :one
+-- The previous destination and short name are kept in link_revisions within
+-- the same statement, only when one of them changes.
+-- A non-null expected_version turns the update into a compare-and-swap.
+-- Enabling a link clears its blocklist flag.
+WITH prev AS (
+  SELECT id, original_url, short_name
+  FROM links
+  WHERE id = $1
+    AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version)::bigint)
+  FOR UPDATE
+), revision AS (
+  INSERT INTO link_revisions (link_id, original_url, short_name)
+  SELECT id, original_url, short_name
+  FROM prev
+  WHERE prev.original_url IS DISTINCT FROM $2
+     OR prev.short_name IS DISTINCT FROM $3
+)
 UPDATE links
-SET original_url = $2,
-    short_name   = $3
-WHERE id = $1
-RETURNING id, original_url, short_name, created_at;
+SET original_url   = $2,
+    short_name     = $3,
+    tags           = $4,
+    disabled       = $5,
+    normalized_url = $7,
+    title          = $8,
+    forward_path   = $9,
+    forward_query  = $10,
+    utm_source     = $11,
+    utm_medium     = $12,
+    utm_campaign   = $13,
+    utm_term       = $14,
+    utm_content    = $15,
+    rules          = $16,
+    variants       = $17,
+    sticky_variant = $18,
+    active_from    = $19,
+    flagged_at     = CASE WHEN $5 THEN links.flagged_at END,
+    flag_reason    = CASE WHEN $5 THEN links.flag_reason ELSE '' END,
+    version        = links.version + 1
+WHERE links.id IN (SELECT id FROM prev)
+RETURNING links.id, links.original_url, links.short_name, links.created_at, links.version, links.tags, links.disabled, links.normalized_url, links.flagged_at, links.flag_reason, links.domain, links.title, links.forward_path, links.forward_query, links.utm_source, links.utm_medium, links.utm_campaign, links.utm_term, links.utm_content, links.rules, links.variants, links.sticky_variant, links.active_from;
 
 -- name: DeleteLink :execrows
 DELETE FROM links
-WHERE id = $1;
+WHERE id = $1
+  AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version)::bigint);
+
+-- name: NextShortNameSeq :one
+SELECT nextval('links_short_name_seq');
@@ -0,0 +1,17 @@
+-- name: IncrementUsageCounter :one
+INSERT INTO usage_counters (name, period, used)
+VALUES ($1, $2, 1)
+ON CONFLICT (name, period) DO UPDATE
+SET used = usage_counters.used + 1
+WHERE sqlc.narg(max_used)::bigint IS NULL
+   OR usage_counters.used < sqlc.narg(max_used)::bigint
+RETURNING used;
+
+-- name: GetUsageCounter :one
+SELECT used
+FROM usage_counters
+WHERE name = $1
+  AND period = $2;
+
+-- name: LockAdvisoryXact :exec
+SELECT pg_advisory_xact_lock($1);
@@ -1,20 +1,53 @@
 package postgres
 
 const (
-	sqlTableLinks      = "links"
-	sqlTableLinkVisits = "link_visits"
+	sqlTableLinks       = "links"
+	sqlTableLinkVisits  = "link_visits"
+	sqlTableAuditEvents = "audit_events"
+	sqlTableRevisions   = "link_revisions"
 
 	sqlAliasLinks  = "l"
 	sqlAliasVisits = "v"
+	sqlAliasAudit  = "a"
+	sqlAliasRevs   = "rv"
 
 	sqlColID          = "id"
 	sqlColShortName   = "short_name"
 	sqlColOriginalURL = "original_url"
 	sqlColCreatedAt   = "created_at"
+	sqlColVersion     = "version"
+	sqlColTags        = "tags"
+	sqlColDisabled    = "disabled"
 
-	sqlColLinkID    = "link_id"
-	sqlColIP        = "ip"
-	sqlColStatus    = "status"
-	sqlColReferer   = "referer"
-	sqlColUserAgent = "user_agent"
+	sqlColNormalizedURL = "normalized_url"
+	sqlColFlaggedAt     = "flagged_at"
+	sqlColFlagReason    = "flag_reason"
+	sqlColDomain        = "domain"
+	sqlColTitle         = "title"
+	sqlColForwardPath   = "forward_path"
+	sqlColForwardQuery  = "forward_query"
+	sqlColUTMSource     = "utm_source"
+	sqlColUTMMedium     = "utm_medium"
+	sqlColUTMCampaign   = "utm_campaign"
+	sqlColUTMTerm       = "utm_term"
+	sqlColUTMContent    = "utm_content"
+	sqlColRules         = "rules"
+	sqlColVariants      = "variants"
+	sqlColStickyVariant = "sticky_variant"
+	sqlColActiveFrom    = "active_from"
+
+	sqlColLinkID       = "link_id"
+	sqlColIP           = "ip"
+	sqlColStatus       = "status"
+	sqlColReferer      = "referer"
+	sqlColUserAgent    = "user_agent"
+	sqlColPath         = "path"
+	sqlColRuleIndex    = "rule_index"
+	sqlColVariantIndex = "variant_index"
+
+	sqlColAction     = "action"
+	sqlColActor      = "actor"
+	sqlColRequestID  = "request_id"
+	sqlColBeforeLink = "before_link"
+	sqlColAfterLink  = "after_link"
 )
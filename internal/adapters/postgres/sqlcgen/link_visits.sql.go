@@ -7,6 +7,7 @@ package sqlcgen
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -22,19 +23,35 @@ func (q *Queries) CountLinkVisits(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countLinkVisitsByLink = `-- name: CountLinkVisitsByLink :one
+SELECT COUNT(*)
+FROM link_visits
+WHERE link_id = $1
+`
+
+func (q *Queries) CountLinkVisitsByLink(ctx context.Context, linkID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countLinkVisitsByLink, linkID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createLinkVisit = `-- name: CreateLinkVisit :one
-INSERT INTO link_visits (link_id, created_at, ip, user_agent, referer, status)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO link_visits (link_id, created_at, ip, user_agent, referer, status, path, rule_index, variant_index)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id
 `
 
 type CreateLinkVisitParams struct {
-	LinkID    int64
-	CreatedAt time.Time
-	Ip        string
-	UserAgent string
-	Referer   string
-	Status    int32
+	LinkID       int64
+	CreatedAt    time.Time
+	Ip           string
+	UserAgent    string
+	Referer      string
+	Status       int32
+	Path         string
+	RuleIndex    sql.NullInt32
+	VariantIndex sql.NullInt32
 }
 
 func (q *Queries) CreateLinkVisit(ctx context.Context, arg CreateLinkVisitParams) (int64, error) {
@@ -45,6 +62,9 @@ func (q *Queries) CreateLinkVisit(ctx context.Context, arg CreateLinkVisitParams
 		arg.UserAgent,
 		arg.Referer,
 		arg.Status,
+		arg.Path,
+		arg.RuleIndex,
+		arg.VariantIndex,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -7,39 +7,83 @@ package sqlcgen
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 )
 
-const countLinks = `-- name: CountLinks :one
-SELECT COUNT(*)
-FROM links
-`
-
-func (q *Queries) CountLinks(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countLinks)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
-}
-
 const createLink = `-- name: CreateLink :one
-INSERT INTO links (original_url, short_name)
-VALUES ($1, $2)
-RETURNING id, original_url, short_name, created_at
+INSERT INTO links (original_url, short_name, tags, disabled, normalized_url, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
 `
 
 type CreateLinkParams struct {
-	OriginalUrl string
-	ShortName   string
+	OriginalUrl   string
+	ShortName     string
+	Tags          json.RawMessage
+	Disabled      bool
+	NormalizedUrl string
+	Domain        sql.NullString
+	Title         string
+	ForwardPath   bool
+	ForwardQuery  string
+	UtmSource     string
+	UtmMedium     string
+	UtmCampaign   string
+	UtmTerm       string
+	UtmContent    string
+	Rules         json.RawMessage
+	Variants      json.RawMessage
+	StickyVariant bool
+	ActiveFrom    sql.NullTime
 }
 
 func (q *Queries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, error) {
-	row := q.db.QueryRowContext(ctx, createLink, arg.OriginalUrl, arg.ShortName)
+	row := q.db.QueryRowContext(ctx, createLink,
+		arg.OriginalUrl,
+		arg.ShortName,
+		arg.Tags,
+		arg.Disabled,
+		arg.NormalizedUrl,
+		arg.Domain,
+		arg.Title,
+		arg.ForwardPath,
+		arg.ForwardQuery,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.UtmTerm,
+		arg.UtmContent,
+		arg.Rules,
+		arg.Variants,
+		arg.StickyVariant,
+		arg.ActiveFrom,
+	)
 	var i Link
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.ShortName,
 		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
 	)
 	return i, err
 }
@@ -47,18 +91,68 @@ func (q *Queries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, e
 const deleteLink = `-- name: DeleteLink :execrows
 DELETE FROM links
 WHERE id = $1
+  AND ($2::bigint IS NULL OR version = $2::bigint)
 `
 
-func (q *Queries) DeleteLink(ctx context.Context, id int64) (int64, error) {
-	result, err := q.db.ExecContext(ctx, deleteLink, id)
+type DeleteLinkParams struct {
+	ID              int64
+	ExpectedVersion sql.NullInt64
+}
+
+func (q *Queries) DeleteLink(ctx context.Context, arg DeleteLinkParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLink, arg.ID, arg.ExpectedVersion)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
+const flagLink = `-- name: FlagLink :one
+UPDATE links
+SET flagged_at  = now(),
+    flag_reason = $2
+WHERE id = $1
+RETURNING id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+`
+
+type FlagLinkParams struct {
+	ID         int64
+	FlagReason string
+}
+
+func (q *Queries) FlagLink(ctx context.Context, arg FlagLinkParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, flagLink, arg.ID, arg.FlagReason)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortName,
+		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
+	)
+	return i, err
+}
+
 const getLinkByID = `-- name: GetLinkByID :one
-SELECT id, original_url, short_name, created_at
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
 FROM links
 WHERE id = $1
 `
@@ -71,50 +165,437 @@ func (q *Queries) GetLinkByID(ctx context.Context, id int64) (Link, error) {
 		&i.OriginalUrl,
 		&i.ShortName,
 		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
+	)
+	return i, err
+}
+
+const getLinkByIDForUpdate = `-- name: GetLinkByIDForUpdate :one
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetLinkByIDForUpdate(ctx context.Context, id int64) (Link, error) {
+	row := q.db.QueryRowContext(ctx, getLinkByIDForUpdate, id)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortName,
+		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
+	)
+	return i, err
+}
+
+const getLinkByNormalizedURL = `-- name: GetLinkByNormalizedURL :one
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE normalized_url = $1
+  AND domain IS NOT DISTINCT FROM $2
+  AND NOT disabled
+ORDER BY id
+LIMIT 1
+`
+
+type GetLinkByNormalizedURLParams struct {
+	NormalizedUrl string
+	Domain        sql.NullString
+}
+
+func (q *Queries) GetLinkByNormalizedURL(ctx context.Context, arg GetLinkByNormalizedURLParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, getLinkByNormalizedURL, arg.NormalizedUrl, arg.Domain)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortName,
+		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
 	)
 	return i, err
 }
 
 const getLinkByShortName = `-- name: GetLinkByShortName :one
-SELECT id, original_url, short_name, created_at
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
 FROM links
 WHERE short_name = $1
+  AND domain IS NOT DISTINCT FROM $2
 `
 
-func (q *Queries) GetLinkByShortName(ctx context.Context, shortName string) (Link, error) {
-	row := q.db.QueryRowContext(ctx, getLinkByShortName, shortName)
+type GetLinkByShortNameParams struct {
+	ShortName string
+	Domain    sql.NullString
+}
+
+func (q *Queries) GetLinkByShortName(ctx context.Context, arg GetLinkByShortNameParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, getLinkByShortName, arg.ShortName, arg.Domain)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortName,
+		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
+	)
+	return i, err
+}
+
+const getLinkByShortNameFold = `-- name: GetLinkByShortNameFold :one
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE lower(short_name) = lower($1)
+  AND domain IS NOT DISTINCT FROM $2
+ORDER BY short_name = $1 DESC, id
+LIMIT 1
+`
+
+type GetLinkByShortNameFoldParams struct {
+	ShortName string
+	Domain    sql.NullString
+}
+
+func (q *Queries) GetLinkByShortNameFold(ctx context.Context, arg GetLinkByShortNameFoldParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, getLinkByShortNameFold, arg.ShortName, arg.Domain)
 	var i Link
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.ShortName,
 		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
 	)
 	return i, err
 }
 
+const hasShortNameFoldIndex = `-- name: HasShortNameFoldIndex :one
+SELECT EXISTS (
+  SELECT 1
+  FROM pg_indexes
+  WHERE tablename = 'links'
+    AND indexname = 'links_short_name_lower_key'
+)
+`
+
+func (q *Queries) HasShortNameFoldIndex(ctx context.Context) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasShortNameFoldIndex)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listFlaggedLinks = `-- name: ListFlaggedLinks :many
+SELECT id, original_url, short_name, created_at, version, tags, disabled, normalized_url, flagged_at, flag_reason, domain, title, forward_path, forward_query, utm_source, utm_medium, utm_campaign, utm_term, utm_content, rules, variants, sticky_variant, active_from
+FROM links
+WHERE flagged_at IS NOT NULL
+ORDER BY flagged_at DESC, id DESC
+`
+
+func (q *Queries) ListFlaggedLinks(ctx context.Context) ([]Link, error) {
+	rows, err := q.db.QueryContext(ctx, listFlaggedLinks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Link
+	for rows.Next() {
+		var i Link
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortName,
+			&i.CreatedAt,
+			&i.Version,
+			&i.Tags,
+			&i.Disabled,
+			&i.NormalizedUrl,
+			&i.FlaggedAt,
+			&i.FlagReason,
+			&i.Domain,
+			&i.Title,
+			&i.ForwardPath,
+			&i.ForwardQuery,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.UtmTerm,
+			&i.UtmContent,
+			&i.Rules,
+			&i.Variants,
+			&i.StickyVariant,
+			&i.ActiveFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShortNameCollisions = `-- name: ListShortNameCollisions :many
+SELECT id, short_name, lower(short_name)::text AS folded, COALESCE(domain, '')::text AS domain_host
+FROM links
+WHERE (COALESCE(domain, ''), lower(short_name)) IN (
+  SELECT COALESCE(domain, ''), lower(short_name)
+  FROM links
+  GROUP BY 1, 2
+  HAVING COUNT(*) > 1
+)
+ORDER BY domain_host, folded, id
+`
+
+type ListShortNameCollisionsRow struct {
+	ID         int64
+	ShortName  string
+	Folded     string
+	DomainHost string
+}
+
+func (q *Queries) ListShortNameCollisions(ctx context.Context) ([]ListShortNameCollisionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listShortNameCollisions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListShortNameCollisionsRow
+	for rows.Next() {
+		var i ListShortNameCollisionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortName,
+			&i.Folded,
+			&i.DomainHost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextShortNameSeq = `-- name: NextShortNameSeq :one
+SELECT nextval('links_short_name_seq')
+`
+
+func (q *Queries) NextShortNameSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, nextShortNameSeq)
+	var nextval int64
+	err := row.Scan(&nextval)
+	return nextval, err
+}
+
 const updateLink = `-- name: UpdateLink :one
+WITH prev AS (
+  SELECT id, original_url, short_name
+  FROM links
+  WHERE id = $1
+    AND ($6::bigint IS NULL OR version = $6::bigint)
+  FOR UPDATE
+), revision AS (
+  INSERT INTO link_revisions (link_id, original_url, short_name)
+  SELECT id, original_url, short_name
+  FROM prev
+  WHERE prev.original_url IS DISTINCT FROM $2
+     OR prev.short_name IS DISTINCT FROM $3
+)
 UPDATE links
-SET original_url = $2,
-    short_name   = $3
-WHERE id = $1
-RETURNING id, original_url, short_name, created_at
+SET original_url   = $2,
+    short_name     = $3,
+    tags           = $4,
+    disabled       = $5,
+    normalized_url = $7,
+    title          = $8,
+    forward_path   = $9,
+    forward_query  = $10,
+    utm_source     = $11,
+    utm_medium     = $12,
+    utm_campaign   = $13,
+    utm_term       = $14,
+    utm_content    = $15,
+    rules          = $16,
+    variants       = $17,
+    sticky_variant = $18,
+    active_from    = $19,
+    flagged_at     = CASE WHEN $5 THEN links.flagged_at END,
+    flag_reason    = CASE WHEN $5 THEN links.flag_reason ELSE '' END,
+    version        = links.version + 1
+WHERE links.id IN (SELECT id FROM prev)
+RETURNING links.id, links.original_url, links.short_name, links.created_at, links.version, links.tags, links.disabled, links.normalized_url, links.flagged_at, links.flag_reason, links.domain, links.title, links.forward_path, links.forward_query, links.utm_source, links.utm_medium, links.utm_campaign, links.utm_term, links.utm_content, links.rules, links.variants, links.sticky_variant, links.active_from
 `
 
 type UpdateLinkParams struct {
-	ID          int64
-	OriginalUrl string
-	ShortName   string
+	ID              int64
+	OriginalUrl     string
+	ShortName       string
+	Tags            json.RawMessage
+	Disabled        bool
+	ExpectedVersion sql.NullInt64
+	NormalizedUrl   string
+	Title           string
+	ForwardPath     bool
+	ForwardQuery    string
+	UtmSource       string
+	UtmMedium       string
+	UtmCampaign     string
+	UtmTerm         string
+	UtmContent      string
+	Rules           json.RawMessage
+	Variants        json.RawMessage
+	StickyVariant   bool
+	ActiveFrom      sql.NullTime
 }
 
 func (q *Queries) UpdateLink(ctx context.Context, arg UpdateLinkParams) (Link, error) {
-	row := q.db.QueryRowContext(ctx, updateLink, arg.ID, arg.OriginalUrl, arg.ShortName)
+	row := q.db.QueryRowContext(ctx, updateLink,
+		arg.ID,
+		arg.OriginalUrl,
+		arg.ShortName,
+		arg.Tags,
+		arg.Disabled,
+		arg.ExpectedVersion,
+		arg.NormalizedUrl,
+		arg.Title,
+		arg.ForwardPath,
+		arg.ForwardQuery,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.UtmTerm,
+		arg.UtmContent,
+		arg.Rules,
+		arg.Variants,
+		arg.StickyVariant,
+		arg.ActiveFrom,
+	)
 	var i Link
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.ShortName,
 		&i.CreatedAt,
+		&i.Version,
+		&i.Tags,
+		&i.Disabled,
+		&i.NormalizedUrl,
+		&i.FlaggedAt,
+		&i.FlagReason,
+		&i.Domain,
+		&i.Title,
+		&i.ForwardPath,
+		&i.ForwardQuery,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.UtmTerm,
+		&i.UtmContent,
+		&i.Rules,
+		&i.Variants,
+		&i.StickyVariant,
+		&i.ActiveFrom,
 	)
 	return i, err
 }
@@ -5,22 +5,88 @@
 package sqlcgen
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type AuditEvent struct {
+	ID         int64
+	CreatedAt  time.Time
+	Action     string
+	LinkID     int64
+	Actor      string
+	RequestID  string
+	Ip         string
+	BeforeLink json.RawMessage
+	AfterLink  json.RawMessage
+}
+
+type Domain struct {
+	ID        int64
+	Host      string
+	CreatedAt time.Time
+}
+
+type IdempotencyKey struct {
+	Key         string
+	RequestHash []byte
+	Status      sql.NullInt32
+	Headers     json.RawMessage
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
 type Link struct {
+	ID            int64
+	OriginalUrl   string
+	ShortName     string
+	CreatedAt     time.Time
+	Version       int64
+	Tags          json.RawMessage
+	Disabled      bool
+	NormalizedUrl string
+	FlaggedAt     sql.NullTime
+	FlagReason    string
+	Domain        sql.NullString
+	Title         string
+	ForwardPath   bool
+	ForwardQuery  string
+	UtmSource     string
+	UtmMedium     string
+	UtmCampaign   string
+	UtmTerm       string
+	UtmContent    string
+	Rules         json.RawMessage
+	Variants      json.RawMessage
+	StickyVariant bool
+	ActiveFrom    sql.NullTime
+}
+
+type LinkRevision struct {
 	ID          int64
+	LinkID      int64
 	OriginalUrl string
 	ShortName   string
 	CreatedAt   time.Time
 }
 
 type LinkVisit struct {
-	ID        int64
-	LinkID    int64
-	CreatedAt time.Time
-	Ip        string
-	UserAgent string
-	Referer   string
-	Status    int32
+	ID           int64
+	LinkID       int64
+	CreatedAt    time.Time
+	Ip           string
+	UserAgent    string
+	Referer      string
+	Status       int32
+	Path         string
+	RuleIndex    sql.NullInt32
+	VariantIndex sql.NullInt32
+}
+
+type UsageCounter struct {
+	Name   string
+	Period time.Time
+	Used   int64
 }
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"code/internal/adapters/postgres/sqlcgen"
+	"code/internal/app/links"
+)
+
+type txKey struct{}
+
+type txState struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// TxManager stores the active *sql.Tx in the context so that repositories
+// sharing the same *sql.DB join it. Nested calls use savepoints.
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+var _ links.TxManager = (*TxManager)(nil)
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return withinSavepoint(ctx, st, fn)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("postgres: begin tx: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx})); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("postgres: commit tx: %w", err)
+	}
+
+	return nil
+}
+
+func withinSavepoint(ctx context.Context, st *txState, fn func(ctx context.Context) error) error {
+	st.savepoints++
+	name := fmt.Sprintf("sp_%d", st.savepoints)
+
+	if _, err := st.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("postgres: savepoint: %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := st.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("postgres: rollback to savepoint: %w (cause: %w)", rbErr, err)
+		}
+
+		return err
+	}
+
+	if _, err := st.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("postgres: release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// conn returns the transaction bound to ctx, falling back to db.
+func conn(ctx context.Context, db *sql.DB) sqlcgen.DBTX {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return st.tx
+	}
+
+	return db
+}
+
+func queries(ctx context.Context, db *sql.DB) *sqlcgen.Queries {
+	return sqlcgen.New(conn(ctx, db))
+}
@@ -0,0 +1,90 @@
+package links
+
+import (
+	"context"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+const anonymousActor = "anonymous"
+
+// AuditMeta describes who performed a mutation.
+type AuditMeta struct {
+	Actor     string
+	RequestID string
+	IP        string
+}
+
+type auditMetaKey struct{}
+
+func ContextWithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+func AuditMetaFromContext(ctx context.Context) AuditMeta {
+	meta, _ := ctx.Value(auditMetaKey{}).(AuditMeta)
+	if meta.Actor == "" {
+		meta.Actor = anonymousActor
+	}
+
+	return meta
+}
+
+// audit must be called within the transaction of the mutation it records.
+func (s *Service) audit(ctx context.Context, action string, linkID int64, before, after *domain.Link) error {
+	if s.auditRepo == nil {
+		return nil
+	}
+
+	meta := AuditMetaFromContext(ctx)
+	event := domain.AuditEvent{
+		CreatedAt: s.now().UTC(),
+		Action:    action,
+		LinkID:    linkID,
+		Actor:     meta.Actor,
+		RequestID: meta.RequestID,
+		IP:        meta.IP,
+		Before:    before,
+		After:     after,
+	}
+
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		return fmt.Errorf("links audit %s: %w", action, err)
+	}
+
+	return nil
+}
+
+func (s *Service) ListAuditEvents(ctx context.Context, query AuditQuery) ([]domain.AuditEvent, int64, error) {
+	if s.auditRepo == nil {
+		return nil, 0, errAuditRepoNil
+	}
+
+	if query.Range == nil {
+		items, err := s.auditRepo.ListAll(ctx, query.Filter, query.Sort)
+		if err != nil {
+			return nil, 0, fmt.Errorf("audit list all: %w", err)
+		}
+
+		return items, -1, nil
+	}
+
+	items, err := s.auditRepo.ListPage(
+		ctx,
+		query.Filter,
+		int32(query.Range.Start),
+		int32(query.Range.Count),
+		query.Sort,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("audit list page: %w", err)
+	}
+
+	total, err := s.auditRepo.Count(ctx, query.Filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("audit count: %w", err)
+	}
+
+	return items, total, nil
+}
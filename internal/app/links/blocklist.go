@@ -0,0 +1,133 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+// BlocklistActor is the audit actor of links flagged by RescanBlocklist.
+const BlocklistActor = "system:blocklist"
+
+var errBlocklistNil = errors.New("blocklist is nil")
+
+// BlocklistRescan summarizes a RescanBlocklist run.
+type BlocklistRescan struct {
+	Scanned int
+	Flagged int
+}
+
+// RescanBlocklist disables and flags every enabled link with a destination
+// (the original, a rule or a variant URL) matching the blocklist, recording
+// each one as an AuditActionFlag event. A link is flagged in its own
+// transaction, so an error leaves the links already flagged in place.
+func (s *Service) RescanBlocklist(ctx context.Context) (BlocklistRescan, error) {
+	var res BlocklistRescan
+
+	if s.blocklist == nil {
+		return res, errBlocklistNil
+	}
+
+	// Matching while streaming would hold the cursor open across writes, so
+	// candidates are collected first and re-checked under lock.
+	var ids []int64
+
+	err := s.repo.StreamAll(ctx, LinksFilter{}, DefaultLinksSort, func(link domain.Link) error {
+		res.Scanned++
+
+		if _, blocked := s.matchBlocklist(link); blocked && !link.Disabled {
+			ids = append(ids, link.ID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return res, fmt.Errorf("links blocklist scan: %w", err)
+	}
+
+	ctx = ContextWithAuditMeta(ctx, AuditMeta{Actor: BlocklistActor})
+
+	for _, id := range ids {
+		flagged, err := s.flagLink(ctx, id)
+		if err != nil {
+			return res, err
+		}
+
+		if flagged {
+			res.Flagged++
+		}
+	}
+
+	return res, nil
+}
+
+// flagLink reports false when the link was deleted, disabled or changed to
+// an allowed destination since the scan.
+func (s *Service) flagLink(ctx context.Context, id int64) (bool, error) {
+	var (
+		entry   string
+		flagged bool
+	)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("links get by id: %w", err)
+		}
+
+		var blocked bool
+		if entry, blocked = s.matchBlocklist(before); !blocked || before.Disabled {
+			return nil
+		}
+
+		disabled := before
+		disabled.Disabled = true
+
+		if _, err := s.repo.Update(ctx, disabled, before.Version); err != nil {
+			return fmt.Errorf("links update: %w", err)
+		}
+
+		after, err := s.repo.Flag(ctx, id, entry)
+		if err != nil {
+			return fmt.Errorf("links flag: %w", err)
+		}
+
+		flagged = true
+
+		return s.audit(ctx, domain.AuditActionFlag, id, &before, &after)
+	})
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil || !flagged {
+		return false, err
+	}
+
+	s.log.With("link_id", id, "entry", entry).Warn("link disabled by blocklist")
+
+	return true, nil
+}
+
+// matchBlocklist returns the entry matching the first blocked destination of
+// link.
+func (s *Service) matchBlocklist(link domain.Link) (string, bool) {
+	for _, rawURL := range link.DestinationURLs() {
+		if entry, blocked := s.blocklist.Match(rawURL); blocked {
+			return entry, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *Service) ListFlaggedLinks(ctx context.Context) ([]domain.Link, error) {
+	items, err := s.repo.ListFlagged(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("links list flagged: %w", err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,132 @@
+package links
+
+import (
+	"context"
+	"errors"
+
+	"code/internal/domain"
+)
+
+// MaxBulkItems bounds a single BulkCreate call.
+const MaxBulkItems = 500
+
+type BulkMode string
+
+const (
+	// BulkModeAtomic creates all items or none of them.
+	BulkModeAtomic BulkMode = "atomic"
+	// BulkModeBestEffort creates every valid item independently.
+	BulkModeBestEffort BulkMode = "best_effort"
+)
+
+type BulkItemStatus string
+
+const (
+	BulkItemCreated BulkItemStatus = "created"
+	BulkItemFailed  BulkItemStatus = "failed"
+	// BulkItemSkipped marks valid items of an atomic batch that was rolled back.
+	BulkItemSkipped BulkItemStatus = "skipped"
+)
+
+// BulkItemResult is the outcome of one input item; results keep input order.
+type BulkItemResult struct {
+	Status BulkItemStatus
+	Link   domain.Link
+	Err    error
+}
+
+// errBulkRollback aborts the transaction of a failed atomic batch.
+var errBulkRollback = errors.New("bulk create rolled back")
+
+// BulkCreate creates links through the regular Create path. Item-level
+// failures (validation, conflicts, quota) are reported per item; any other
+// error fails the whole call.
+func (s *Service) BulkCreate(ctx context.Context, items []LinkInput, mode BulkMode) ([]BulkItemResult, error) {
+	if len(items) == 0 || len(items) > MaxBulkItems {
+		return nil, ErrInvalidBatchSize
+	}
+
+	switch mode {
+	case BulkModeAtomic:
+		return s.bulkCreateAtomic(ctx, items)
+	case BulkModeBestEffort:
+		return s.bulkCreateEach(ctx, items)
+	default:
+		return nil, ErrInvalidBulkMode
+	}
+}
+
+func (s *Service) bulkCreateAtomic(ctx context.Context, items []LinkInput) ([]BulkItemResult, error) {
+	var results []BulkItemResult
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		results, err = s.bulkCreateEach(ctx, items)
+		if err != nil {
+			return err
+		}
+
+		for _, res := range results {
+			if res.Status == BulkItemFailed {
+				return errBulkRollback
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, errBulkRollback) {
+		for i := range results {
+			if results[i].Status == BulkItemCreated {
+				results[i] = BulkItemResult{Status: BulkItemSkipped}
+			}
+		}
+
+		return results, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// bulkCreateEach runs every item in its own (nested) transaction, so a failed
+// item never aborts the surrounding one.
+func (s *Service) bulkCreateEach(ctx context.Context, items []LinkInput) ([]BulkItemResult, error) {
+	results := make([]BulkItemResult, len(items))
+
+	for i, item := range items {
+		link, err := s.Create(ctx, item)
+		if err != nil {
+			if !isItemError(err) {
+				return nil, err
+			}
+
+			results[i] = BulkItemResult{Status: BulkItemFailed, Err: err}
+
+			continue
+		}
+
+		results[i] = BulkItemResult{Status: BulkItemCreated, Link: link}
+	}
+
+	return results, nil
+}
+
+func isItemError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidURL) ||
+		errors.Is(err, domain.ErrInvalidShortName) ||
+		errors.Is(err, domain.ErrShortNameConflict) ||
+		errors.Is(err, domain.ErrInvalidTags) ||
+		errors.Is(err, domain.ErrInvalidTitle) ||
+		errors.Is(err, domain.ErrInvalidForwardQuery) ||
+		errors.Is(err, domain.ErrInvalidUTM) ||
+		errors.Is(err, domain.ErrInvalidRedirectRules) ||
+		errors.Is(err, domain.ErrInvalidVariants) ||
+		errors.Is(err, domain.ErrInvalidDomain) ||
+		errors.Is(err, domain.ErrUnknownDomain) ||
+		errors.Is(err, ErrUnknownShortNameStrategy) ||
+		errors.Is(err, ErrLinkQuotaExceeded)
+}
@@ -0,0 +1,69 @@
+package links
+
+import (
+	"context"
+	"fmt"
+)
+
+// BulkDelete deletes every link matching filter in one transaction and
+// returns their IDs. Each deletion is audited like a single Delete.
+func (s *Service) BulkDelete(ctx context.Context, filter LinksFilter) ([]int64, error) {
+	return s.bulkModify(ctx, filter, func(ctx context.Context, id int64) error {
+		return s.Delete(ctx, id, 0)
+	})
+}
+
+// BulkPatch applies patch to every link matching filter in one transaction
+// and returns their IDs. Short names are unique, so the patch cannot set one
+// or ask for new ones.
+func (s *Service) BulkPatch(ctx context.Context, filter LinksFilter, patch LinkPatch) ([]int64, error) {
+	if patch.empty() || patch.ShortName != nil || patch.RegenerateShortName {
+		return nil, ErrInvalidBulkPatch
+	}
+
+	return s.bulkModify(ctx, filter, func(ctx context.Context, id int64) error {
+		_, err := s.Patch(ctx, id, patch, 0)
+
+		return err
+	})
+}
+
+// bulkModify locks the matching links up front so the set cannot change
+// while fn runs on each of them; the first error rolls everything back.
+func (s *Service) bulkModify(
+	ctx context.Context,
+	filter LinksFilter,
+	fn func(ctx context.Context, id int64) error,
+) ([]int64, error) {
+	if filter.empty() {
+		return nil, ErrEmptyBulkFilter
+	}
+
+	if len(filter.IDs) > MaxBulkItems {
+		return nil, ErrInvalidBatchSize
+	}
+
+	var ids []int64
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		ids, err = s.repo.LockMatching(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("links lock matching: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := fn(ctx, id); err != nil {
+				return fmt.Errorf("link %d: %w", id, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
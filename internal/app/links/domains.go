@@ -0,0 +1,108 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+// DomainRouting maps request hosts to link domains. DefaultHost, the BASE_URL
+// host, serves the links without a domain. Hosts that are neither the default
+// nor registered serve the links of Fallback, which is empty for the default
+// domain.
+type DomainRouting struct {
+	DefaultHost string
+	Fallback    string
+}
+
+// domainFor returns the domain whose links are served on host.
+func (s *Service) domainFor(ctx context.Context, host string) (string, error) {
+	host = domain.NormalizeHost(host)
+	if s.domainsRepo == nil || host == s.routing.DefaultHost {
+		return "", nil
+	}
+
+	_, err := s.domainsRepo.GetByHost(ctx, host)
+	switch {
+	case err == nil:
+		return host, nil
+	case errors.Is(err, domain.ErrNotFound):
+		return s.fallbackDomain(), nil
+	default:
+		return "", fmt.Errorf("domains get by host: %w", err)
+	}
+}
+
+func (s *Service) fallbackDomain() string {
+	if s.routing.Fallback == s.routing.DefaultHost {
+		return ""
+	}
+
+	return s.routing.Fallback
+}
+
+func (s *Service) ListDomains(ctx context.Context) ([]domain.Domain, error) {
+	if s.domainsRepo == nil {
+		return nil, errDomainsNil
+	}
+
+	items, err := s.domainsRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("domains list: %w", err)
+	}
+
+	return items, nil
+}
+
+func (s *Service) GetDomain(ctx context.Context, id int64) (domain.Domain, error) {
+	if s.domainsRepo == nil {
+		return domain.Domain{}, errDomainsNil
+	}
+
+	d, err := s.domainsRepo.Get(ctx, id)
+	if err != nil {
+		return domain.Domain{}, fmt.Errorf("domains get: %w", err)
+	}
+
+	return d, nil
+}
+
+// CreateDomain registers host; the BASE_URL host is always served and cannot
+// be registered.
+func (s *Service) CreateDomain(ctx context.Context, host string) (domain.Domain, error) {
+	if s.domainsRepo == nil {
+		return domain.Domain{}, errDomainsNil
+	}
+
+	host = domain.NormalizeHost(host)
+	if err := domain.ValidateHost(host); err != nil {
+		return domain.Domain{}, err
+	}
+
+	if host == s.routing.DefaultHost {
+		return domain.Domain{}, domain.ErrDomainConflict
+	}
+
+	d, err := s.domainsRepo.Create(ctx, host)
+	if err != nil {
+		return domain.Domain{}, fmt.Errorf("domains create: %w", err)
+	}
+
+	return d, nil
+}
+
+// DeleteDomain fails with domain.ErrDomainInUse while links belong to the
+// domain.
+func (s *Service) DeleteDomain(ctx context.Context, id int64) error {
+	if s.domainsRepo == nil {
+		return errDomainsNil
+	}
+
+	if err := s.domainsRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("domains delete: %w", err)
+	}
+
+	return nil
+}
@@ -2,4 +2,16 @@ package links
 
 import "errors"
 
-var ErrInvalidSort = errors.New("invalid sort")
+var (
+	ErrInvalidSort        = errors.New("invalid sort")
+	ErrLinkQuotaExceeded  = errors.New("link quota exceeded")
+	ErrClickQuotaExceeded = errors.New("monthly click quota exceeded")
+	ErrInvalidBatchSize   = errors.New("invalid batch size")
+	ErrInvalidBulkMode    = errors.New("invalid bulk mode")
+	ErrEmptyBulkFilter    = errors.New("bulk filter is empty")
+	ErrInvalidBulkPatch   = errors.New("invalid bulk patch")
+
+	ErrInvalidConflictPolicy    = errors.New("invalid conflict policy")
+	ErrUnknownShortNameStrategy = errors.New("unknown short name strategy")
+	ErrInvalidStatsGroup        = errors.New("invalid stats group")
+)
@@ -0,0 +1,31 @@
+package links
+
+import (
+	"context"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+// ExportLinks streams the links matching filter in sort order to fn without
+// loading the whole table; fn errors abort the export.
+func (s *Service) ExportLinks(ctx context.Context, filter LinksFilter, sort Sort, fn func(domain.Link) error) error {
+	if err := s.repo.StreamAll(ctx, filter, sort, fn); err != nil {
+		return fmt.Errorf("links export: %w", err)
+	}
+
+	return nil
+}
+
+// ExportLinkVisits is the visits counterpart of ExportLinks.
+func (s *Service) ExportLinkVisits(ctx context.Context, sort Sort, fn func(domain.LinkVisit) error) error {
+	if s.visitsRepo == nil {
+		return errVisitsRepoNil
+	}
+
+	if err := s.visitsRepo.StreamAll(ctx, sort, fn); err != nil {
+		return fmt.Errorf("link visits export: %w", err)
+	}
+
+	return nil
+}
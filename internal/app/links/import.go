@@ -0,0 +1,326 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"code/internal/domain"
+)
+
+// MaxImportErrors bounds the per-row errors kept in an ImportResult; rows
+// beyond it are still counted as failed.
+const MaxImportErrors = 10_000
+
+// ConflictPolicy decides what happens to an import row whose short name is
+// already taken.
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictFail      ConflictPolicy = "fail"
+)
+
+type ImportOptions struct {
+	// DryRun validates and resolves conflicts without locking or persisting
+	// anything.
+	DryRun     bool
+	OnConflict ConflictPolicy
+}
+
+// ImportRecord is one parsed input row; Err is set when the row could not be
+// parsed.
+type ImportRecord struct {
+	Line  int
+	Input LinkInput
+	Err   error
+}
+
+// ImportSource yields records until it returns io.EOF. Any other error aborts
+// the import.
+type ImportSource interface {
+	Next() (ImportRecord, error)
+}
+
+type ImportRowError struct {
+	Line  int
+	Input LinkInput
+	Err   error
+}
+
+type ImportResult struct {
+	Rows    int
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+
+	Errors          []ImportRowError
+	ErrorsTruncated bool
+}
+
+// importRowFunc applies one parsed row, counting it in res.
+type importRowFunc func(ctx context.Context, in LinkInput, opts ImportOptions, res *ImportResult) error
+
+// ImportLinks streams records from src through the regular create and update
+// paths, committing rows one by one. A dry run only reads: rows go through
+// the same checks while the links created so far and the quota are tracked
+// in memory, so no lock is held for the length of the upload.
+func (s *Service) ImportLinks(ctx context.Context, src ImportSource, opts ImportOptions) (ImportResult, error) {
+	switch opts.OnConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return ImportResult{}, ErrInvalidConflictPolicy
+	}
+
+	var res ImportResult
+
+	row := s.importRow
+	if opts.DryRun {
+		row = (&importDryRun{s: s, created: map[string]domain.Link{}}).row
+	}
+
+	err := s.importRows(ctx, src, opts, &res, row)
+
+	return res, err
+}
+
+func (s *Service) importRows(
+	ctx context.Context,
+	src ImportSource,
+	opts ImportOptions,
+	res *ImportResult,
+	row importRowFunc,
+) error {
+	for {
+		rec, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("links import read: %w", err)
+		}
+
+		res.Rows++
+
+		if rec.Err != nil {
+			res.fail(rec, rec.Err)
+
+			continue
+		}
+
+		if err := row(ctx, rec.Input, opts, res); err != nil {
+			if !isItemError(err) {
+				return fmt.Errorf("links import line %d: %w", rec.Line, err)
+			}
+
+			res.fail(rec, err)
+		}
+	}
+}
+
+func (s *Service) importRow(ctx context.Context, in LinkInput, opts ImportOptions, res *ImportResult) error {
+	link, err := s.linkFrom(in)
+	if err != nil {
+		return err
+	}
+
+	if link.ShortName != "" {
+		existing, err := s.findByShortName(ctx, link.Domain, link.ShortName)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("links get by short name: %w", err)
+		default:
+			return s.importConflict(ctx, existing, in, opts, res)
+		}
+	}
+
+	if _, err := s.Create(ctx, in); err != nil {
+		return err
+	}
+
+	res.Created++
+
+	return nil
+}
+
+func (s *Service) importConflict(
+	ctx context.Context,
+	existing domain.Link,
+	in LinkInput,
+	opts ImportOptions,
+	res *ImportResult,
+) error {
+	switch opts.OnConflict {
+	case ConflictSkip:
+		res.Skipped++
+
+		return nil
+	case ConflictOverwrite:
+		if _, err := s.Update(ctx, existing.ID, in, 0); err != nil {
+			return err
+		}
+
+		res.Updated++
+
+		return nil
+	default:
+		return domain.ErrShortNameConflict
+	}
+}
+
+// importDryRun checks rows like importRow without writing: the links of
+// earlier rows stand in for the ones they would have created or overwritten,
+// and the quota counts them.
+type importDryRun struct {
+	s *Service
+	// created maps the short names of earlier rows to their links.
+	created map[string]domain.Link
+	links   int64
+	counted bool
+}
+
+func (d *importDryRun) row(ctx context.Context, in LinkInput, opts ImportOptions, res *ImportResult) error {
+	s := d.s
+
+	link, err := s.linkFrom(in)
+	if err != nil {
+		return err
+	}
+
+	key := d.key(link)
+
+	if link.ShortName != "" {
+		existing, ok := d.created[key]
+		if !ok {
+			existing, err = s.findByShortName(ctx, link.Domain, link.ShortName)
+			switch {
+			case err == nil:
+				ok = true
+			case !errors.Is(err, domain.ErrNotFound):
+				return fmt.Errorf("links get by short name: %w", err)
+			}
+		}
+
+		if ok {
+			return d.conflict(ctx, existing, link, opts, res)
+		}
+	}
+
+	if _, err := s.checkNew(ctx, link, in.ShortNameStrategy); err != nil {
+		return err
+	}
+
+	if err := d.checkDomain(ctx, link.Domain); err != nil {
+		return err
+	}
+
+	if err := d.checkQuota(ctx); err != nil {
+		return err
+	}
+
+	if link.ShortName != "" {
+		d.created[key] = link
+	}
+
+	res.Created++
+
+	return nil
+}
+
+func (d *importDryRun) conflict(
+	ctx context.Context,
+	existing, link domain.Link,
+	opts ImportOptions,
+	res *ImportResult,
+) error {
+	switch opts.OnConflict {
+	case ConflictSkip:
+		res.Skipped++
+
+		return nil
+	case ConflictOverwrite:
+		link.ID = existing.ID
+		if err := d.s.checkChanges(ctx, link, &existing); err != nil {
+			return err
+		}
+
+		d.created[d.key(link)] = link
+		res.Updated++
+
+		return nil
+	default:
+		return domain.ErrShortNameConflict
+	}
+}
+
+// key identifies a short name within its domain, folded like the lookups.
+func (d *importDryRun) key(link domain.Link) string {
+	name := link.ShortName
+	if d.s.foldCase {
+		name = strings.ToLower(name)
+	}
+
+	return link.Domain + "/" + name
+}
+
+// checkDomain stands in for the foreign key that rejects unregistered
+// domains on insert.
+func (d *importDryRun) checkDomain(ctx context.Context, host string) error {
+	if host == "" || d.s.domainsRepo == nil {
+		return nil
+	}
+
+	_, err := d.s.domainsRepo.GetByHost(ctx, host)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domain.ErrNotFound):
+		return domain.ErrUnknownDomain
+	default:
+		return fmt.Errorf("domains get by host: %w", err)
+	}
+}
+
+// checkQuota is checkLinkQuota without the lock, counting the links earlier
+// rows would have created.
+func (d *importDryRun) checkQuota(ctx context.Context) error {
+	s := d.s
+	if s.quotaRepo == nil || s.quota.MaxLinks <= 0 {
+		return nil
+	}
+
+	if !d.counted {
+		total, err := s.repo.Count(ctx, LinksFilter{})
+		if err != nil {
+			return fmt.Errorf("links count: %w", err)
+		}
+
+		d.links, d.counted = total, true
+	}
+
+	if d.links >= s.quota.MaxLinks {
+		return ErrLinkQuotaExceeded
+	}
+
+	d.links++
+
+	return nil
+}
+
+func (r *ImportResult) fail(rec ImportRecord, err error) {
+	r.Failed++
+
+	if len(r.Errors) >= MaxImportErrors {
+		r.ErrorsTruncated = true
+
+		return
+	}
+
+	r.Errors = append(r.Errors, ImportRowError{Line: rec.Line, Input: rec.Input, Err: err})
+}
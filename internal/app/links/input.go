@@ -0,0 +1,176 @@
+package links
+
+import (
+	"strings"
+	"time"
+
+	"code/internal/domain"
+)
+
+// LinkInput carries the user-editable fields of a link. An empty ShortName
+// asks for a generated one, drawn with ShortNameStrategy or, when that is
+// empty too, the configured default strategy. Domain is only read on
+// creation; empty selects the BASE_URL domain. A nil or zero ActiveFrom makes
+// the link live right away.
+type LinkInput struct {
+	OriginalURL       string
+	ShortName         string
+	Tags              []string
+	Disabled          bool
+	ShortNameStrategy string
+	Domain            string
+	Title             string
+	ForwardPath       bool
+	ForwardQuery      string
+	UTM               domain.UTM
+	Rules             []domain.RedirectRule
+	Variants          []domain.Variant
+	StickyVariant     bool
+	ActiveFrom        *time.Time
+}
+
+// inputFrom returns the input that would store link unchanged.
+func inputFrom(link domain.Link) LinkInput {
+	return LinkInput{
+		OriginalURL:   link.OriginalURL,
+		ShortName:     link.ShortName,
+		Tags:          link.Tags,
+		Disabled:      link.Disabled,
+		Domain:        link.Domain,
+		Title:         link.Title,
+		ForwardPath:   link.ForwardPath,
+		ForwardQuery:  link.ForwardQuery,
+		UTM:           link.UTM,
+		Rules:         link.Rules,
+		Variants:      link.Variants,
+		StickyVariant: link.StickyVariant,
+		ActiveFrom:    link.ActiveFrom,
+	}
+}
+
+// link normalizes and validates the input.
+func (in LinkInput) link() (domain.Link, error) {
+	link := domain.Link{
+		OriginalURL:  strings.TrimSpace(in.OriginalURL),
+		ShortName:    strings.TrimSpace(in.ShortName),
+		Tags:         domain.NormalizeTags(in.Tags),
+		Disabled:     in.Disabled,
+		Domain:       domain.NormalizeHost(in.Domain),
+		Title:        strings.TrimSpace(in.Title),
+		ForwardPath:  in.ForwardPath,
+		ForwardQuery: strings.TrimSpace(in.ForwardQuery),
+		UTM: domain.UTM{
+			Source:   strings.TrimSpace(in.UTM.Source),
+			Medium:   strings.TrimSpace(in.UTM.Medium),
+			Campaign: strings.TrimSpace(in.UTM.Campaign),
+			Term:     strings.TrimSpace(in.UTM.Term),
+			Content:  strings.TrimSpace(in.UTM.Content),
+		},
+		Rules:         normalizeRules(in.Rules),
+		Variants:      normalizeVariants(in.Variants),
+		StickyVariant: in.StickyVariant,
+		ActiveFrom:    normalizeTime(in.ActiveFrom),
+	}
+
+	if err := domain.ValidateOriginalURL(link.OriginalURL); err != nil {
+		return domain.Link{}, err
+	}
+
+	if link.ShortName != "" {
+		if err := domain.ValidateShortName(link.ShortName); err != nil {
+			return domain.Link{}, err
+		}
+	}
+
+	if err := domain.ValidateTags(link.Tags); err != nil {
+		return domain.Link{}, err
+	}
+
+	if err := domain.ValidateTitle(link.Title); err != nil {
+		return domain.Link{}, err
+	}
+
+	if err := domain.ValidateForwardQuery(link.ForwardQuery); err != nil {
+		return domain.Link{}, err
+	}
+
+	if err := domain.ValidateUTM(link.UTM); err != nil {
+		return domain.Link{}, err
+	}
+
+	if err := domain.ValidateRedirectRules(link.Rules); err != nil {
+		return domain.Link{}, err
+	}
+
+	if err := domain.ValidateVariants(link.Variants); err != nil {
+		return domain.Link{}, err
+	}
+
+	if link.Domain != "" {
+		if err := domain.ValidateHost(link.Domain); err != nil {
+			return domain.Link{}, err
+		}
+	}
+
+	return link, nil
+}
+
+// normalizeRules trims the rules, lowercases their OS and device,
+// uppercases their country and moves their time window to UTC; the result is
+// never nil.
+func normalizeRules(rules []domain.RedirectRule) []domain.RedirectRule {
+	out := make([]domain.RedirectRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, domain.RedirectRule{
+			OS:      strings.ToLower(strings.TrimSpace(rule.OS)),
+			Device:  strings.ToLower(strings.TrimSpace(rule.Device)),
+			Country: strings.ToUpper(strings.TrimSpace(rule.Country)),
+			After:   rule.After.UTC(),
+			Before:  rule.Before.UTC(),
+			URL:     strings.TrimSpace(rule.URL),
+		})
+	}
+
+	return out
+}
+
+// normalizeTime returns t in UTC, or nil for a nil or zero t.
+func normalizeTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+
+	utc := t.UTC()
+
+	return &utc
+}
+
+// normalizeVariants trims the variant URLs; the result is never nil.
+func normalizeVariants(variants []domain.Variant) []domain.Variant {
+	out := make([]domain.Variant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, domain.Variant{URL: strings.TrimSpace(v.URL), Weight: v.Weight})
+	}
+
+	return out
+}
+
+// linkFrom is in.link with the normalized destination filled in; the
+// BASE_URL host is accepted as a spelling of the empty domain.
+func (s *Service) linkFrom(in LinkInput) (domain.Link, error) {
+	if domain.NormalizeHost(in.Domain) == s.routing.DefaultHost {
+		in.Domain = ""
+	}
+
+	link, err := in.link()
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	link.NormalizedURL, err = domain.NormalizeURL(link.OriginalURL, s.urlNorm)
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	return link, nil
+}
@@ -0,0 +1,127 @@
+package links
+
+import (
+	"time"
+
+	"code/internal/domain"
+)
+
+// Option configures optional Service collaborators.
+type Option func(*Service)
+
+// WithTxManager makes multi-step mutations atomic.
+func WithTxManager(tx TxManager) Option {
+	return func(s *Service) {
+		if tx != nil {
+			s.tx = tx
+		}
+	}
+}
+
+// WithQuota enables link and click quotas backed by repo.
+func WithQuota(q Quota, repo QuotaRepo) Option {
+	return func(s *Service) {
+		s.quota = q
+		s.quotaRepo = repo
+	}
+}
+
+// WithAudit records every link mutation through repo.
+func WithAudit(repo AuditRepo) Option {
+	return func(s *Service) {
+		s.auditRepo = repo
+	}
+}
+
+// WithRevisions enables listing and reverting link revisions.
+func WithRevisions(repo RevisionsRepo) Option {
+	return func(s *Service) {
+		s.revisionsRepo = repo
+	}
+}
+
+// WithURLNormalization enables the optional steps used to derive the
+// normalized destination of links.
+func WithURLNormalization(n domain.URLNormalization) Option {
+	return func(s *Service) {
+		s.urlNorm = n
+	}
+}
+
+// WithURLPolicy restricts the destinations of new and changed links.
+func WithURLPolicy(p domain.URLPolicy) Option {
+	return func(s *Service) {
+		s.urlPolicy = p
+	}
+}
+
+// WithShortNamePolicy extends the reserved names rejected for custom and
+// generated short names with p.Denied.
+func WithShortNamePolicy(p domain.ShortNamePolicy) Option {
+	return func(s *Service) {
+		s.shortNames = p
+	}
+}
+
+// WithShortNameGenerator registers gen under strategy, replacing any
+// generator already registered under that name.
+func WithShortNameGenerator(strategy string, gen ShortNameGenerator) Option {
+	return func(s *Service) {
+		s.generators[strategy] = gen
+	}
+}
+
+// WithShortNameStrategy selects the strategy used when a request names none;
+// it defaults to StrategyRandom.
+func WithShortNameStrategy(strategy string) Option {
+	return func(s *Service) {
+		if strategy != "" {
+			s.strategy = strategy
+		}
+	}
+}
+
+// WithCaseInsensitiveShortNames resolves short names in any casing. The
+// store must already keep them unique regardless of case.
+func WithCaseInsensitiveShortNames(enabled bool) Option {
+	return func(s *Service) {
+		s.foldCase = enabled
+	}
+}
+
+// WithDomains serves links on the domains stored in repo besides the BASE_URL
+// one and enables managing them.
+func WithDomains(repo DomainsRepo, routing DomainRouting) Option {
+	return func(s *Service) {
+		s.domainsRepo = repo
+		s.routing = DomainRouting{
+			DefaultHost: domain.NormalizeHost(routing.DefaultHost),
+			Fallback:    domain.NormalizeHost(routing.Fallback),
+		}
+	}
+}
+
+// WithBlocklist rejects new and changed destinations matching bl and enables
+// RescanBlocklist.
+func WithBlocklist(bl Blocklist) Option {
+	return func(s *Service) {
+		s.blocklist = bl
+	}
+}
+
+// WithGeoIP resolves the country of visitors from their IP address for
+// country redirect rules when VisitMeta.Country is empty.
+func WithGeoIP(geo GeoIP) Option {
+	return func(s *Service) {
+		s.geoIP = geo
+	}
+}
+
+// WithClock overrides the time source, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code/internal/domain"
+)
+
+// LinkPatch is a partial update: nil fields keep their current value.
+// Unlike Update, an empty ShortName is rejected; RegenerateShortName asks for
+// a newly generated one instead.
+type LinkPatch struct {
+	OriginalURL         *string
+	ShortName           *string
+	RegenerateShortName bool
+	Tags                *[]string
+	Disabled            *bool
+	Title               *string
+	ForwardPath         *bool
+	ForwardQuery        *string
+	// UTM changes the UTM parameters it sets and keeps the others.
+	UTM *UTMPatch
+	// Rules replaces the whole rule list.
+	Rules *[]domain.RedirectRule
+	// Variants replaces the whole A/B split.
+	Variants      *[]domain.Variant
+	StickyVariant *bool
+	// ActiveFrom set to the zero time makes the link live right away.
+	ActiveFrom *time.Time
+}
+
+// UTMPatch is a partial update of the UTM parameters: nil fields keep their
+// current value and empty ones clear it.
+type UTMPatch struct {
+	Source   *string
+	Medium   *string
+	Campaign *string
+	Term     *string
+	Content  *string
+}
+
+func (p UTMPatch) apply(u domain.UTM) domain.UTM {
+	for _, f := range []struct {
+		patch *string
+		value *string
+	}{
+		{p.Source, &u.Source},
+		{p.Medium, &u.Medium},
+		{p.Campaign, &u.Campaign},
+		{p.Term, &u.Term},
+		{p.Content, &u.Content},
+	} {
+		if f.patch != nil {
+			*f.value = *f.patch
+		}
+	}
+
+	return u
+}
+
+func (p LinkPatch) empty() bool {
+	return p.OriginalURL == nil && p.ShortName == nil && !p.RegenerateShortName && p.Tags == nil && p.Disabled == nil && p.Title == nil &&
+		p.ForwardPath == nil && p.ForwardQuery == nil && p.UTM == nil && p.Rules == nil &&
+		p.Variants == nil && p.StickyVariant == nil && p.ActiveFrom == nil
+}
+
+// Patch merges patch into the stored link and saves it through the regular
+// update path; a non-zero ifVersion must match the stored version.
+func (s *Service) Patch(ctx context.Context, id int64, patch LinkPatch, ifVersion int64) (domain.Link, error) {
+	err := patch.validate()
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	if patch.empty() {
+		link, err := s.Get(ctx, id)
+		if err != nil {
+			return domain.Link{}, err
+		}
+
+		if ifVersion != 0 && link.Version != ifVersion {
+			return domain.Link{}, domain.ErrVersionMismatch
+		}
+
+		return link, nil
+	}
+
+	return s.modify(ctx, domain.AuditActionUpdate, id, ifVersion, patch.apply)
+}
+
+// validate rejects a short name that would be cleared by accident: a merge
+// patch spells regeneration out rather than sending null or "".
+func (p LinkPatch) validate() error {
+	switch {
+	case p.ShortName != nil && p.RegenerateShortName:
+		return fmt.Errorf("%w: set together with regeneration", domain.ErrInvalidShortName)
+	case p.ShortName != nil && strings.TrimSpace(*p.ShortName) == "":
+		return fmt.Errorf("%w: empty in a patch", domain.ErrInvalidShortName)
+	default:
+		return nil
+	}
+}
+
+func (p LinkPatch) apply(current domain.Link) LinkInput {
+	in := inputFrom(current)
+
+	if p.OriginalURL != nil {
+		in.OriginalURL = *p.OriginalURL
+	}
+
+	if p.ShortName != nil {
+		in.ShortName = *p.ShortName
+	}
+
+	if p.RegenerateShortName {
+		in.ShortName = ""
+	}
+
+	if p.Tags != nil {
+		in.Tags = *p.Tags
+	}
+
+	if p.Disabled != nil {
+		in.Disabled = *p.Disabled
+	}
+
+	if p.Title != nil {
+		in.Title = *p.Title
+	}
+
+	if p.ForwardPath != nil {
+		in.ForwardPath = *p.ForwardPath
+	}
+
+	if p.ForwardQuery != nil {
+		in.ForwardQuery = *p.ForwardQuery
+	}
+
+	if p.UTM != nil {
+		in.UTM = p.UTM.apply(in.UTM)
+	}
+
+	if p.Rules != nil {
+		in.Rules = *p.Rules
+	}
+
+	if p.Variants != nil {
+		in.Variants = *p.Variants
+	}
+
+	if p.StickyVariant != nil {
+		in.StickyVariant = *p.StickyVariant
+	}
+
+	if p.ActiveFrom != nil {
+		in.ActiveFrom = p.ActiveFrom
+	}
+
+	return in
+}
+
+// modify derives the new state from the locked current one and saves it via
+// update; a non-zero ifVersion must match the stored version.
+func (s *Service) modify(
+	ctx context.Context,
+	action string,
+	id int64,
+	ifVersion int64,
+	apply func(current domain.Link) LinkInput,
+) (domain.Link, error) {
+	var link domain.Link
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("links get by id: %w", err)
+		}
+
+		if ifVersion != 0 && current.Version != ifVersion {
+			return domain.ErrVersionMismatch
+		}
+
+		// Pinning the version read above keeps the merge atomic even
+		// without a surrounding transaction.
+		link, err = s.update(ctx, action, id, apply(current), current.Version)
+
+		return err
+	})
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	return link, nil
+}
@@ -2,24 +2,106 @@ package links
 
 import (
 	"context"
+	"time"
 
 	"code/internal/domain"
 )
 
 type Repo interface {
-	ListAll(ctx context.Context, sort Sort) ([]domain.Link, error)
-	ListPage(ctx context.Context, offset, limit int32, sort Sort) ([]domain.Link, error)
-	Count(ctx context.Context) (int64, error)
+	ListAll(ctx context.Context, filter LinksFilter, sort Sort) ([]domain.Link, error)
+	ListPage(ctx context.Context, filter LinksFilter, offset, limit int32, sort Sort) ([]domain.Link, error)
+	Count(ctx context.Context, filter LinksFilter) (int64, error)
+	// StreamAll calls fn for every matching link in sort order while reading
+	// from an open cursor, so memory use does not grow with the table. An
+	// error from fn stops the iteration and is returned as is.
+	StreamAll(ctx context.Context, filter LinksFilter, sort Sort, fn func(domain.Link) error) error
 	GetByID(ctx context.Context, id int64) (domain.Link, error)
-	GetByShortName(ctx context.Context, shortName string) (domain.Link, error)
-	Create(ctx context.Context, originalURL, shortName string) (domain.Link, error)
-	Update(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error)
+	// GetByShortName and GetByShortNameFold look up links of one domain;
+	// host is empty for the BASE_URL domain.
+	GetByShortName(ctx context.Context, host, shortName string) (domain.Link, error)
+	// GetByShortNameFold matches shortName ignoring case; an exact match
+	// wins when several links differ only in case.
+	GetByShortNameFold(ctx context.Context, host, shortName string) (domain.Link, error)
+	// GetByNormalizedURL returns the oldest enabled link of the domain with
+	// this normalized destination.
+	GetByNormalizedURL(ctx context.Context, host, normalizedURL string) (domain.Link, error)
+	// Create fails with domain.ErrUnknownDomain when link.Domain is not
+	// registered.
+	Create(ctx context.Context, link domain.Link) (domain.Link, error)
+	// Update stores the mutable fields of link (matched by ID). Update and
+	// Delete fail with domain.ErrVersionMismatch when expectedVersion is
+	// non-zero and differs from the stored version.
+	Update(ctx context.Context, link domain.Link, expectedVersion int64) (domain.Link, error)
+	Delete(ctx context.Context, id int64, expectedVersion int64) error
+	// LockMatching returns the IDs of links matching filter in ascending
+	// order and locks them until the current transaction ends.
+	LockMatching(ctx context.Context, filter LinksFilter) ([]int64, error)
+	// GetByIDForUpdate locks the link until the current transaction ends.
+	GetByIDForUpdate(ctx context.Context, id int64) (domain.Link, error)
+	// Flag marks the link as caught by the blocklist, with the matching entry
+	// as reason; it does not change the version. Update clears the flag when
+	// it enables the link.
+	Flag(ctx context.Context, id int64, reason string) (domain.Link, error)
+	// ListFlagged returns the flagged links, most recently flagged first.
+	ListFlagged(ctx context.Context) ([]domain.Link, error)
+}
+
+// DomainsRepo stores the hosts links can be served on besides the BASE_URL
+// one. Hosts are unique (domain.ErrDomainConflict), and Delete fails with
+// domain.ErrDomainInUse while links belong to the domain.
+type DomainsRepo interface {
+	List(ctx context.Context) ([]domain.Domain, error)
+	Get(ctx context.Context, id int64) (domain.Domain, error)
+	GetByHost(ctx context.Context, host string) (domain.Domain, error)
+	Create(ctx context.Context, host string) (domain.Domain, error)
 	Delete(ctx context.Context, id int64) error
 }
 
+// Blocklist matches destinations that must not be shortened and returns the
+// matching entry. Implementations may change their entries at any time.
+type Blocklist interface {
+	Match(rawURL string) (entry string, ok bool)
+}
+
+// GeoIP resolves the ISO 3166-1 alpha-2 country code of an IP address, or
+// returns "" when it is unknown.
+type GeoIP interface {
+	Country(ip string) string
+}
+
 type VisitsRepo interface {
 	Create(ctx context.Context, visit domain.LinkVisit) (int64, error)
 	ListAll(ctx context.Context, sort Sort) ([]domain.LinkVisit, error)
 	ListPage(ctx context.Context, offset, limit int32, sort Sort) ([]domain.LinkVisit, error)
 	Count(ctx context.Context) (int64, error)
+	CountByLink(ctx context.Context, linkID int64) (int64, error)
+	// Stats counts visits per value of query.GroupBy, most visited first.
+	Stats(ctx context.Context, query VisitStatsQuery) ([]domain.VisitStat, error)
+	// StreamAll is the visits counterpart of Repo.StreamAll.
+	StreamAll(ctx context.Context, sort Sort, fn func(domain.LinkVisit) error) error
+}
+
+// QuotaRepo stores usage counters used for quota enforcement.
+type QuotaRepo interface {
+	// LockLinkQuota serializes link quota checks until the current transaction ends.
+	LockLinkQuota(ctx context.Context) error
+	// IncrementMonthlyClicks atomically bumps the counter for period unless it
+	// already reached limit (limit <= 0 means unlimited) and reports success.
+	IncrementMonthlyClicks(ctx context.Context, period time.Time, limit int64) (bool, error)
+	MonthlyClicks(ctx context.Context, period time.Time) (int64, error)
+}
+
+type AuditRepo interface {
+	Create(ctx context.Context, event domain.AuditEvent) error
+	ListAll(ctx context.Context, filter AuditFilter, sort Sort) ([]domain.AuditEvent, error)
+	ListPage(ctx context.Context, filter AuditFilter, offset, limit int32, sort Sort) ([]domain.AuditEvent, error)
+	Count(ctx context.Context, filter AuditFilter) (int64, error)
+}
+
+// RevisionsRepo reads link revisions; they are written by Repo.Update.
+type RevisionsRepo interface {
+	Get(ctx context.Context, linkID, id int64) (domain.LinkRevision, error)
+	ListAll(ctx context.Context, linkID int64, sort Sort) ([]domain.LinkRevision, error)
+	ListPage(ctx context.Context, linkID int64, offset, limit int32, sort Sort) ([]domain.LinkRevision, error)
+	Count(ctx context.Context, linkID int64) (int64, error)
 }
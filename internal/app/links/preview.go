@@ -0,0 +1,52 @@
+package links
+
+import (
+	"context"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+// LinkPreview is what visitors are shown before following a short link.
+type LinkPreview struct {
+	Link domain.Link
+	// Destinations lists every URL the link can lead to, with its UTM
+	// parameters: the original URL first, then those of rules and variants.
+	Destinations []string
+	// Clicks counts the recorded visits; it is zero without a visits repo.
+	Clicks int64
+}
+
+// Preview resolves shortName like Redirect without recording a visit or
+// counting a click against the quota.
+func (s *Service) Preview(ctx context.Context, host, shortName string) (LinkPreview, error) {
+	link, err := s.GetByShortName(ctx, host, shortName)
+	if err != nil {
+		return LinkPreview{}, err
+	}
+
+	if link.Disabled {
+		return LinkPreview{}, domain.ErrLinkDisabled
+	}
+
+	if !link.ActiveAt(s.now()) {
+		return LinkPreview{}, domain.ErrLinkNotActive
+	}
+
+	dests, err := link.Destinations()
+	if err != nil {
+		return LinkPreview{}, err
+	}
+
+	preview := LinkPreview{Link: link, Destinations: dests}
+	if s.visitsRepo == nil {
+		return preview, nil
+	}
+
+	preview.Clicks, err = s.visitsRepo.CountByLink(ctx, link.ID)
+	if err != nil {
+		return LinkPreview{}, fmt.Errorf("links count visits: %w", err)
+	}
+
+	return preview, nil
+}
@@ -1,11 +1,45 @@
 package links
 
 type LinksQuery struct {
+	Range  *Range
+	Sort   Sort
+	Filter LinksFilter
+}
+
+type LinkVisitsQuery struct {
 	Range *Range
 	Sort  Sort
 }
 
-type LinkVisitsQuery struct {
+// LinksFilter selects links to list or to change in bulk. Set fields are
+// combined with AND; a non-nil IDs matches only those links, even when empty.
+type LinksFilter struct {
+	IDs      []int64
+	Tag      string
+	Disabled *bool
+	// Campaign matches UTM.Campaign exactly.
+	Campaign string
+}
+
+func (f LinksFilter) empty() bool {
+	return f.IDs == nil && f.Tag == "" && f.Disabled == nil && f.Campaign == ""
+}
+
+// AuditFilter narrows audit events; zero fields match everything.
+type AuditFilter struct {
+	Action    string
+	LinkID    int64
+	Actor     string
+	RequestID string
+}
+
+type LinkRevisionsQuery struct {
 	Range *Range
 	Sort  Sort
 }
+
+type AuditQuery struct {
+	Range  *Range
+	Sort   Sort
+	Filter AuditFilter
+}
@@ -0,0 +1,108 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Quota limits the deployment-wide usage. Zero limits mean unlimited.
+type Quota struct {
+	// MaxLinks counts disabled links too: they keep their short name and
+	// can be enabled again at any time, so only deleting frees a slot.
+	MaxLinks         int64
+	MaxMonthlyClicks int64
+	// BlockRedirects fails redirects once the click quota is exhausted
+	// instead of only skipping visit tracking.
+	BlockRedirects bool
+}
+
+type UsageCounter struct {
+	Used  int64
+	Limit int64
+}
+
+type Usage struct {
+	Links         UsageCounter
+	MonthlyClicks UsageCounter
+	Period        time.Time
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkLinkQuota must run inside the same transaction as the insert it guards.
+func (s *Service) checkLinkQuota(ctx context.Context) error {
+	if s.quotaRepo == nil || s.quota.MaxLinks <= 0 {
+		return nil
+	}
+
+	if err := s.quotaRepo.LockLinkQuota(ctx); err != nil {
+		return fmt.Errorf("links lock quota: %w", err)
+	}
+
+	// Disabled links are counted on purpose; see Quota.MaxLinks.
+	total, err := s.repo.Count(ctx, LinksFilter{})
+	if err != nil {
+		return fmt.Errorf("links count: %w", err)
+	}
+
+	if total >= s.quota.MaxLinks {
+		return ErrLinkQuotaExceeded
+	}
+
+	return nil
+}
+
+// consumeClick reserves one tracked click for the current month and
+// reports whether the visit may be recorded. Without a click limit there is
+// nothing to reserve, so the counter is left alone.
+func (s *Service) consumeClick(ctx context.Context, shortName string) (bool, error) {
+	if s.quotaRepo == nil || s.quota.MaxMonthlyClicks <= 0 {
+		return true, nil
+	}
+
+	ok, err := s.quotaRepo.IncrementMonthlyClicks(ctx, monthStart(s.now()), s.quota.MaxMonthlyClicks)
+	if err != nil {
+		s.log.With("code", shortName).Warn("click quota increment failed", "err", err)
+
+		return false, nil
+	}
+
+	if !ok && s.quota.BlockRedirects {
+		return false, ErrClickQuotaExceeded
+	}
+
+	return ok, nil
+}
+
+func (s *Service) Usage(ctx context.Context) (Usage, error) {
+	period := monthStart(s.now())
+
+	links, err := s.repo.Count(ctx, LinksFilter{})
+	if err != nil {
+		return Usage{}, fmt.Errorf("links count: %w", err)
+	}
+
+	usage := Usage{
+		Links:         UsageCounter{Used: links, Limit: s.quota.MaxLinks},
+		MonthlyClicks: UsageCounter{Limit: s.quota.MaxMonthlyClicks},
+		Period:        period,
+	}
+
+	if s.quotaRepo == nil {
+		return usage, nil
+	}
+
+	clicks, err := s.quotaRepo.MonthlyClicks(ctx, period)
+	if err != nil {
+		return Usage{}, fmt.Errorf("links monthly clicks: %w", err)
+	}
+
+	usage.MonthlyClicks.Used = clicks
+
+	return usage, nil
+}
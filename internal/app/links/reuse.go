@@ -0,0 +1,65 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"code/internal/domain"
+)
+
+// CreateOrReuse returns the oldest enabled link of the same domain to the same
+// normalized destination instead of minting another short name, and reports whether a
+// link was created. A reused link is returned as stored, whatever the tags of
+// in, but only if its UTM parameters, redirect rules, variants and activation
+// time match; an explicit short name always goes through Create.
+func (s *Service) CreateOrReuse(ctx context.Context, in LinkInput) (domain.Link, bool, error) {
+	if strings.TrimSpace(in.ShortName) != "" {
+		link, err := s.Create(ctx, in)
+
+		return link, err == nil, err
+	}
+
+	link, err := s.linkFrom(in)
+	if err != nil {
+		return domain.Link{}, false, err
+	}
+
+	if err := s.checkDestinations(ctx, link); err != nil {
+		return domain.Link{}, false, err
+	}
+
+	existing, err := s.repo.GetByNormalizedURL(ctx, link.Domain, link.NormalizedURL)
+	switch {
+	case err == nil && sameRouting(existing, link):
+		return existing, false, nil
+	case err != nil && !errors.Is(err, domain.ErrNotFound):
+		return domain.Link{}, false, fmt.Errorf("links get by normalized url: %w", err)
+	}
+
+	link, err = s.Create(ctx, in)
+
+	return link, err == nil, err
+}
+
+// sameRouting reports whether a and b send every visitor to the same place.
+func sameRouting(a, b domain.Link) bool {
+	return a.ForwardPath == b.ForwardPath &&
+		a.ForwardQuery == b.ForwardQuery &&
+		a.UTM == b.UTM &&
+		slices.Equal(a.Rules, b.Rules) &&
+		slices.Equal(a.Variants, b.Variants) &&
+		a.StickyVariant == b.StickyVariant &&
+		sameTime(a.ActiveFrom, b.ActiveFrom)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
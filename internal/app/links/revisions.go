@@ -0,0 +1,72 @@
+package links
+
+import (
+	"context"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+func (s *Service) ListLinkRevisions(
+	ctx context.Context,
+	linkID int64,
+	query LinkRevisionsQuery,
+) ([]domain.LinkRevision, int64, error) {
+	if s.revisionsRepo == nil {
+		return nil, 0, errRevisionsNil
+	}
+
+	// Distinguish an unknown link from a link that was never edited.
+	if _, err := s.Get(ctx, linkID); err != nil {
+		return nil, 0, err
+	}
+
+	if query.Range == nil {
+		items, err := s.revisionsRepo.ListAll(ctx, linkID, query.Sort)
+		if err != nil {
+			return nil, 0, fmt.Errorf("link revisions list all: %w", err)
+		}
+
+		return items, -1, nil
+	}
+
+	items, err := s.revisionsRepo.ListPage(
+		ctx,
+		linkID,
+		int32(query.Range.Start),
+		int32(query.Range.Count),
+		query.Sort,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("link revisions list page: %w", err)
+	}
+
+	total, err := s.revisionsRepo.Count(ctx, linkID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("link revisions count: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// RevertLink re-applies an older revision through the regular update path,
+// so the revert itself is validated, audited and becomes a new revision.
+func (s *Service) RevertLink(ctx context.Context, id, revisionID int64) (domain.Link, error) {
+	if s.revisionsRepo == nil {
+		return domain.Link{}, errRevisionsNil
+	}
+
+	rev, err := s.revisionsRepo.Get(ctx, id, revisionID)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("link revisions get: %w", err)
+	}
+
+	// Revisions do not track tags, so the current ones are kept.
+	return s.modify(ctx, domain.AuditActionRevert, id, 0, func(current domain.Link) LinkInput {
+		in := inputFrom(current)
+		in.OriginalURL = rev.OriginalURL
+		in.ShortName = rev.ShortName
+
+		return in
+	})
+}
@@ -2,9 +2,11 @@ package links
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,36 +15,77 @@ import (
 
 const (
 	autoShortNameAttempts = 5
-	shortNameLen          = 8
+	// generateAttempts bounds redraws of generated names rejected by the
+	// short name policy; only a denylist of very short words gets close.
+	generateAttempts = 100
 
 	createErrWrapFmt = "links create: %w"
 
-	shortNameAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-
 	redirectStatusFound = 302
 )
 
-var errVisitsRepoNil = errors.New("link visits repo is nil")
+var (
+	errVisitsRepoNil = errors.New("link visits repo is nil")
+	errAuditRepoNil  = errors.New("audit repo is nil")
+	errRevisionsNil  = errors.New("link revisions repo is nil")
+	errDomainsNil    = errors.New("domains repo is nil")
+
+	errGeneratedShortNameDenied = errors.New("every generated short name was denied")
+)
 
 type Service struct {
 	repo       Repo
 	visitsRepo VisitsRepo
 	log        Logger
+
+	tx            TxManager
+	quota         Quota
+	quotaRepo     QuotaRepo
+	auditRepo     AuditRepo
+	revisionsRepo RevisionsRepo
+	urlNorm       domain.URLNormalization
+	urlPolicy     domain.URLPolicy
+	shortNames    domain.ShortNamePolicy
+	blocklist     Blocklist
+	geoIP         GeoIP
+	generators    map[string]ShortNameGenerator
+	strategy      string
+	foldCase      bool
+	domainsRepo   DomainsRepo
+	routing       DomainRouting
+	now           func() time.Time
 }
 
-func New(repo Repo, visitsRepo VisitsRepo, log Logger) *Service {
+func New(repo Repo, visitsRepo VisitsRepo, log Logger, opts ...Option) *Service {
 	if log == nil {
 		log = NopLogger{}
 	}
 
-	return &Service{repo: repo, visitsRepo: visitsRepo, log: log}
+	s := &Service{
+		repo:       repo,
+		visitsRepo: visitsRepo,
+		log:        log,
+		tx:         NopTxManager{},
+		generators: map[string]ShortNameGenerator{
+			StrategyRandom: RandomGenerator{Length: DefaultShortNameLength, Alphabet: DefaultShortNameAlphabet},
+			StrategyWords:  WordsGenerator{},
+		},
+		strategy: StrategyRandom,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 var _ UseCase = (*Service)(nil)
 
 func (s *Service) ListLinks(ctx context.Context, query LinksQuery) ([]domain.Link, int64, error) {
 	if query.Range == nil {
-		items, err := s.repo.ListAll(ctx, query.Sort)
+		items, err := s.repo.ListAll(ctx, query.Filter, query.Sort)
 		if err != nil {
 			return nil, 0, fmt.Errorf("links list all: %w", err)
 		}
@@ -50,12 +93,12 @@ func (s *Service) ListLinks(ctx context.Context, query LinksQuery) ([]domain.Lin
 		return items, -1, nil
 	}
 
-	items, err := s.repo.ListPage(ctx, int32(query.Range.Start), int32(query.Range.Count), query.Sort)
+	items, err := s.repo.ListPage(ctx, query.Filter, int32(query.Range.Start), int32(query.Range.Count), query.Sort)
 	if err != nil {
 		return nil, 0, fmt.Errorf("links list page: %w", err)
 	}
 
-	total, err := s.repo.Count(ctx)
+	total, err := s.repo.Count(ctx, query.Filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("links count: %w", err)
 	}
@@ -72,13 +115,18 @@ func (s *Service) Get(ctx context.Context, id int64) (domain.Link, error) {
 	return link, nil
 }
 
-func (s *Service) GetByShortName(ctx context.Context, shortName string) (domain.Link, error) {
+func (s *Service) GetByShortName(ctx context.Context, host, shortName string) (domain.Link, error) {
 	err := domain.ValidateShortName(shortName)
 	if err != nil {
 		return domain.Link{}, err
 	}
 
-	link, err := s.repo.GetByShortName(ctx, shortName)
+	linkDomain, err := s.domainFor(ctx, host)
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	link, err := s.findByShortName(ctx, linkDomain, shortName)
 	if err != nil {
 		return domain.Link{}, fmt.Errorf("links get by short name: %w", err)
 	}
@@ -86,22 +134,84 @@ func (s *Service) GetByShortName(ctx context.Context, shortName string) (domain.
 	return link, nil
 }
 
-func (s *Service) Redirect(ctx context.Context, shortName string, meta VisitMeta) (string, int, error) {
-	link, err := s.GetByShortName(ctx, shortName)
+// findByShortName ignores case in case-insensitive mode.
+func (s *Service) findByShortName(ctx context.Context, linkDomain, shortName string) (domain.Link, error) {
+	if s.foldCase {
+		return s.repo.GetByShortNameFold(ctx, linkDomain, shortName)
+	}
+
+	return s.repo.GetByShortName(ctx, linkDomain, shortName)
+}
+
+func (s *Service) Redirect(ctx context.Context, host, shortName string, meta VisitMeta) (Redirection, error) {
+	link, err := s.GetByShortName(ctx, host, shortName)
 	if err != nil {
-		return "", 0, err
+		return Redirection{}, err
 	}
 
-	status := redirectStatusFound
+	if link.Disabled {
+		return Redirection{}, domain.ErrLinkDisabled
+	}
 
-	if s.visitsRepo != nil {
+	now := s.now().UTC()
+	if !link.ActiveAt(now) {
+		return Redirection{}, domain.ErrLinkNotActive
+	}
+
+	os, device := domain.ParseUserAgent(meta.UserAgent)
+	routed, rule := link.Route(domain.Visitor{
+		OS:      os,
+		Device:  device,
+		Country: s.visitorCountry(link, meta),
+		Time:    now,
+	})
+
+	var variant *int
+	if rule < 0 && len(link.Variants) > 0 {
+		i := pickVariant(link, meta.Variant)
+		routed = link.Variants[i].URL
+		variant = &i
+	}
+
+	dest, err := link.UTM.Apply(routed)
+	if err != nil {
+		return Redirection{}, err
+	}
+
+	target, err := link.Forward(dest, meta.ExtraPath, meta.RawQuery)
+	if err != nil {
+		return Redirection{}, err
+	}
+
+	redirection := Redirection{
+		URL:           target,
+		Status:        redirectStatusFound,
+		Variant:       variant,
+		StickyVariant: link.StickyVariant && variant != nil,
+	}
+
+	if s.visitsRepo == nil {
+		return redirection, nil
+	}
+
+	track, err := s.consumeClick(ctx, shortName)
+	if err != nil {
+		return Redirection{}, err
+	}
+
+	if track {
 		visit := domain.LinkVisit{
 			LinkID:    link.ID,
-			CreatedAt: time.Now().UTC(),
+			CreatedAt: now,
 			IP:        meta.IP,
 			UserAgent: meta.UserAgent,
 			Referer:   meta.Referer,
-			Status:    status,
+			Status:    redirection.Status,
+			Path:      meta.Path,
+			Variant:   variant,
+		}
+		if rule >= 0 {
+			visit.Rule = &rule
 		}
 
 		if _, err := s.visitsRepo.Create(ctx, visit); err != nil {
@@ -112,69 +222,327 @@ func (s *Service) Redirect(ctx context.Context, shortName string, meta VisitMeta
 		}
 	}
 
-	return link.OriginalURL, status, nil
+	return redirection, nil
 }
 
-func (s *Service) Create(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
-	originalURL = strings.TrimSpace(originalURL)
-	shortName = strings.TrimSpace(shortName)
+// visitorCountry prefers the country given by the caller and only looks up
+// the IP address when a rule of the link needs it.
+func (s *Service) visitorCountry(link domain.Link, meta VisitMeta) string {
+	if meta.Country != "" || s.geoIP == nil || !link.HasCountryRules() {
+		return meta.Country
+	}
 
-	if err := domain.ValidateOriginalURL(originalURL); err != nil {
-		return domain.Link{}, err
+	return s.geoIP.Country(meta.IP)
+}
+
+// pickVariant keeps a sticky link on the remembered variant while it still
+// exists and has a weight, and draws one by weight otherwise.
+func pickVariant(link domain.Link, remembered *int) int {
+	if link.StickyVariant && remembered != nil {
+		i := *remembered
+		if i >= 0 && i < len(link.Variants) && link.Variants[i].Weight > 0 {
+			return i
+		}
 	}
 
-	if shortName == "" {
-		return s.createWithGeneratedShortName(ctx, originalURL)
+	return link.VariantAt(rand.IntN(link.VariantsWeight()))
+}
+
+func (s *Service) Create(ctx context.Context, in LinkInput) (domain.Link, error) {
+	link, err := s.linkFrom(in)
+	if err != nil {
+		return domain.Link{}, err
 	}
 
-	if err := domain.ValidateShortName(shortName); err != nil {
+	gen, err := s.checkNew(ctx, link, in.ShortNameStrategy)
+	if err != nil {
 		return domain.Link{}, err
 	}
 
-	link, err := s.repo.Create(ctx, originalURL, shortName)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkLinkQuota(ctx); err != nil {
+			return err
+		}
+
+		var err error
+		if link.ShortName == "" {
+			link, err = s.createWithGeneratedShortName(ctx, link, gen)
+		} else {
+			link, err = s.repo.Create(ctx, link)
+			if err != nil {
+				err = fmt.Errorf(createErrWrapFmt, err)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, domain.AuditActionCreate, link.ID, nil, &link)
+	})
 	if err != nil {
-		return domain.Link{}, fmt.Errorf(createErrWrapFmt, err)
+		return domain.Link{}, err
 	}
 
 	return link, nil
 }
 
-func (s *Service) Update(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
-	originalURL = strings.TrimSpace(originalURL)
-	shortName = strings.TrimSpace(shortName)
+// checkNew runs the checks of Create that need no transaction and returns the
+// generator for a link without a short name.
+func (s *Service) checkNew(ctx context.Context, link domain.Link, strategy string) (ShortNameGenerator, error) {
+	var (
+		gen ShortNameGenerator
+		err error
+	)
+
+	if link.ShortName == "" {
+		gen, err = s.generator(strategy)
+	} else {
+		err = s.shortNames.Check(link.ShortName)
+	}
 
-	if err := domain.ValidateOriginalURL(originalURL); err != nil {
-		return domain.Link{}, err
+	if err != nil {
+		return nil, err
 	}
 
-	if shortName == "" {
-		return s.updateWithGeneratedShortName(ctx, id, originalURL)
+	if err := s.checkDestinations(ctx, link); err != nil {
+		return nil, err
 	}
 
-	if err := domain.ValidateShortName(shortName); err != nil {
+	return gen, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int64, in LinkInput, ifVersion int64) (domain.Link, error) {
+	return s.update(ctx, domain.AuditActionUpdate, id, in, ifVersion)
+}
+
+// update replaces the mutable fields of the link; a non-zero ifVersion makes
+// it conditional on the stored link version.
+func (s *Service) update(
+	ctx context.Context,
+	action string,
+	id int64,
+	in LinkInput,
+	ifVersion int64,
+) (domain.Link, error) {
+	link, err := s.linkFrom(in)
+	if err != nil {
 		return domain.Link{}, err
 	}
 
-	link, err := s.repo.Update(ctx, id, originalURL, shortName)
+	link.ID = id
+
+	var gen ShortNameGenerator
+	if link.ShortName == "" {
+		gen, err = s.generator(in.ShortNameStrategy)
+		if err != nil {
+			return domain.Link{}, err
+		}
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lockForAudit(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.checkChanges(ctx, link, before); err != nil {
+			return err
+		}
+
+		if link.ShortName == "" {
+			link, err = s.updateWithGeneratedShortName(ctx, link, gen, ifVersion)
+		} else {
+			link, err = s.repo.Update(ctx, link, ifVersion)
+			if err != nil {
+				err = fmt.Errorf("links update: %w", err)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, action, id, before, &link)
+	})
 	if err != nil {
-		return domain.Link{}, fmt.Errorf("links update: %w", err)
+		return domain.Link{}, err
 	}
 
 	return link, nil
 }
 
+// lockForAudit captures the state before a mutation; it is a no-op when
+// auditing is disabled.
+func (s *Service) lockForAudit(ctx context.Context, id int64) (*domain.Link, error) {
+	if s.auditRepo == nil {
+		return nil, nil
+	}
+
+	link, err := s.repo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("links get by id: %w", err)
+	}
+
+	return &link, nil
+}
+
+// checkDestination applies the URL policy, the registered domains and the
+// blocklist to a new destination.
+func (s *Service) checkDestination(ctx context.Context, rawURL string) error {
+	if err := s.urlPolicy.Check(rawURL); err != nil {
+		return err
+	}
+
+	if err := s.checkServedHost(ctx, rawURL); err != nil {
+		return err
+	}
+
+	if s.blocklist == nil {
+		return nil
+	}
+
+	if _, blocked := s.blocklist.Match(rawURL); blocked {
+		return domain.ErrURLBlocked
+	}
+
+	return nil
+}
+
+// checkServedHost rejects destinations on a registered domain: like the
+// configured self hosts, they serve short links and would chain or loop.
+func (s *Service) checkServedHost(ctx context.Context, rawURL string) error {
+	if s.domainsRepo == nil {
+		return nil
+	}
+
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return domain.ErrInvalidURL
+	}
+
+	_, err = s.domainsRepo.GetByHost(ctx, domain.NormalizeHost(u.Hostname()))
+	switch {
+	case err == nil:
+		return domain.ErrURLSelfReference
+	case errors.Is(err, domain.ErrNotFound):
+		return nil
+	default:
+		return fmt.Errorf("domains get by host: %w", err)
+	}
+}
+
+// checkDestinations applies checkDestination to the destination, rule and
+// variant URLs of link.
+func (s *Service) checkDestinations(ctx context.Context, link domain.Link) error {
+	if err := s.checkDestination(ctx, link.OriginalURL); err != nil {
+		return err
+	}
+
+	for _, rule := range link.Rules {
+		if err := s.checkDestination(ctx, rule.URL); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidRedirectRules, err)
+		}
+	}
+
+	for _, variant := range link.Variants {
+		if err := s.checkDestination(ctx, variant.URL); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidVariants, err)
+		}
+	}
+
+	return nil
+}
+
+// checkChanges applies the short name policy and checkDestination to the
+// fields an update changes. Stored values are not re-checked, so links
+// created before the policies tightened can still be disabled or retagged.
+func (s *Service) checkChanges(ctx context.Context, link domain.Link, before *domain.Link) error {
+	var nameErr error
+	if link.ShortName != "" {
+		nameErr = s.shortNames.Check(link.ShortName)
+	}
+
+	checkURL := s.urlPolicy.Enabled() || s.blocklist != nil || s.domainsRepo != nil
+	if nameErr == nil && !checkURL {
+		return nil
+	}
+
+	if before == nil {
+		current, err := s.repo.GetByIDForUpdate(ctx, link.ID)
+		if err != nil {
+			return fmt.Errorf("links get by id: %w", err)
+		}
+
+		before = &current
+	}
+
+	if nameErr != nil && before.ShortName != link.ShortName {
+		return nameErr
+	}
+
+	if !checkURL {
+		return nil
+	}
+
+	if before.OriginalURL != link.OriginalURL {
+		if err := s.checkDestination(ctx, link.OriginalURL); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range link.Rules {
+		kept := slices.ContainsFunc(before.Rules, func(r domain.RedirectRule) bool {
+			return r.URL == rule.URL
+		})
+		if kept {
+			continue
+		}
+
+		if err := s.checkDestination(ctx, rule.URL); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidRedirectRules, err)
+		}
+	}
+
+	for _, variant := range link.Variants {
+		kept := slices.ContainsFunc(before.Variants, func(v domain.Variant) bool {
+			return v.URL == variant.URL
+		})
+		if kept {
+			continue
+		}
+
+		if err := s.checkDestination(ctx, variant.URL); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidVariants, err)
+		}
+	}
+
+	return nil
+}
+
 func (s *Service) updateWithGeneratedShortName(
 	ctx context.Context,
-	id int64,
-	originalURL string,
+	link domain.Link,
+	gen ShortNameGenerator,
+	ifVersion int64,
 ) (domain.Link, error) {
 	for range autoShortNameAttempts {
-		gen, err := generateShortName()
+		name, err := s.generateShortName(ctx, gen)
 		if err != nil {
 			return domain.Link{}, fmt.Errorf("links generate short name: %w", err)
 		}
 
-		link, err := s.repo.Update(ctx, id, originalURL, gen)
+		candidate := link
+		candidate.ShortName = name
+
+		var updated domain.Link
+
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			updated, err = s.repo.Update(ctx, candidate, ifVersion)
+
+			return err
+		})
 		if errors.Is(err, domain.ErrShortNameConflict) {
 			continue
 		}
@@ -183,18 +551,25 @@ func (s *Service) updateWithGeneratedShortName(
 			return domain.Link{}, fmt.Errorf("links update: %w", err)
 		}
 
-		return link, nil
+		return updated, nil
 	}
 
 	return domain.Link{}, domain.ErrShortNameConflict
 }
 
-func (s *Service) Delete(ctx context.Context, id int64) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("links delete: %w", err)
-	}
+func (s *Service) Delete(ctx context.Context, id int64, ifVersion int64) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.lockForAudit(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if err := s.repo.Delete(ctx, id, ifVersion); err != nil {
+			return fmt.Errorf("links delete: %w", err)
+		}
+
+		return s.audit(ctx, domain.AuditActionDelete, id, before, nil)
+	})
 }
 
 func (s *Service) ListLinkVisits(ctx context.Context, query LinkVisitsQuery) ([]domain.LinkVisit, int64, error) {
@@ -226,15 +601,28 @@ func (s *Service) ListLinkVisits(ctx context.Context, query LinkVisitsQuery) ([]
 
 func (s *Service) createWithGeneratedShortName(
 	ctx context.Context,
-	originalURL string,
+	link domain.Link,
+	gen ShortNameGenerator,
 ) (domain.Link, error) {
 	for range autoShortNameAttempts {
-		gen, err := generateShortName()
+		name, err := s.generateShortName(ctx, gen)
 		if err != nil {
 			return domain.Link{}, fmt.Errorf("links generate short name: %w", err)
 		}
 
-		link, err := s.repo.Create(ctx, originalURL, gen)
+		candidate := link
+		candidate.ShortName = name
+
+		// Each attempt runs in its own savepoint: a unique violation
+		// must not abort the surrounding transaction.
+		var created domain.Link
+
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			created, err = s.repo.Create(ctx, candidate)
+
+			return err
+		})
 		if errors.Is(err, domain.ErrShortNameConflict) {
 			continue
 		}
@@ -243,39 +631,39 @@ func (s *Service) createWithGeneratedShortName(
 			return domain.Link{}, fmt.Errorf(createErrWrapFmt, err)
 		}
 
-		return link, nil
+		return created, nil
 	}
 
 	return domain.Link{}, domain.ErrShortNameConflict
 }
 
-func generateShortName() (string, error) {
-	alphaLen := len(shortNameAlphabet)
-	cutoff := (256 / alphaLen) * alphaLen
+// generator resolves a short name strategy; empty selects the default.
+func (s *Service) generator(strategy string) (ShortNameGenerator, error) {
+	if strategy == "" {
+		strategy = s.strategy
+	}
+
+	gen, ok := s.generators[strategy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownShortNameStrategy, strategy)
+	}
 
-	out := make([]byte, shortNameLen)
-	filled := 0
+	return gen, nil
+}
 
-	var buf [32]byte
-	for filled < shortNameLen {
-		_, err := rand.Read(buf[:])
+// generateShortName draws names from gen until one passes the short name
+// policy.
+func (s *Service) generateShortName(ctx context.Context, gen ShortNameGenerator) (string, error) {
+	for range generateAttempts {
+		name, err := gen.Generate(ctx)
 		if err != nil {
-			return "", fmt.Errorf("rand read: %w", err)
+			return "", err
 		}
 
-		for _, b := range buf {
-			if filled >= shortNameLen {
-				break
-			}
-
-			if int(b) >= cutoff {
-				continue
-			}
-
-			out[filled] = shortNameAlphabet[int(b)%alphaLen]
-			filled++
+		if s.shortNames.Check(name) == nil {
+			return name, nil
 		}
 	}
 
-	return string(out), nil
+	return "", errGeneratedShortNameDenied
 }
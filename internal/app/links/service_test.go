@@ -3,7 +3,9 @@ package links
 import (
 	"context"
 	"errors"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -13,23 +15,71 @@ import (
 type stubRepo struct {
 	t testing.TB
 
-	listAllFunc        func(context.Context, Sort) ([]domain.Link, error)
-	listPageFunc       func(context.Context, int32, int32, Sort) ([]domain.Link, error)
-	countFunc          func(context.Context) (int64, error)
-	getByIDFunc        func(context.Context, int64) (domain.Link, error)
-	getByShortNameFunc func(context.Context, string) (domain.Link, error)
-	createFunc         func(context.Context, string, string) (domain.Link, error)
-	updateFunc         func(context.Context, int64, string, string) (domain.Link, error)
-	deleteFunc         func(context.Context, int64) error
+	listAllFunc         func(context.Context, LinksFilter, Sort) ([]domain.Link, error)
+	listPageFunc        func(context.Context, LinksFilter, int32, int32, Sort) ([]domain.Link, error)
+	countFunc           func(context.Context, LinksFilter) (int64, error)
+	streamAllFunc       func(context.Context, LinksFilter, Sort, func(domain.Link) error) error
+	getByIDFunc         func(context.Context, int64) (domain.Link, error)
+	getByShortNameFunc  func(context.Context, string, string) (domain.Link, error)
+	getByFoldFunc       func(context.Context, string, string) (domain.Link, error)
+	getByNormalizedFunc func(context.Context, string, string) (domain.Link, error)
+	createFunc          func(context.Context, string, string) (domain.Link, error)
+	updateFunc          func(context.Context, int64, string, string, int64) (domain.Link, error)
+	deleteFunc          func(context.Context, int64, int64) error
+	getForUpdateFunc    func(context.Context, int64) (domain.Link, error)
+	lockMatchingFunc    func(context.Context, LinksFilter) ([]int64, error)
+	flagFunc            func(context.Context, int64, string) (domain.Link, error)
+	listFlaggedFunc     func(context.Context) ([]domain.Link, error)
+}
+
+type stubQuotaRepo struct {
+	t testing.TB
+
+	lockFunc      func(context.Context) error
+	incrementFunc func(context.Context, time.Time, int64) (bool, error)
+	clicksFunc    func(context.Context, time.Time) (int64, error)
+}
+
+func (s *stubQuotaRepo) LockLinkQuota(ctx context.Context) error {
+	s.t.Helper()
+
+	if s.lockFunc == nil {
+		s.t.Fatalf("unexpected LockLinkQuota call")
+	}
+
+	return s.lockFunc(ctx)
+}
+
+func (s *stubQuotaRepo) IncrementMonthlyClicks(ctx context.Context, period time.Time, limit int64) (bool, error) {
+	s.t.Helper()
+
+	if s.incrementFunc == nil {
+		s.t.Fatalf("unexpected IncrementMonthlyClicks call")
+	}
+
+	return s.incrementFunc(ctx, period, limit)
+}
+
+func (s *stubQuotaRepo) MonthlyClicks(ctx context.Context, period time.Time) (int64, error) {
+	s.t.Helper()
+
+	if s.clicksFunc == nil {
+		s.t.Fatalf("unexpected MonthlyClicks call")
+	}
+
+	return s.clicksFunc(ctx, period)
 }
 
 type stubVisitsRepo struct {
 	t testing.TB
 
-	createFunc   func(context.Context, domain.LinkVisit) (int64, error)
-	listAllFunc  func(context.Context, Sort) ([]domain.LinkVisit, error)
-	listPageFunc func(context.Context, int32, int32, Sort) ([]domain.LinkVisit, error)
-	countFunc    func(context.Context) (int64, error)
+	createFunc      func(context.Context, domain.LinkVisit) (int64, error)
+	listAllFunc     func(context.Context, Sort) ([]domain.LinkVisit, error)
+	listPageFunc    func(context.Context, int32, int32, Sort) ([]domain.LinkVisit, error)
+	countFunc       func(context.Context) (int64, error)
+	streamAllFunc   func(context.Context, Sort, func(domain.LinkVisit) error) error
+	countByLinkFunc func(context.Context, int64) (int64, error)
+	statsFunc       func(context.Context, VisitStatsQuery) ([]domain.VisitStat, error)
 }
 
 func (s *stubVisitsRepo) Create(ctx context.Context, visit domain.LinkVisit) (int64, error) {
@@ -72,34 +122,74 @@ func (s *stubVisitsRepo) Count(ctx context.Context) (int64, error) {
 	return s.countFunc(ctx)
 }
 
-func (s *stubRepo) ListAll(ctx context.Context, sort Sort) ([]domain.Link, error) {
+func (s *stubVisitsRepo) CountByLink(ctx context.Context, linkID int64) (int64, error) {
+	s.t.Helper()
+
+	if s.countByLinkFunc == nil {
+		s.t.Fatalf("unexpected CountByLink call")
+	}
+
+	return s.countByLinkFunc(ctx, linkID)
+}
+
+func (s *stubVisitsRepo) Stats(ctx context.Context, query VisitStatsQuery) ([]domain.VisitStat, error) {
+	s.t.Helper()
+
+	if s.statsFunc == nil {
+		s.t.Fatalf("unexpected Stats call")
+	}
+
+	return s.statsFunc(ctx, query)
+}
+
+func (s *stubVisitsRepo) StreamAll(ctx context.Context, sort Sort, fn func(domain.LinkVisit) error) error {
+	s.t.Helper()
+
+	if s.streamAllFunc == nil {
+		s.t.Fatalf("unexpected StreamAll call")
+	}
+
+	return s.streamAllFunc(ctx, sort, fn)
+}
+
+func (s *stubRepo) ListAll(ctx context.Context, filter LinksFilter, sort Sort) ([]domain.Link, error) {
 	s.t.Helper()
 
 	if s.listAllFunc == nil {
 		s.t.Fatalf("unexpected ListAll call")
 	}
 
-	return s.listAllFunc(ctx, sort)
+	return s.listAllFunc(ctx, filter, sort)
 }
 
-func (s *stubRepo) ListPage(ctx context.Context, offset, limit int32, sort Sort) ([]domain.Link, error) {
+func (s *stubRepo) ListPage(ctx context.Context, filter LinksFilter, offset, limit int32, sort Sort) ([]domain.Link, error) {
 	s.t.Helper()
 
 	if s.listPageFunc == nil {
 		s.t.Fatalf("unexpected ListPage call")
 	}
 
-	return s.listPageFunc(ctx, offset, limit, sort)
+	return s.listPageFunc(ctx, filter, offset, limit, sort)
 }
 
-func (s *stubRepo) Count(ctx context.Context) (int64, error) {
+func (s *stubRepo) Count(ctx context.Context, filter LinksFilter) (int64, error) {
 	s.t.Helper()
 
 	if s.countFunc == nil {
 		s.t.Fatalf("unexpected Count call")
 	}
 
-	return s.countFunc(ctx)
+	return s.countFunc(ctx, filter)
+}
+
+func (s *stubRepo) StreamAll(ctx context.Context, filter LinksFilter, sort Sort, fn func(domain.Link) error) error {
+	s.t.Helper()
+
+	if s.streamAllFunc == nil {
+		s.t.Fatalf("unexpected StreamAll call")
+	}
+
+	return s.streamAllFunc(ctx, filter, sort, fn)
 }
 
 func (s *stubRepo) GetByID(ctx context.Context, id int64) (domain.Link, error) {
@@ -112,44 +202,131 @@ func (s *stubRepo) GetByID(ctx context.Context, id int64) (domain.Link, error) {
 	return s.getByIDFunc(ctx, id)
 }
 
-func (s *stubRepo) GetByShortName(ctx context.Context, shortName string) (domain.Link, error) {
+func (s *stubRepo) GetByShortName(ctx context.Context, host, shortName string) (domain.Link, error) {
 	s.t.Helper()
 
 	if s.getByShortNameFunc == nil {
 		s.t.Fatalf("unexpected GetByShortName call")
 	}
 
-	return s.getByShortNameFunc(ctx, shortName)
+	return s.getByShortNameFunc(ctx, host, shortName)
+}
+
+func (s *stubRepo) GetByShortNameFold(ctx context.Context, host, shortName string) (domain.Link, error) {
+	s.t.Helper()
+
+	if s.getByFoldFunc == nil {
+		s.t.Fatalf("unexpected GetByShortNameFold call")
+	}
+
+	return s.getByFoldFunc(ctx, host, shortName)
+}
+
+func (s *stubRepo) GetByNormalizedURL(ctx context.Context, host, normalizedURL string) (domain.Link, error) {
+	s.t.Helper()
+
+	if s.getByNormalizedFunc == nil {
+		s.t.Fatalf("unexpected GetByNormalizedURL call")
+	}
+
+	return s.getByNormalizedFunc(ctx, host, normalizedURL)
 }
 
-func (s *stubRepo) Create(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
+func (s *stubRepo) Create(ctx context.Context, link domain.Link) (domain.Link, error) {
 	s.t.Helper()
 
 	if s.createFunc == nil {
 		s.t.Fatalf("unexpected Create call")
 	}
 
-	return s.createFunc(ctx, originalURL, shortName)
+	return s.createFunc(ctx, link.OriginalURL, link.ShortName)
 }
 
-func (s *stubRepo) Update(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+func (s *stubRepo) Update(ctx context.Context, link domain.Link, expectedVersion int64) (domain.Link, error) {
 	s.t.Helper()
 
 	if s.updateFunc == nil {
 		s.t.Fatalf("unexpected Update call")
 	}
 
-	return s.updateFunc(ctx, id, originalURL, shortName)
+	return s.updateFunc(ctx, link.ID, link.OriginalURL, link.ShortName, expectedVersion)
 }
 
-func (s *stubRepo) Delete(ctx context.Context, id int64) error {
+func (s *stubRepo) Delete(ctx context.Context, id int64, expectedVersion int64) error {
 	s.t.Helper()
 
 	if s.deleteFunc == nil {
 		s.t.Fatalf("unexpected Delete call")
 	}
 
-	return s.deleteFunc(ctx, id)
+	return s.deleteFunc(ctx, id, expectedVersion)
+}
+
+func (s *stubRepo) LockMatching(ctx context.Context, filter LinksFilter) ([]int64, error) {
+	s.t.Helper()
+
+	if s.lockMatchingFunc == nil {
+		s.t.Fatalf("unexpected LockMatching call")
+	}
+
+	return s.lockMatchingFunc(ctx, filter)
+}
+
+func (s *stubRepo) GetByIDForUpdate(ctx context.Context, id int64) (domain.Link, error) {
+	s.t.Helper()
+
+	if s.getForUpdateFunc == nil {
+		s.t.Fatalf("unexpected GetByIDForUpdate call")
+	}
+
+	return s.getForUpdateFunc(ctx, id)
+}
+
+func (s *stubRepo) Flag(ctx context.Context, id int64, reason string) (domain.Link, error) {
+	s.t.Helper()
+
+	if s.flagFunc == nil {
+		s.t.Fatalf("unexpected Flag call")
+	}
+
+	return s.flagFunc(ctx, id, reason)
+}
+
+func (s *stubRepo) ListFlagged(ctx context.Context) ([]domain.Link, error) {
+	s.t.Helper()
+
+	if s.listFlaggedFunc == nil {
+		s.t.Fatalf("unexpected ListFlagged call")
+	}
+
+	return s.listFlaggedFunc(ctx)
+}
+
+type stubAuditRepo struct {
+	events []domain.AuditEvent
+	err    error
+}
+
+func (s *stubAuditRepo) Create(_ context.Context, event domain.AuditEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func (s *stubAuditRepo) ListAll(context.Context, AuditFilter, Sort) ([]domain.AuditEvent, error) {
+	return s.events, nil
+}
+
+func (s *stubAuditRepo) ListPage(context.Context, AuditFilter, int32, int32, Sort) ([]domain.AuditEvent, error) {
+	return s.events, nil
+}
+
+func (s *stubAuditRepo) Count(context.Context, AuditFilter) (int64, error) {
+	return int64(len(s.events)), nil
 }
 
 func TestServiceCreate_AutoShortNameRetries(t *testing.T) {
@@ -175,7 +352,7 @@ func TestServiceCreate_AutoShortNameRetries(t *testing.T) {
 	}
 
 	svc := New(repo, nil, nil)
-	link, err := svc.Create(ctx, "https://example.com", "")
+	link, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com", ShortName: ""})
 	require.NoError(t, err)
 	require.Equal(t, 3, calls)
 	require.NoError(t, domain.ValidateShortName(lastShortName))
@@ -195,7 +372,7 @@ func TestServiceCreate_AutoShortNameExhausted(t *testing.T) {
 	}
 
 	svc := New(repo, nil, nil)
-	_, err := svc.Create(ctx, "https://example.com", "")
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com", ShortName: ""})
 	require.ErrorIs(t, err, domain.ErrShortNameConflict)
 	require.Equal(t, autoShortNameAttempts, calls)
 }
@@ -209,7 +386,7 @@ func TestServiceCreate_ExplicitShortNameValidation(t *testing.T) {
 		}
 
 		svc := New(repo, nil, nil)
-		_, err := svc.Create(ctx, "https://example.com", "ab_cd")
+		_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com", ShortName: "ab_cd"})
 		require.ErrorIs(t, err, domain.ErrInvalidShortName)
 	})
 
@@ -224,7 +401,7 @@ func TestServiceCreate_ExplicitShortNameValidation(t *testing.T) {
 		}
 
 		svc := New(repo, nil, nil)
-		_, err := svc.Create(ctx, "https://example.com", "abcd")
+		_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com", ShortName: "abcd"})
 		require.ErrorIs(t, err, domain.ErrShortNameConflict)
 		require.Equal(t, 1, calls)
 	})
@@ -236,14 +413,14 @@ func TestServiceUpdate_EmptyShortNameGenerates(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
 			gotShortName = shortName
 			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
 		},
 	}
 
 	svc := New(repo, nil, nil)
-	link, err := svc.Update(ctx, 1, "https://example.com/new", "")
+	link, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "https://example.com/new", ShortName: ""}, 0)
 	require.NoError(t, err)
 	require.NotEmpty(t, gotShortName)
 	require.NoError(t, domain.ValidateShortName(gotShortName))
@@ -255,14 +432,14 @@ func TestServiceUpdate_ExplicitShortName(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
 			require.Equal(t, "zzzz", shortName)
 			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
 		},
 	}
 
 	svc := New(repo, nil, nil)
-	link, err := svc.Update(ctx, 1, "https://example.com/new", "zzzz")
+	link, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "https://example.com/new", ShortName: "zzzz"}, 0)
 	require.NoError(t, err)
 	require.Equal(t, "zzzz", link.ShortName)
 }
@@ -272,14 +449,14 @@ func TestServiceUpdate_InvalidShortName(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
 			t.Fatalf("Update should not be called")
 			return domain.Link{}, nil
 		},
 	}
 
 	svc := New(repo, nil, nil)
-	_, err := svc.Update(ctx, 1, "https://example.com/new", "ab_cd")
+	_, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "https://example.com/new", ShortName: "ab_cd"}, 0)
 	require.ErrorIs(t, err, domain.ErrInvalidShortName)
 }
 
@@ -288,13 +465,13 @@ func TestServiceUpdate_ConflictReturnsError(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
 			return domain.Link{}, domain.ErrShortNameConflict
 		},
 	}
 
 	svc := New(repo, nil, nil)
-	_, err := svc.Update(ctx, 1, "https://example.com/new", "conflict")
+	_, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "https://example.com/new", ShortName: "conflict"}, 0)
 	require.ErrorIs(t, err, domain.ErrShortNameConflict)
 }
 
@@ -303,13 +480,13 @@ func TestServiceUpdate_NotFound(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
 			return domain.Link{}, domain.ErrNotFound
 		},
 	}
 
 	svc := New(repo, nil, nil)
-	_, err := svc.Update(ctx, 1, "https://example.com/new", "abcd")
+	_, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "https://example.com/new", ShortName: "abcd"}, 0)
 	require.ErrorIs(t, err, domain.ErrNotFound)
 }
 
@@ -318,14 +495,14 @@ func TestServiceUpdate_InvalidOriginalURL(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error) {
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
 			t.Fatalf("Update should not be called")
 			return domain.Link{}, nil
 		},
 	}
 
 	svc := New(repo, nil, nil)
-	_, err := svc.Update(ctx, 1, "not-a-url", "abcd")
+	_, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "not-a-url", ShortName: "abcd"}, 0)
 	require.ErrorIs(t, err, domain.ErrInvalidURL)
 }
 
@@ -340,7 +517,7 @@ func TestServiceRedirect_VisitCreateFailureDoesNotFail(t *testing.T) {
 
 	repo := &stubRepo{
 		t: t,
-		getByShortNameFunc: func(ctx context.Context, shortName string) (domain.Link, error) {
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
 			require.Equal(t, "code", shortName)
 			return link, nil
 		},
@@ -355,9 +532,1761 @@ func TestServiceRedirect_VisitCreateFailureDoesNotFail(t *testing.T) {
 	}
 
 	svc := New(repo, visitsRepo, nil)
-	url, status, err := svc.Redirect(ctx, "code", VisitMeta{})
+	redirection, err := svc.Redirect(ctx, "", "code", VisitMeta{})
 	require.NoError(t, err)
-	require.Equal(t, link.OriginalURL, url)
-	require.Equal(t, redirectStatusFound, status)
+	require.Equal(t, link.OriginalURL, redirection.URL)
+	require.Equal(t, redirectStatusFound, redirection.Status)
 	require.Equal(t, 1, createCalls)
 }
+
+func TestServiceRedirect_Forward(t *testing.T) {
+	ctx := context.Background()
+	link := domain.Link{
+		ID:           1,
+		OriginalURL:  "https://example.com/docs?lang=en",
+		ShortName:    "code",
+		ForwardPath:  true,
+		ForwardQuery: domain.ForwardQueryPreserve,
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return link, nil
+		},
+	}
+
+	var visit domain.LinkVisit
+	visitsRepo := &stubVisitsRepo{
+		t: t,
+		createFunc: func(ctx context.Context, v domain.LinkVisit) (int64, error) {
+			visit = v
+
+			return 1, nil
+		},
+	}
+
+	svc := New(repo, visitsRepo, nil)
+	redirection, err := svc.Redirect(ctx, "", "code", VisitMeta{
+		Path:      "/r/code/getting-started?lang=de&utm_source=x",
+		ExtraPath: "/getting-started",
+		RawQuery:  "lang=de&utm_source=x",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/docs/getting-started?lang=en&utm_source=x", redirection.URL)
+	require.Equal(t, "/r/code/getting-started?lang=de&utm_source=x", visit.Path)
+}
+
+func TestServiceRedirect_UTM(t *testing.T) {
+	ctx := context.Background()
+	link := domain.Link{
+		ID:           1,
+		OriginalURL:  "https://example.com/sale?utm_source=old",
+		ShortName:    "sale",
+		ForwardQuery: domain.ForwardQueryPreserve,
+		UTM:          domain.UTM{Source: "news", Medium: "email", Campaign: "spring"},
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return link, nil
+		},
+	}
+
+	svc := New(repo, nil, nil)
+
+	// The UTM parameters of the link win over both the destination and the
+	// forwarded query.
+	redirection, err := svc.Redirect(ctx, "", "sale", VisitMeta{RawQuery: "utm_source=x&ref=y"})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/sale?ref=y&utm_campaign=spring&utm_medium=email&utm_source=news", redirection.URL)
+}
+
+func TestServiceRedirect_Rules(t *testing.T) {
+	ctx := context.Background()
+	link := domain.Link{
+		ID:          1,
+		OriginalURL: "https://example.com/app",
+		ShortName:   "app",
+		UTM:         domain.UTM{Source: "qr"},
+		Rules: []domain.RedirectRule{
+			{OS: domain.OSiOS, URL: "https://apps.apple.com/app/id1"},
+			{OS: domain.OSAndroid, URL: "https://play.google.com/store/apps/details?id=app"},
+		},
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return link, nil
+		},
+	}
+
+	ruleIndex := func(i int) *int { return &i }
+
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+		rule      *int
+	}{
+		{"ios", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+			"https://apps.apple.com/app/id1?utm_source=qr", ruleIndex(0)},
+		{"android", "Mozilla/5.0 (Linux; Android 14; Pixel 8) Mobile",
+			"https://play.google.com/store/apps/details?id=app&utm_source=qr", ruleIndex(1)},
+		{"fallback", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			"https://example.com/app?utm_source=qr", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var visit domain.LinkVisit
+
+			visitsRepo := &stubVisitsRepo{
+				t: t,
+				createFunc: func(ctx context.Context, v domain.LinkVisit) (int64, error) {
+					visit = v
+
+					return 1, nil
+				},
+			}
+
+			svc := New(repo, visitsRepo, nil)
+			redirection, err := svc.Redirect(ctx, "", "app", VisitMeta{UserAgent: tc.userAgent})
+			require.NoError(t, err)
+			require.Equal(t, tc.want, redirection.URL)
+			require.Equal(t, tc.rule, visit.Rule)
+		})
+	}
+}
+
+type stubGeoIP map[string]string
+
+func (g stubGeoIP) Country(ip string) string {
+	return g[ip]
+}
+
+func TestServiceRedirect_CountryRules(t *testing.T) {
+	ctx := context.Background()
+	link := domain.Link{
+		ID:          1,
+		OriginalURL: "https://example.com/sale",
+		ShortName:   "sale",
+		Rules: []domain.RedirectRule{
+			{Country: "DE", URL: "https://example.de/sale"},
+			{Country: "FR", URL: "https://example.fr/soldes"},
+		},
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return link, nil
+		},
+	}
+
+	geo := stubGeoIP{"203.0.113.7": "FR"}
+
+	tests := []struct {
+		name string
+		geo  GeoIP
+		meta VisitMeta
+		want string
+	}{
+		{"header", nil, VisitMeta{IP: "203.0.113.7", Country: "DE"}, "https://example.de/sale"},
+		{"header_wins_over_geoip", geo, VisitMeta{IP: "203.0.113.7", Country: "DE"}, "https://example.de/sale"},
+		{"geoip", geo, VisitMeta{IP: "203.0.113.7"}, "https://example.fr/soldes"},
+		{"unresolved", geo, VisitMeta{IP: "198.51.100.1"}, "https://example.com/sale"},
+		{"no_geoip", nil, VisitMeta{IP: "203.0.113.7"}, "https://example.com/sale"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := New(repo, nil, nil, WithGeoIP(tc.geo))
+			redirection, err := svc.Redirect(ctx, "", "sale", tc.meta)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, redirection.URL)
+		})
+	}
+}
+
+func TestServiceRedirect_Variants(t *testing.T) {
+	ctx := context.Background()
+	index := func(i int) *int { return &i }
+
+	link := domain.Link{
+		ID:          1,
+		OriginalURL: "https://example.com/landing",
+		ShortName:   "split",
+		Rules:       []domain.RedirectRule{{OS: domain.OSiOS, URL: "https://apps.apple.com/app/id1"}},
+		Variants: []domain.Variant{
+			{URL: "https://example.com/a", Weight: 0},
+			{URL: "https://example.com/b", Weight: 1},
+			{URL: "https://example.com/c", Weight: 0},
+		},
+		StickyVariant: true,
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return link, nil
+		},
+	}
+
+	var visit domain.LinkVisit
+
+	visitsRepo := &stubVisitsRepo{
+		t: t,
+		createFunc: func(ctx context.Context, v domain.LinkVisit) (int64, error) {
+			visit = v
+
+			return 1, nil
+		},
+	}
+
+	svc := New(repo, visitsRepo, nil)
+
+	redirection, err := svc.Redirect(ctx, "", "split", VisitMeta{})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/b", redirection.URL)
+	require.Equal(t, index(1), redirection.Variant)
+	require.True(t, redirection.StickyVariant)
+	require.Equal(t, index(1), visit.Variant)
+
+	// A paused or unknown remembered variant is drawn again.
+	for _, remembered := range []int{0, 3} {
+		redirection, err = svc.Redirect(ctx, "", "split", VisitMeta{Variant: index(remembered)})
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/b", redirection.URL)
+	}
+
+	link.Variants[2].Weight = 5
+
+	redirection, err = svc.Redirect(ctx, "", "split", VisitMeta{Variant: index(2)})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/c", redirection.URL)
+
+	link.StickyVariant = false
+
+	redirection, err = svc.Redirect(ctx, "", "split", VisitMeta{Variant: index(2)})
+	require.NoError(t, err)
+	require.NotNil(t, redirection.Variant)
+	require.False(t, redirection.StickyVariant)
+
+	// Rules win over the split.
+	redirection, err = svc.Redirect(ctx, "", "split", VisitMeta{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"})
+	require.NoError(t, err)
+	require.Equal(t, "https://apps.apple.com/app/id1", redirection.URL)
+	require.Nil(t, redirection.Variant)
+	require.Nil(t, visit.Variant)
+	require.Equal(t, index(0), visit.Rule)
+}
+
+func TestServiceCreate_InvalidRules(t *testing.T) {
+	ctx := context.Background()
+	svc := New(&stubRepo{t: t}, nil, nil,
+		WithURLPolicy(domain.URLPolicy{DenyDomains: []string{"evil.example"}}))
+
+	_, err := svc.Create(ctx, LinkInput{
+		OriginalURL: "https://example.com",
+		Rules:       []domain.RedirectRule{{OS: "beos", URL: "https://example.com/b"}},
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidRedirectRules)
+
+	_, err = svc.Create(ctx, LinkInput{
+		OriginalURL: "https://example.com",
+		Rules:       []domain.RedirectRule{{OS: domain.OSiOS, URL: "https://evil.example/app"}},
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidRedirectRules)
+	require.ErrorIs(t, err, domain.ErrURLDomainDenied)
+}
+
+func TestServiceListLinks_Filter(t *testing.T) {
+	ctx := context.Background()
+	filter := LinksFilter{Campaign: "spring"}
+
+	repo := &stubRepo{
+		t: t,
+		listPageFunc: func(_ context.Context, got LinksFilter, offset, limit int32, _ Sort) ([]domain.Link, error) {
+			require.Equal(t, filter, got)
+			require.Equal(t, int32(10), offset)
+			require.Equal(t, int32(5), limit)
+
+			return []domain.Link{{ID: 1}}, nil
+		},
+		countFunc: func(_ context.Context, got LinksFilter) (int64, error) {
+			require.Equal(t, filter, got)
+
+			return 11, nil
+		},
+	}
+
+	svc := New(repo, nil, nil)
+
+	items, total, err := svc.ListLinks(ctx, LinksQuery{Range: &Range{Start: 10, Count: 5}, Filter: filter})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, int64(11), total)
+}
+
+func TestServiceVisitStats(t *testing.T) {
+	ctx := context.Background()
+	want := []domain.VisitStat{{Key: "spring", Visits: 3}, {Key: "", Visits: 1}}
+
+	visitsRepo := &stubVisitsRepo{
+		t: t,
+		statsFunc: func(_ context.Context, query VisitStatsQuery) ([]domain.VisitStat, error) {
+			require.Equal(t, VisitStatsQuery{GroupBy: StatsGroupCampaign, LinkID: 4}, query)
+
+			return want, nil
+		},
+	}
+
+	svc := New(&stubRepo{t: t}, visitsRepo, nil)
+
+	stats, err := svc.VisitStats(ctx, VisitStatsQuery{GroupBy: StatsGroupCampaign, LinkID: 4})
+	require.NoError(t, err)
+	require.Equal(t, want, stats)
+
+	_, err = svc.VisitStats(ctx, VisitStatsQuery{GroupBy: "nope"})
+	require.ErrorIs(t, err, ErrInvalidStatsGroup)
+}
+
+func TestServiceCreate_LinkQuotaExceeded(t *testing.T) {
+	ctx := context.Background()
+
+	var locked bool
+	repo := &stubRepo{
+		t: t,
+		countFunc: func(ctx context.Context, filter LinksFilter) (int64, error) {
+			// Disabled links count against the quota.
+			require.Equal(t, LinksFilter{}, filter)
+
+			return 2, nil
+		},
+	}
+	quotaRepo := &stubQuotaRepo{
+		t: t,
+		lockFunc: func(ctx context.Context) error {
+			locked = true
+
+			return nil
+		},
+	}
+
+	svc := New(repo, nil, nil, WithQuota(Quota{MaxLinks: 2}, quotaRepo))
+
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com", ShortName: "abcd"})
+	require.ErrorIs(t, err, ErrLinkQuotaExceeded)
+	require.True(t, locked)
+}
+
+func TestServiceRedirect_ClickQuota(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, time.October, 31, 13, 1, 43, 0, time.UTC)
+	link := domain.Link{ID: 1, OriginalURL: "https://example.com", ShortName: "code"}
+
+	newService := func(t *testing.T, quota Quota, visitCalls *int) *Service {
+		repo := &stubRepo{
+			t: t,
+			getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+				return link, nil
+			},
+		}
+		visitsRepo := &stubVisitsRepo{
+			t: t,
+			createFunc: func(ctx context.Context, visit domain.LinkVisit) (int64, error) {
+				*visitCalls++
+
+				return 1, nil
+			},
+		}
+		quotaRepo := &stubQuotaRepo{
+			t: t,
+			incrementFunc: func(ctx context.Context, period time.Time, limit int64) (bool, error) {
+				require.Equal(t, time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC), period)
+				require.Equal(t, quota.MaxMonthlyClicks, limit)
+
+				return false, nil
+			},
+		}
+
+		return New(repo, visitsRepo, nil,
+			WithQuota(quota, quotaRepo),
+			WithClock(func() time.Time { return now }),
+		)
+	}
+
+	t.Run("tracking stops", func(t *testing.T) {
+		var visitCalls int
+		svc := newService(t, Quota{MaxMonthlyClicks: 10}, &visitCalls)
+
+		redirection, err := svc.Redirect(ctx, "", "code", VisitMeta{})
+		require.NoError(t, err)
+		require.Equal(t, link.OriginalURL, redirection.URL)
+		require.Equal(t, redirectStatusFound, redirection.Status)
+		require.Zero(t, visitCalls)
+	})
+
+	t.Run("redirect blocked", func(t *testing.T) {
+		var visitCalls int
+		svc := newService(t, Quota{MaxMonthlyClicks: 10, BlockRedirects: true}, &visitCalls)
+
+		_, err := svc.Redirect(ctx, "", "code", VisitMeta{})
+		require.ErrorIs(t, err, ErrClickQuotaExceeded)
+		require.Zero(t, visitCalls)
+	})
+
+	t.Run("unlimited skips the counter", func(t *testing.T) {
+		var visitCalls int
+		svc := newService(t, Quota{MaxLinks: 10}, &visitCalls)
+		svc.quotaRepo = &stubQuotaRepo{t: t}
+
+		_, err := svc.Redirect(ctx, "", "code", VisitMeta{})
+		require.NoError(t, err)
+		require.Equal(t, 1, visitCalls)
+	})
+}
+
+func TestServiceAudit_RecordsMutations(t *testing.T) {
+	ctx := ContextWithAuditMeta(context.Background(), AuditMeta{
+		Actor:     "alice",
+		RequestID: "req-1",
+		IP:        "10.0.0.1",
+	})
+
+	current := domain.Link{ID: 7, OriginalURL: "https://example.com/old", ShortName: "old1"}
+	repo := &stubRepo{
+		t: t,
+		createFunc: func(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
+			return domain.Link{ID: 7, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+		getForUpdateFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+			return current, nil
+		},
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+		deleteFunc: func(ctx context.Context, id int64, expectedVersion int64) error {
+			return nil
+		},
+	}
+	auditRepo := &stubAuditRepo{}
+	svc := New(repo, nil, nil, WithAudit(auditRepo))
+
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/old", ShortName: "old1"})
+	require.NoError(t, err)
+	_, err = svc.Update(ctx, 7, LinkInput{OriginalURL: "https://example.com/new", ShortName: "new1"}, 0)
+	require.NoError(t, err)
+	require.NoError(t, svc.Delete(ctx, 7, 0))
+
+	require.Len(t, auditRepo.events, 3)
+
+	created, updated, deleted := auditRepo.events[0], auditRepo.events[1], auditRepo.events[2]
+	require.Equal(t, domain.AuditActionCreate, created.Action)
+	require.Nil(t, created.Before)
+	require.Equal(t, "old1", created.After.ShortName)
+
+	require.Equal(t, domain.AuditActionUpdate, updated.Action)
+	require.Equal(t, "https://example.com/old", updated.Before.OriginalURL)
+	require.Equal(t, "https://example.com/new", updated.After.OriginalURL)
+
+	require.Equal(t, domain.AuditActionDelete, deleted.Action)
+	require.Nil(t, deleted.After)
+
+	for _, event := range auditRepo.events {
+		require.Equal(t, int64(7), event.LinkID)
+		require.Equal(t, "alice", event.Actor)
+		require.Equal(t, "req-1", event.RequestID)
+		require.Equal(t, "10.0.0.1", event.IP)
+	}
+}
+
+func TestServiceAudit_FailureFailsMutation(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &stubRepo{
+		t: t,
+		getForUpdateFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+			return domain.Link{ID: id}, nil
+		},
+		deleteFunc: func(ctx context.Context, id int64, expectedVersion int64) error {
+			return nil
+		},
+	}
+	auditRepo := &stubAuditRepo{err: errors.New("write failed")}
+	svc := New(repo, nil, nil, WithAudit(auditRepo))
+
+	err := svc.Delete(ctx, 1, 0)
+	require.Error(t, err)
+}
+
+type stubRevisionsRepo struct {
+	rev domain.LinkRevision
+}
+
+func (s *stubRevisionsRepo) Get(_ context.Context, linkID, id int64) (domain.LinkRevision, error) {
+	if s.rev.LinkID != linkID || s.rev.ID != id {
+		return domain.LinkRevision{}, domain.ErrNotFound
+	}
+
+	return s.rev, nil
+}
+
+func (s *stubRevisionsRepo) ListAll(context.Context, int64, Sort) ([]domain.LinkRevision, error) {
+	return []domain.LinkRevision{s.rev}, nil
+}
+
+func (s *stubRevisionsRepo) ListPage(context.Context, int64, int32, int32, Sort) ([]domain.LinkRevision, error) {
+	return []domain.LinkRevision{s.rev}, nil
+}
+
+func (s *stubRevisionsRepo) Count(context.Context, int64) (int64, error) {
+	return 1, nil
+}
+
+func TestServiceRevertLink(t *testing.T) {
+	ctx := context.Background()
+
+	revisions := &stubRevisionsRepo{rev: domain.LinkRevision{
+		ID:          3,
+		LinkID:      1,
+		OriginalURL: "https://example.com/old",
+		ShortName:   "old1",
+	}}
+
+	t.Run("re-applies revision", func(t *testing.T) {
+		repo := &stubRepo{
+			t: t,
+			getForUpdateFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+				return domain.Link{ID: id, OriginalURL: "https://example.com/new", ShortName: "new1"}, nil
+			},
+			updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+				return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
+			},
+		}
+		auditRepo := &stubAuditRepo{}
+		svc := New(repo, nil, nil, WithRevisions(revisions), WithAudit(auditRepo))
+
+		link, err := svc.RevertLink(ctx, 1, 3)
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/old", link.OriginalURL)
+		require.Equal(t, "old1", link.ShortName)
+		require.Len(t, auditRepo.events, 1)
+		require.Equal(t, domain.AuditActionRevert, auditRepo.events[0].Action)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		svc := New(&stubRepo{t: t}, nil, nil, WithRevisions(revisions))
+
+		_, err := svc.RevertLink(ctx, 1, 4)
+		require.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestServiceUpdate_VersionMismatch(t *testing.T) {
+	ctx := context.Background()
+	var gotVersion int64
+
+	repo := &stubRepo{
+		t: t,
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+			gotVersion = expectedVersion
+			return domain.Link{}, domain.ErrVersionMismatch
+		},
+	}
+
+	svc := New(repo, nil, nil)
+	_, err := svc.Update(ctx, 1, LinkInput{OriginalURL: "https://example.com/new", ShortName: "abcd"}, 3)
+	require.ErrorIs(t, err, domain.ErrVersionMismatch)
+	require.Equal(t, int64(3), gotVersion)
+}
+
+func TestServicePatch(t *testing.T) {
+	ctx := context.Background()
+	current := domain.Link{ID: 1, OriginalURL: "https://example.com/old", ShortName: "keep", Version: 4}
+
+	newRepo := func(t *testing.T, got *domain.Link, gotVersion *int64) *stubRepo {
+		return &stubRepo{
+			t: t,
+			getForUpdateFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+				return current, nil
+			},
+			updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+				*got = domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName, Version: expectedVersion + 1}
+				*gotVersion = expectedVersion
+				return *got, nil
+			},
+		}
+	}
+
+	t.Run("absent fields are kept", func(t *testing.T) {
+		var got domain.Link
+		var gotVersion int64
+		svc := New(newRepo(t, &got, &gotVersion), nil, nil)
+
+		url := "https://example.com/new"
+		link, err := svc.Patch(ctx, 1, LinkPatch{OriginalURL: &url}, 0)
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/new", link.OriginalURL)
+		require.Equal(t, "keep", link.ShortName)
+		require.Equal(t, int64(4), gotVersion)
+	})
+
+	t.Run("empty short name is rejected", func(t *testing.T) {
+		svc := New(&stubRepo{t: t}, nil, nil)
+
+		for _, name := range []string{"", "  "} {
+			_, err := svc.Patch(ctx, 1, LinkPatch{ShortName: &name}, 4)
+			require.ErrorIs(t, err, domain.ErrInvalidShortName)
+		}
+
+		name := "other"
+		_, err := svc.Patch(ctx, 1, LinkPatch{ShortName: &name, RegenerateShortName: true}, 4)
+		require.ErrorIs(t, err, domain.ErrInvalidShortName)
+	})
+
+	t.Run("regenerate short name", func(t *testing.T) {
+		var got domain.Link
+		var gotVersion int64
+		svc := New(newRepo(t, &got, &gotVersion), nil, nil)
+
+		link, err := svc.Patch(ctx, 1, LinkPatch{RegenerateShortName: true}, 4)
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/old", link.OriginalURL)
+		require.NotEqual(t, "keep", link.ShortName)
+		require.NoError(t, domain.ValidateShortName(link.ShortName))
+	})
+
+	t.Run("utm members merge", func(t *testing.T) {
+		stored := current
+		stored.UTM = domain.UTM{Source: "news", Medium: "email", Campaign: "spring"}
+
+		source, empty := "blog", ""
+		in := LinkPatch{UTM: &UTMPatch{Source: &source, Medium: &empty}}.apply(stored)
+		require.Equal(t, domain.UTM{Source: "blog", Campaign: "spring"}, in.UTM)
+
+		in = LinkPatch{UTM: &UTMPatch{}}.apply(stored)
+		require.Equal(t, stored.UTM, in.UTM)
+	})
+
+	t.Run("empty patch is a no-op", func(t *testing.T) {
+		svc := New(&stubRepo{
+			t: t,
+			getByIDFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+				return current, nil
+			},
+		}, nil, nil)
+
+		link, err := svc.Patch(ctx, 1, LinkPatch{}, 0)
+		require.NoError(t, err)
+		require.Equal(t, current, link)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		svc := New(&stubRepo{
+			t: t,
+			getForUpdateFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+				return current, nil
+			},
+		}, nil, nil)
+
+		url := "https://example.com/new"
+		_, err := svc.Patch(ctx, 1, LinkPatch{OriginalURL: &url}, 3)
+		require.ErrorIs(t, err, domain.ErrVersionMismatch)
+	})
+}
+
+func TestServiceBulkCreate(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(t *testing.T) *stubRepo {
+		var nextID int64
+
+		return &stubRepo{
+			t: t,
+			createFunc: func(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
+				if shortName == "taken" {
+					return domain.Link{}, domain.ErrShortNameConflict
+				}
+
+				if originalURL == "https://example.com/unknown-domain" {
+					return domain.Link{}, domain.ErrUnknownDomain
+				}
+
+				nextID++
+				return domain.Link{ID: nextID, OriginalURL: originalURL, ShortName: shortName}, nil
+			},
+		}
+	}
+
+	items := []LinkInput{
+		{OriginalURL: "https://example.com/a", ShortName: "first"},
+		{OriginalURL: "https://example.com/b", ShortName: "taken"},
+		{OriginalURL: "not-a-url", ShortName: "third"},
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		results, err := svc.BulkCreate(ctx, items, BulkModeBestEffort)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.Equal(t, BulkItemCreated, results[0].Status)
+		require.Equal(t, "first", results[0].Link.ShortName)
+		require.Equal(t, BulkItemFailed, results[1].Status)
+		require.ErrorIs(t, results[1].Err, domain.ErrShortNameConflict)
+		require.Equal(t, BulkItemFailed, results[2].Status)
+		require.ErrorIs(t, results[2].Err, domain.ErrInvalidURL)
+	})
+
+	t.Run("atomic rolls back created items", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		results, err := svc.BulkCreate(ctx, items, BulkModeAtomic)
+		require.NoError(t, err)
+		require.Equal(t, BulkItemSkipped, results[0].Status)
+		require.Zero(t, results[0].Link.ID)
+		require.Equal(t, BulkItemFailed, results[1].Status)
+	})
+
+	t.Run("unknown strategy fails the item", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		results, err := svc.BulkCreate(ctx, []LinkInput{
+			{OriginalURL: "https://example.com/a", ShortName: "first"},
+			{OriginalURL: "https://example.com/b", ShortNameStrategy: "emoji"},
+		}, BulkModeBestEffort)
+		require.NoError(t, err)
+		require.Equal(t, BulkItemCreated, results[0].Status)
+		require.Equal(t, BulkItemFailed, results[1].Status)
+		require.ErrorIs(t, results[1].Err, ErrUnknownShortNameStrategy)
+	})
+
+	t.Run("bad domains fail the item", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		results, err := svc.BulkCreate(ctx, []LinkInput{
+			{OriginalURL: "https://example.com/a", ShortName: "first"},
+			{OriginalURL: "https://example.com/b", ShortName: "second", Domain: "not a host"},
+			{OriginalURL: "https://example.com/unknown-domain", ShortName: "third", Domain: "go.example.com"},
+		}, BulkModeBestEffort)
+		require.NoError(t, err)
+		require.Equal(t, BulkItemCreated, results[0].Status)
+		require.ErrorIs(t, results[1].Err, domain.ErrInvalidDomain)
+		require.ErrorIs(t, results[2].Err, domain.ErrUnknownDomain)
+	})
+
+	t.Run("batch bounds", func(t *testing.T) {
+		svc := New(&stubRepo{t: t}, nil, nil)
+
+		_, err := svc.BulkCreate(ctx, nil, BulkModeAtomic)
+		require.ErrorIs(t, err, ErrInvalidBatchSize)
+
+		_, err = svc.BulkCreate(ctx, make([]LinkInput, MaxBulkItems+1), BulkModeAtomic)
+		require.ErrorIs(t, err, ErrInvalidBatchSize)
+	})
+
+	t.Run("infrastructure error fails the batch", func(t *testing.T) {
+		dbErr := errors.New("db down")
+		svc := New(&stubRepo{
+			t: t,
+			createFunc: func(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
+				return domain.Link{}, dbErr
+			},
+		}, nil, nil)
+
+		_, err := svc.BulkCreate(ctx, items[:1], BulkModeBestEffort)
+		require.ErrorIs(t, err, dbErr)
+	})
+}
+
+type sliceImportSource struct {
+	records []ImportRecord
+}
+
+func (s *sliceImportSource) Next() (ImportRecord, error) {
+	if len(s.records) == 0 {
+		return ImportRecord{}, io.EOF
+	}
+
+	rec := s.records[0]
+	s.records = s.records[1:]
+
+	return rec, nil
+}
+
+func TestServiceImportLinks(t *testing.T) {
+	ctx := context.Background()
+
+	existing := domain.Link{ID: 7, OriginalURL: "https://example.com/old", ShortName: "taken", Version: 2}
+
+	newRepo := func(t *testing.T) *stubRepo {
+		var nextID int64 = 100
+
+		return &stubRepo{
+			t: t,
+			getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+				if shortName == existing.ShortName {
+					return existing, nil
+				}
+
+				return domain.Link{}, domain.ErrNotFound
+			},
+			createFunc: func(ctx context.Context, originalURL, shortName string) (domain.Link, error) {
+				if originalURL == "https://example.com/unknown-domain" {
+					return domain.Link{}, domain.ErrUnknownDomain
+				}
+
+				nextID++
+				return domain.Link{ID: nextID, OriginalURL: originalURL, ShortName: shortName}, nil
+			},
+			updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+				require.Equal(t, existing.ID, id)
+				return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
+			},
+		}
+	}
+
+	source := func() *sliceImportSource {
+		return &sliceImportSource{records: []ImportRecord{
+			{Line: 2, Input: LinkInput{OriginalURL: "https://example.com/a", ShortName: "fresh"}},
+			{Line: 3, Input: LinkInput{OriginalURL: "https://example.com/b", ShortName: "taken"}},
+			{Line: 4, Input: LinkInput{OriginalURL: "not-a-url"}},
+			{Line: 5, Err: errors.New("malformed row")},
+		}}
+	}
+
+	t.Run("fail on conflict", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		res, err := svc.ImportLinks(ctx, source(), ImportOptions{OnConflict: ConflictFail})
+		require.NoError(t, err)
+		require.Equal(t, 4, res.Rows)
+		require.Equal(t, 1, res.Created)
+		require.Equal(t, 3, res.Failed)
+		require.Len(t, res.Errors, 3)
+		require.Equal(t, 3, res.Errors[0].Line)
+		require.ErrorIs(t, res.Errors[0].Err, domain.ErrShortNameConflict)
+		require.ErrorIs(t, res.Errors[1].Err, domain.ErrInvalidURL)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		res, err := svc.ImportLinks(ctx, source(), ImportOptions{OnConflict: ConflictSkip})
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Created)
+		require.Equal(t, 1, res.Skipped)
+		require.Equal(t, 2, res.Failed)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		res, err := svc.ImportLinks(ctx, source(), ImportOptions{OnConflict: ConflictOverwrite})
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Created)
+		require.Equal(t, 1, res.Updated)
+		require.Equal(t, 2, res.Failed)
+	})
+
+	t.Run("unknown strategy fails the row", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		res, err := svc.ImportLinks(ctx, &sliceImportSource{records: []ImportRecord{
+			{Line: 2, Input: LinkInput{OriginalURL: "https://example.com/a", ShortNameStrategy: "emoji"}},
+			{Line: 3, Input: LinkInput{OriginalURL: "https://example.com/b", ShortName: "fresh"}},
+		}}, ImportOptions{OnConflict: ConflictFail})
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Created)
+		require.Equal(t, 1, res.Failed)
+		require.Equal(t, 2, res.Errors[0].Line)
+		require.ErrorIs(t, res.Errors[0].Err, ErrUnknownShortNameStrategy)
+	})
+
+	t.Run("bad domains fail the row", func(t *testing.T) {
+		svc := New(newRepo(t), nil, nil)
+
+		res, err := svc.ImportLinks(ctx, &sliceImportSource{records: []ImportRecord{
+			{Line: 2, Input: LinkInput{OriginalURL: "https://example.com/a", Domain: "not a host"}},
+			{Line: 3, Input: LinkInput{OriginalURL: "https://example.com/unknown-domain", Domain: "go.example.com"}},
+			{Line: 4, Input: LinkInput{OriginalURL: "https://example.com/b", ShortName: "fresh"}},
+		}}, ImportOptions{OnConflict: ConflictFail})
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Created)
+		require.Equal(t, 2, res.Failed)
+		require.ErrorIs(t, res.Errors[0].Err, domain.ErrInvalidDomain)
+		require.ErrorIs(t, res.Errors[1].Err, domain.ErrUnknownDomain)
+	})
+
+	t.Run("dry run only reads", func(t *testing.T) {
+		// Create, Update and LockLinkQuota are not stubbed: a dry run
+		// writes nothing and takes no lock.
+		repo := newRepo(t)
+		repo.createFunc = nil
+		repo.updateFunc = nil
+		repo.countFunc = func(context.Context, LinksFilter) (int64, error) {
+			return 1, nil
+		}
+
+		svc := New(repo, nil, nil, WithQuota(Quota{MaxLinks: 3}, &stubQuotaRepo{t: t}))
+
+		res, err := svc.ImportLinks(ctx, &sliceImportSource{records: []ImportRecord{
+			{Line: 2, Input: LinkInput{OriginalURL: "https://example.com/a", ShortName: "fresh"}},
+			{Line: 3, Input: LinkInput{OriginalURL: "https://example.com/b", ShortName: "taken"}},
+			{Line: 4, Input: LinkInput{OriginalURL: "https://example.com/c", ShortName: "fresh"}},
+			{Line: 5, Input: LinkInput{OriginalURL: "not-a-url"}},
+			{Line: 6, Input: LinkInput{OriginalURL: "https://example.com/d"}},
+			{Line: 7, Input: LinkInput{OriginalURL: "https://example.com/e"}},
+		}}, ImportOptions{DryRun: true, OnConflict: ConflictOverwrite})
+		require.NoError(t, err)
+		require.Equal(t, 2, res.Created)
+		require.Equal(t, 2, res.Updated)
+		require.Equal(t, 2, res.Failed)
+		require.ErrorIs(t, res.Errors[0].Err, domain.ErrInvalidURL)
+		require.Equal(t, 7, res.Errors[1].Line)
+		require.ErrorIs(t, res.Errors[1].Err, ErrLinkQuotaExceeded)
+
+		res, err = svc.ImportLinks(ctx, &sliceImportSource{records: []ImportRecord{
+			{Line: 2, Input: LinkInput{OriginalURL: "https://example.com/a", ShortName: "fresh"}},
+			{Line: 3, Input: LinkInput{OriginalURL: "https://example.com/c", ShortName: "fresh"}},
+		}}, ImportOptions{DryRun: true, OnConflict: ConflictFail})
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Created)
+		require.ErrorIs(t, res.Errors[0].Err, domain.ErrShortNameConflict)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		svc := New(&stubRepo{t: t}, nil, nil)
+
+		_, err := svc.ImportLinks(ctx, source(), ImportOptions{OnConflict: "merge"})
+		require.ErrorIs(t, err, ErrInvalidConflictPolicy)
+	})
+
+	t.Run("infrastructure error aborts", func(t *testing.T) {
+		dbErr := errors.New("db down")
+		svc := New(&stubRepo{
+			t: t,
+			getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+				return domain.Link{}, dbErr
+			},
+		}, nil, nil)
+
+		_, err := svc.ImportLinks(ctx, source(), ImportOptions{OnConflict: ConflictFail})
+		require.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestServiceExportLinks(t *testing.T) {
+	ctx := context.Background()
+	stored := []domain.Link{{ID: 1, ShortName: "one"}, {ID: 2, ShortName: "two"}}
+
+	svc := New(&stubRepo{
+		t: t,
+		streamAllFunc: func(ctx context.Context, filter LinksFilter, sort Sort, fn func(domain.Link) error) error {
+			require.Equal(t, LinksFilter{Tag: "promo"}, filter)
+			require.Equal(t, DefaultLinksSort, sort)
+
+			for _, link := range stored {
+				if err := fn(link); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}, nil, nil)
+
+	var got []int64
+	err := svc.ExportLinks(ctx, LinksFilter{Tag: "promo"}, DefaultLinksSort, func(link domain.Link) error {
+		got = append(got, link.ID)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, got)
+
+	writeErr := errors.New("client gone")
+	err = svc.ExportLinks(ctx, LinksFilter{Tag: "promo"}, DefaultLinksSort, func(domain.Link) error {
+		return writeErr
+	})
+	require.ErrorIs(t, err, writeErr)
+
+	err = svc.ExportLinkVisits(ctx, DefaultLinkVisitsSort, func(domain.LinkVisit) error { return nil })
+	require.Error(t, err)
+}
+
+func TestServiceBulkDelete(t *testing.T) {
+	ctx := context.Background()
+
+	var deleted []int64
+	svc := New(&stubRepo{
+		t: t,
+		lockMatchingFunc: func(ctx context.Context, filter LinksFilter) ([]int64, error) {
+			require.Equal(t, []int64{1, 2, 9}, filter.IDs)
+			return []int64{1, 2}, nil
+		},
+		deleteFunc: func(ctx context.Context, id, expectedVersion int64) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}, nil, nil)
+
+	ids, err := svc.BulkDelete(ctx, LinksFilter{IDs: []int64{1, 2, 9}})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, ids)
+	require.Equal(t, []int64{1, 2}, deleted)
+
+	_, err = svc.BulkDelete(ctx, LinksFilter{})
+	require.ErrorIs(t, err, ErrEmptyBulkFilter)
+
+	_, err = svc.BulkDelete(ctx, LinksFilter{IDs: make([]int64, MaxBulkItems+1)})
+	require.ErrorIs(t, err, ErrInvalidBatchSize)
+}
+
+func TestServiceBulkPatch(t *testing.T) {
+	ctx := context.Background()
+	disabled := true
+
+	stored := map[int64]domain.Link{
+		1: {ID: 1, OriginalURL: "https://example.com/1", ShortName: "one", Tags: []string{"a"}, Version: 1},
+		2: {ID: 2, OriginalURL: "https://example.com/2", ShortName: "two", Version: 4},
+	}
+
+	repo := &stubRepo{
+		t: t,
+		lockMatchingFunc: func(ctx context.Context, filter LinksFilter) ([]int64, error) {
+			require.Equal(t, "promo", filter.Tag)
+			return []int64{1, 2}, nil
+		},
+		getForUpdateFunc: func(ctx context.Context, id int64) (domain.Link, error) {
+			return stored[id], nil
+		},
+		updateFunc: func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+			require.Equal(t, stored[id].Version, expectedVersion)
+			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+	}
+
+	audit := &stubAuditRepo{}
+	svc := New(repo, nil, nil, WithAudit(audit))
+
+	ids, err := svc.BulkPatch(ctx, LinksFilter{Tag: "promo"}, LinkPatch{Disabled: &disabled})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, ids)
+	require.Len(t, audit.events, 2)
+	require.Equal(t, int64(2), audit.events[1].LinkID)
+
+	name := "same"
+	_, err = svc.BulkPatch(ctx, LinksFilter{Tag: "promo"}, LinkPatch{ShortName: &name})
+	require.ErrorIs(t, err, ErrInvalidBulkPatch)
+
+	_, err = svc.BulkPatch(ctx, LinksFilter{Tag: "promo"}, LinkPatch{RegenerateShortName: true})
+	require.ErrorIs(t, err, ErrInvalidBulkPatch)
+
+	_, err = svc.BulkPatch(ctx, LinksFilter{Tag: "promo"}, LinkPatch{})
+	require.ErrorIs(t, err, ErrInvalidBulkPatch)
+
+	dbErr := errors.New("db down")
+	repo.updateFunc = func(ctx context.Context, id int64, originalURL, shortName string, expectedVersion int64) (domain.Link, error) {
+		if id == 2 {
+			return domain.Link{}, dbErr
+		}
+
+		return domain.Link{ID: id}, nil
+	}
+
+	_, err = svc.BulkPatch(ctx, LinksFilter{Tag: "promo"}, LinkPatch{Disabled: &disabled})
+	require.ErrorIs(t, err, dbErr)
+}
+
+func TestServiceRedirect_DisabledLink(t *testing.T) {
+	svc := New(&stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return domain.Link{ID: 1, OriginalURL: "https://example.com", ShortName: shortName, Disabled: true}, nil
+		},
+	}, &stubVisitsRepo{t: t}, nil)
+
+	_, err := svc.Redirect(context.Background(), "", "off", VisitMeta{})
+	require.ErrorIs(t, err, domain.ErrLinkDisabled)
+}
+
+func TestServiceRedirect_ActiveFrom(t *testing.T) {
+	ctx := context.Background()
+	launch := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return domain.Link{ID: 1, OriginalURL: "https://example.com/launch", ShortName: shortName, ActiveFrom: &launch}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{"before", launch.Add(-time.Second), domain.ErrLinkNotActive},
+		{"at", launch, nil},
+		{"after", launch.Add(time.Hour), nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := New(repo, nil, nil, WithClock(func() time.Time { return tc.now }))
+
+			redirection, err := svc.Redirect(ctx, "", "launch", VisitMeta{})
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+
+				_, err = svc.Preview(ctx, "", "launch")
+				require.ErrorIs(t, err, tc.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "https://example.com/launch", redirection.URL)
+		})
+	}
+}
+
+func TestServiceRedirect_TimeRules(t *testing.T) {
+	ctx := context.Background()
+	release := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	link := domain.Link{
+		ID:          1,
+		OriginalURL: "https://example.com/product",
+		ShortName:   "drop",
+		Rules: []domain.RedirectRule{
+			{Before: release, URL: "https://example.com/teaser"},
+			{After: end, URL: "https://example.com/sold-out"},
+		},
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(ctx context.Context, _, shortName string) (domain.Link, error) {
+			return link, nil
+		},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"teaser", release.Add(-time.Minute), "https://example.com/teaser"},
+		{"launch", release, "https://example.com/product"},
+		{"campaign", end.Add(-time.Minute), "https://example.com/product"},
+		{"ended", end, "https://example.com/sold-out"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var visit domain.LinkVisit
+
+			visitsRepo := &stubVisitsRepo{
+				t: t,
+				createFunc: func(ctx context.Context, v domain.LinkVisit) (int64, error) {
+					visit = v
+
+					return 1, nil
+				},
+			}
+
+			svc := New(repo, visitsRepo, nil, WithClock(func() time.Time { return tc.now }))
+			redirection, err := svc.Redirect(ctx, "", "drop", VisitMeta{})
+			require.NoError(t, err)
+			require.Equal(t, tc.want, redirection.URL)
+			require.Equal(t, tc.now, visit.CreatedAt)
+		})
+	}
+}
+
+func TestLinkInput_ActiveFromAndTimeRules(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	launch := time.Date(2026, 11, 1, 10, 0, 0, 0, paris)
+
+	link, err := LinkInput{
+		OriginalURL: "https://example.com/product",
+		ActiveFrom:  &launch,
+		Rules:       []domain.RedirectRule{{Before: launch.Add(24 * time.Hour), URL: "https://example.com/teaser"}},
+	}.link()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC), *link.ActiveFrom)
+	require.Equal(t, time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), link.Rules[0].Before)
+
+	link, err = LinkInput{OriginalURL: "https://example.com/product", ActiveFrom: &time.Time{}}.link()
+	require.NoError(t, err)
+	require.Nil(t, link.ActiveFrom)
+
+	_, err = LinkInput{
+		OriginalURL: "https://example.com/product",
+		Rules:       []domain.RedirectRule{{After: launch, Before: launch, URL: "https://example.com/teaser"}},
+	}.link()
+	require.ErrorIs(t, err, domain.ErrInvalidRedirectRules)
+}
+
+func TestServicePreview(t *testing.T) {
+	ctx := context.Background()
+	link := domain.Link{
+		ID:          3,
+		OriginalURL: "https://example.com",
+		ShortName:   "peek",
+		Title:       "Example",
+		UTM:         domain.UTM{Source: "news"},
+		Rules:       []domain.RedirectRule{{OS: domain.OSiOS, URL: "https://apps.apple.com/app/id1"}},
+		Variants: []domain.Variant{
+			{URL: "https://example.com", Weight: 1},
+			{URL: "https://example.com/b", Weight: 1},
+		},
+	}
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(_ context.Context, _, shortName string) (domain.Link, error) {
+			if shortName != link.ShortName {
+				return domain.Link{}, domain.ErrNotFound
+			}
+
+			return link, nil
+		},
+	}
+	// Create and the quota counters are not stubbed: a preview records no
+	// visit and consumes no click.
+	visitsRepo := &stubVisitsRepo{
+		t: t,
+		countByLinkFunc: func(_ context.Context, linkID int64) (int64, error) {
+			require.Equal(t, link.ID, linkID)
+
+			return 42, nil
+		},
+	}
+
+	svc := New(repo, visitsRepo, nil, WithQuota(Quota{MaxMonthlyClicks: 1}, &stubQuotaRepo{t: t}))
+
+	preview, err := svc.Preview(ctx, "", "peek")
+	require.NoError(t, err)
+	require.Equal(t, LinkPreview{
+		Link: link,
+		Destinations: []string{
+			"https://example.com?utm_source=news",
+			"https://apps.apple.com/app/id1?utm_source=news",
+			"https://example.com/b?utm_source=news",
+		},
+		Clicks: 42,
+	}, preview)
+
+	_, err = svc.Preview(ctx, "", "nope")
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	link.Disabled = true
+
+	_, err = svc.Preview(ctx, "", "peek")
+	require.ErrorIs(t, err, domain.ErrLinkDisabled)
+}
+
+func TestServiceCreateOrReuse(t *testing.T) {
+	ctx := context.Background()
+	existing := domain.Link{ID: 7, OriginalURL: "https://Example.com:443/a?b=1&utm_source=x", ShortName: "seven"}
+
+	var lookups []string
+	created := 0
+
+	repo := &stubRepo{
+		t: t,
+		getByNormalizedFunc: func(_ context.Context, _, normalizedURL string) (domain.Link, error) {
+			lookups = append(lookups, normalizedURL)
+			if normalizedURL == "https://example.com/a?b=1" {
+				return existing, nil
+			}
+
+			return domain.Link{}, domain.ErrNotFound
+		},
+		createFunc: func(_ context.Context, originalURL, shortName string) (domain.Link, error) {
+			created++
+
+			return domain.Link{ID: 8, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+	}
+
+	svc := New(repo, nil, nil, WithURLNormalization(domain.URLNormalization{StripTracking: true}))
+
+	link, isNew, err := svc.CreateOrReuse(ctx, LinkInput{OriginalURL: "HTTPS://example.COM/a?utm_medium=y&b=1"})
+	require.NoError(t, err)
+	require.False(t, isNew)
+	require.Equal(t, existing, link)
+	require.Zero(t, created)
+
+	link, isNew, err = svc.CreateOrReuse(ctx, LinkInput{OriginalURL: "https://example.com/other"})
+	require.NoError(t, err)
+	require.True(t, isNew)
+	require.Equal(t, int64(8), link.ID)
+
+	// A match with other UTM parameters is not reused.
+	_, isNew, err = svc.CreateOrReuse(ctx, LinkInput{
+		OriginalURL: "https://example.com/a?b=1",
+		UTM:         domain.UTM{Source: "news"},
+	})
+	require.NoError(t, err)
+	require.True(t, isNew)
+
+	// Nor is one with other forwarding options.
+	for _, in := range []LinkInput{
+		{OriginalURL: "https://example.com/a?b=1", ForwardPath: true},
+		{OriginalURL: "https://example.com/a?b=1", ForwardQuery: domain.ForwardQueryPreserve},
+	} {
+		_, isNew, err = svc.CreateOrReuse(ctx, in)
+		require.NoError(t, err)
+		require.True(t, isNew)
+	}
+
+	// An explicit short name skips the lookup.
+	_, isNew, err = svc.CreateOrReuse(ctx, LinkInput{OriginalURL: "https://example.com/a?b=1", ShortName: "mine"})
+	require.NoError(t, err)
+	require.True(t, isNew)
+	require.Equal(t, []string{
+		"https://example.com/a?b=1",
+		"https://example.com/other",
+		"https://example.com/a?b=1",
+		"https://example.com/a?b=1",
+		"https://example.com/a?b=1",
+	}, lookups)
+	require.Equal(t, 5, created)
+}
+
+func TestServiceURLPolicy(t *testing.T) {
+	ctx := context.Background()
+	stored := domain.Link{ID: 1, OriginalURL: "https://evil.test/old", ShortName: "old"}
+
+	var updated []string
+
+	repo := &stubRepo{
+		t: t,
+		createFunc: func(_ context.Context, originalURL, shortName string) (domain.Link, error) {
+			return domain.Link{ID: 2, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+		getForUpdateFunc: func(context.Context, int64) (domain.Link, error) {
+			return stored, nil
+		},
+		updateFunc: func(_ context.Context, id int64, originalURL, shortName string, _ int64) (domain.Link, error) {
+			updated = append(updated, originalURL)
+
+			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+	}
+
+	svc := New(repo, nil, nil, WithURLPolicy(domain.URLPolicy{
+		BlockPrivate: true,
+		SelfHosts:    []string{"sho.rt"},
+		DenyDomains:  []string{"evil.test"},
+	}))
+
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "http://127.0.0.1/admin"})
+	require.ErrorIs(t, err, domain.ErrURLPrivateHost)
+
+	_, err = svc.Create(ctx, LinkInput{OriginalURL: "https://sho.rt/r/abc"})
+	require.ErrorIs(t, err, domain.ErrURLSelfReference)
+
+	_, err = svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/"})
+	require.NoError(t, err)
+
+	// A stored destination that the policy now denies can still be edited...
+	_, err = svc.Update(ctx, 1, LinkInput{OriginalURL: stored.OriginalURL, ShortName: "old", Disabled: true}, 0)
+	require.NoError(t, err)
+
+	// ...but not changed to another denied one.
+	_, err = svc.Update(ctx, 1, LinkInput{OriginalURL: "https://evil.test/new", ShortName: "old"}, 0)
+	require.ErrorIs(t, err, domain.ErrURLDomainDenied)
+	require.Equal(t, []string{stored.OriginalURL}, updated)
+}
+
+type stubBlocklist map[string]string
+
+func (b stubBlocklist) Match(rawURL string) (string, bool) {
+	entry, ok := b[rawURL]
+
+	return entry, ok
+}
+
+func TestServiceBlocklist(t *testing.T) {
+	ctx := context.Background()
+	bl := stubBlocklist{
+		"https://phish.test/login": "phish.test",
+		"https://phish.test/other": "phish.test",
+	}
+
+	stored := map[int64]domain.Link{
+		1: {ID: 1, OriginalURL: "https://example.com/", ShortName: "ok1", Version: 1},
+		2: {ID: 2, OriginalURL: "https://phish.test/login", ShortName: "bad", Version: 4},
+		3: {ID: 3, OriginalURL: "https://phish.test/other", ShortName: "off", Version: 1, Disabled: true},
+	}
+
+	var (
+		updated []int64
+		flagged []string
+	)
+
+	repo := &stubRepo{
+		t: t,
+		streamAllFunc: func(_ context.Context, _ LinksFilter, _ Sort, fn func(domain.Link) error) error {
+			for id := int64(1); id <= 3; id++ {
+				if err := fn(stored[id]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		getForUpdateFunc: func(_ context.Context, id int64) (domain.Link, error) {
+			return stored[id], nil
+		},
+		updateFunc: func(_ context.Context, id int64, originalURL, shortName string, ver int64) (domain.Link, error) {
+			require.Equal(t, stored[id].Version, ver)
+			updated = append(updated, id)
+
+			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName, Disabled: true}, nil
+		},
+		flagFunc: func(_ context.Context, id int64, reason string) (domain.Link, error) {
+			flagged = append(flagged, reason)
+			now := time.Now()
+
+			link := stored[id]
+			link.Disabled = true
+			link.FlaggedAt = &now
+			link.FlagReason = reason
+
+			return link, nil
+		},
+	}
+	audit := &stubAuditRepo{}
+
+	svc := New(repo, nil, nil, WithBlocklist(bl), WithAudit(audit))
+
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://phish.test/login"})
+	require.ErrorIs(t, err, domain.ErrURLBlocked)
+	require.ErrorIs(t, err, domain.ErrInvalidURL)
+
+	res, err := svc.RescanBlocklist(ctx)
+	require.NoError(t, err)
+	require.Equal(t, BlocklistRescan{Scanned: 3, Flagged: 1}, res)
+	require.Equal(t, []int64{2}, updated)
+	require.Equal(t, []string{"phish.test"}, flagged)
+
+	require.Len(t, audit.events, 1)
+	require.Equal(t, domain.AuditActionFlag, audit.events[0].Action)
+	require.Equal(t, BlocklistActor, audit.events[0].Actor)
+	require.False(t, audit.events[0].Before.Disabled)
+	require.True(t, audit.events[0].After.Disabled)
+	require.Equal(t, "phish.test", audit.events[0].After.FlagReason)
+}
+
+func TestServiceRescanBlocklist_AllDestinations(t *testing.T) {
+	ctx := context.Background()
+	bl := stubBlocklist{
+		"https://phish.test/ios": "phish.test",
+		"https://evil.test/b":    "evil.test",
+	}
+
+	stored := map[int64]domain.Link{
+		1: {
+			ID:          1,
+			OriginalURL: "https://example.com/",
+			Rules:       []domain.RedirectRule{{OS: domain.OSiOS, URL: "https://phish.test/ios"}},
+		},
+		2: {
+			ID:          2,
+			OriginalURL: "https://example.com/",
+			Variants: []domain.Variant{
+				{URL: "https://example.com/a", Weight: 1},
+				{URL: "https://evil.test/b", Weight: 1},
+			},
+		},
+		3: {ID: 3, OriginalURL: "https://example.com/"},
+	}
+
+	var flagged []string
+
+	repo := &stubRepo{
+		t: t,
+		streamAllFunc: func(_ context.Context, _ LinksFilter, _ Sort, fn func(domain.Link) error) error {
+			for id := int64(1); id <= 3; id++ {
+				if err := fn(stored[id]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		getForUpdateFunc: func(_ context.Context, id int64) (domain.Link, error) {
+			return stored[id], nil
+		},
+		updateFunc: func(_ context.Context, id int64, originalURL, shortName string, _ int64) (domain.Link, error) {
+			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName, Disabled: true}, nil
+		},
+		flagFunc: func(_ context.Context, id int64, reason string) (domain.Link, error) {
+			flagged = append(flagged, reason)
+
+			return stored[id], nil
+		},
+	}
+
+	svc := New(repo, nil, nil, WithBlocklist(bl))
+
+	res, err := svc.RescanBlocklist(ctx)
+	require.NoError(t, err)
+	require.Equal(t, BlocklistRescan{Scanned: 3, Flagged: 2}, res)
+	require.Equal(t, []string{"phish.test", "evil.test"}, flagged)
+}
+
+func TestServiceRescanBlocklist_Disabled(t *testing.T) {
+	svc := New(&stubRepo{t: t}, nil, nil)
+
+	_, err := svc.RescanBlocklist(context.Background())
+	require.Error(t, err)
+}
+
+func TestServiceShortNamePolicy(t *testing.T) {
+	ctx := context.Background()
+	stored := domain.Link{ID: 1, OriginalURL: "https://example.com/", ShortName: "admin"}
+
+	var created []string
+
+	repo := &stubRepo{
+		t: t,
+		createFunc: func(_ context.Context, originalURL, shortName string) (domain.Link, error) {
+			created = append(created, shortName)
+
+			return domain.Link{ID: 2, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+		getForUpdateFunc: func(context.Context, int64) (domain.Link, error) {
+			return stored, nil
+		},
+		updateFunc: func(_ context.Context, id int64, originalURL, shortName string, _ int64) (domain.Link, error) {
+			return domain.Link{ID: id, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+	}
+
+	// Denying every letter rejects practically every generated name.
+	denied := make([]string, 0, 26)
+	for c := 'a'; c <= 'z'; c++ {
+		denied = append(denied, string(c))
+	}
+
+	svc := New(repo, nil, nil, WithShortNamePolicy(domain.ShortNamePolicy{Denied: []string{"nasty"}}))
+
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/", ShortName: "API"})
+	require.ErrorIs(t, err, domain.ErrShortNameReserved)
+
+	_, err = svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/", ShortName: "so-n4sty"})
+	require.ErrorIs(t, err, domain.ErrShortNameDenied)
+	require.Empty(t, created)
+
+	// A stored reserved name can still be updated...
+	_, err = svc.Update(ctx, 1, LinkInput{OriginalURL: stored.OriginalURL, ShortName: "admin", Disabled: true}, 0)
+	require.NoError(t, err)
+
+	// ...but not renamed to another one.
+	_, err = svc.Update(ctx, 1, LinkInput{OriginalURL: stored.OriginalURL, ShortName: "login"}, 0)
+	require.ErrorIs(t, err, domain.ErrShortNameReserved)
+
+	svc = New(repo, nil, nil, WithShortNamePolicy(domain.ShortNamePolicy{Denied: denied}))
+
+	_, err = svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/"})
+	require.ErrorIs(t, err, errGeneratedShortNameDenied)
+	require.Empty(t, created)
+}
+
+func TestServiceCreate_ShortNameStrategy(t *testing.T) {
+	ctx := context.Background()
+
+	var created []string
+
+	repo := &stubRepo{
+		t: t,
+		createFunc: func(_ context.Context, originalURL, shortName string) (domain.Link, error) {
+			created = append(created, shortName)
+
+			return domain.Link{ID: 1, OriginalURL: originalURL, ShortName: shortName}, nil
+		},
+	}
+
+	svc := New(repo, nil, nil,
+		WithShortNameGenerator(StrategySequential, NewSequentialGenerator(&counterSeq{})),
+		WithShortNameStrategy(StrategySequential),
+	)
+
+	_, err := svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/"})
+	require.NoError(t, err)
+
+	_, err = svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/", ShortNameStrategy: StrategyWords})
+	require.NoError(t, err)
+
+	_, err = svc.Create(ctx, LinkInput{OriginalURL: "https://example.com/", ShortNameStrategy: "uuid"})
+	require.ErrorIs(t, err, ErrUnknownShortNameStrategy)
+
+	require.Len(t, created, 2)
+	require.Equal(t, "101", created[0])
+	require.Regexp(t, `^[a-z]+-[a-z]+-[0-9]+$`, created[1])
+}
+
+func TestServiceGetByShortName_CaseInsensitive(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &stubRepo{
+		t: t,
+		getByFoldFunc: func(_ context.Context, _, shortName string) (domain.Link, error) {
+			require.Equal(t, "ABC123", shortName)
+
+			return domain.Link{ID: 1, ShortName: "abc123"}, nil
+		},
+	}
+
+	svc := New(repo, nil, nil, WithCaseInsensitiveShortNames(true))
+
+	link, err := svc.GetByShortName(ctx, "", "ABC123")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), link.ID)
+}
+
+type stubDomainsRepo struct {
+	DomainsRepo
+
+	hosts   map[string]struct{}
+	created []string
+}
+
+func (s *stubDomainsRepo) GetByHost(_ context.Context, host string) (domain.Domain, error) {
+	if _, ok := s.hosts[host]; !ok {
+		return domain.Domain{}, domain.ErrNotFound
+	}
+
+	return domain.Domain{ID: 1, Host: host}, nil
+}
+
+func (s *stubDomainsRepo) Create(_ context.Context, host string) (domain.Domain, error) {
+	s.created = append(s.created, host)
+
+	return domain.Domain{ID: 2, Host: host}, nil
+}
+
+func TestServiceDomainRouting(t *testing.T) {
+	ctx := context.Background()
+
+	var lookups []string
+
+	repo := &stubRepo{
+		t: t,
+		getByShortNameFunc: func(_ context.Context, host, shortName string) (domain.Link, error) {
+			lookups = append(lookups, host)
+
+			return domain.Link{ID: 1, ShortName: shortName, Domain: host}, nil
+		},
+	}
+
+	domains := &stubDomainsRepo{hosts: map[string]struct{}{
+		"go.brand-a.com": {},
+		"go.brand-b.com": {},
+	}}
+
+	svc := New(repo, nil, nil, WithDomains(domains, DomainRouting{
+		DefaultHost: "sho.rt:8080",
+		Fallback:    "go.brand-b.com",
+	}))
+
+	for _, host := range []string{"sho.rt", "SHO.RT:8080", "go.brand-a.com:443", "unknown.example"} {
+		_, err := svc.GetByShortName(ctx, host, "abc123")
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, []string{"", "", "go.brand-a.com", "go.brand-b.com"}, lookups)
+
+	lookups = nil
+	svc = New(repo, nil, nil, WithDomains(domains, DomainRouting{DefaultHost: "sho.rt"}))
+
+	_, err := svc.GetByShortName(ctx, "unknown.example", "abc123")
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, lookups)
+}
+
+func TestServiceCreate_RegisteredDomainDestination(t *testing.T) {
+	ctx := context.Background()
+
+	domains := &stubDomainsRepo{hosts: map[string]struct{}{"go.brand-a.com": {}}}
+	svc := New(&stubRepo{t: t}, nil, nil, WithDomains(domains, DomainRouting{DefaultHost: "sho.rt"}))
+
+	for _, in := range []LinkInput{
+		{OriginalURL: "https://go.brand-a.com/x"},
+		{OriginalURL: "https://GO.Brand-A.com.:443/x"},
+		{
+			OriginalURL: "https://example.com/",
+			Rules:       []domain.RedirectRule{{OS: domain.OSiOS, URL: "https://go.brand-a.com/ios"}},
+		},
+		{
+			OriginalURL: "https://example.com/",
+			Variants: []domain.Variant{
+				{URL: "https://example.com/a", Weight: 1},
+				{URL: "https://go.brand-a.com/b", Weight: 1},
+			},
+		},
+	} {
+		_, err := svc.Create(ctx, in)
+		require.ErrorIs(t, err, domain.ErrURLSelfReference, in)
+	}
+}
+
+func TestServiceCreateOrReuse_Domain(t *testing.T) {
+	ctx := context.Background()
+
+	var hosts []string
+
+	repo := &stubRepo{
+		t: t,
+		getByNormalizedFunc: func(_ context.Context, host, _ string) (domain.Link, error) {
+			hosts = append(hosts, host)
+
+			return domain.Link{ID: 1, Domain: host}, nil
+		},
+	}
+
+	svc := New(repo, nil, nil, WithDomains(&stubDomainsRepo{}, DomainRouting{DefaultHost: "sho.rt"}))
+
+	for _, host := range []string{"Go.Example.com", "sho.rt", ""} {
+		_, _, err := svc.CreateOrReuse(ctx, LinkInput{OriginalURL: "https://example.com", Domain: host})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, []string{"go.example.com", "", ""}, hosts)
+
+	_, _, err := svc.CreateOrReuse(ctx, LinkInput{OriginalURL: "https://example.com", Domain: "bad_host"})
+	require.ErrorIs(t, err, domain.ErrInvalidDomain)
+}
+
+func TestServiceCreateDomain(t *testing.T) {
+	ctx := context.Background()
+
+	domains := &stubDomainsRepo{}
+	svc := New(&stubRepo{t: t}, nil, nil, WithDomains(domains, DomainRouting{DefaultHost: "sho.rt"}))
+
+	d, err := svc.CreateDomain(ctx, " Go.Example.com. ")
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", d.Host)
+
+	_, err = svc.CreateDomain(ctx, "sho.rt")
+	require.ErrorIs(t, err, domain.ErrDomainConflict)
+
+	for _, host := range []string{"localhost", "10.0.0.1", "-bad.example.com", "a..b"} {
+		_, err = svc.CreateDomain(ctx, host)
+		require.ErrorIs(t, err, domain.ErrInvalidDomain, host)
+	}
+
+	require.Equal(t, []string{"go.example.com"}, domains.created)
+}
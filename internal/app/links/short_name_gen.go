@@ -0,0 +1,217 @@
+package links
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Short name generation strategies, selectable per request with
+// LinkInput.ShortNameStrategy.
+const (
+	StrategyRandom     = "random"
+	StrategySequential = "sequential"
+	StrategyHashID     = "hashid"
+	StrategyWords      = "words"
+)
+
+const (
+	// DefaultShortNameAlphabet is used by the random strategy by default.
+	DefaultShortNameAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// ReadableShortNameAlphabet leaves out the look-alikes 0, O, 1, l and I.
+	ReadableShortNameAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	DefaultShortNameLength = 8
+
+	base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// minCodeLength matches the shortest name domain.ValidateShortName
+	// accepts.
+	minCodeLength = 3
+)
+
+// ShortNameGenerator proposes names for links saved without one. The service
+// redraws names that fail the short name policy or are already taken.
+type ShortNameGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// ShortNameSequence hands out increasing numbers that are never reused, even
+// when the transaction asking for them rolls back.
+type ShortNameSequence interface {
+	NextShortNameSeq(ctx context.Context) (int64, error)
+}
+
+// RandomGenerator draws Length characters uniformly from Alphabet, which must
+// hold 2 to 62 distinct ASCII letters and digits.
+type RandomGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+func (g RandomGenerator) Generate(context.Context) (string, error) {
+	alphaLen := len(g.Alphabet)
+	cutoff := (256 / alphaLen) * alphaLen
+
+	out := make([]byte, g.Length)
+	filled := 0
+
+	var buf [32]byte
+	for filled < g.Length {
+		_, err := rand.Read(buf[:])
+		if err != nil {
+			return "", fmt.Errorf("rand read: %w", err)
+		}
+
+		for _, b := range buf {
+			if filled >= g.Length {
+				break
+			}
+
+			if int(b) >= cutoff {
+				continue
+			}
+
+			out[filled] = g.Alphabet[int(b)%alphaLen]
+			filled++
+		}
+	}
+
+	return string(out), nil
+}
+
+// SequentialGenerator encodes the next sequence value in base62. Codes never
+// collide with each other, only with custom names.
+type SequentialGenerator struct {
+	seq ShortNameSequence
+}
+
+func NewSequentialGenerator(seq ShortNameSequence) SequentialGenerator {
+	return SequentialGenerator{seq: seq}
+}
+
+func (g SequentialGenerator) Generate(ctx context.Context) (string, error) {
+	n, err := g.seq.NextShortNameSeq(ctx)
+	if err != nil {
+		return "", fmt.Errorf("next short name seq: %w", err)
+	}
+
+	// Skip the values that would encode shorter than minCodeLength.
+	return encodeBase(n+pow(len(base62Alphabet), minCodeLength-1), []byte(base62Alphabet)), nil
+}
+
+// HashIDGenerator encodes sequence values like hashids do: a salted alphabet
+// hides the order of the codes while keeping them collision-free.
+type HashIDGenerator struct {
+	seq       ShortNameSequence
+	alphabet  []byte
+	salt      string
+	minLength int
+}
+
+// NewHashIDGenerator returns a generator producing codes of at least
+// minLength (3 to 12) characters from alphabet (2 to 62 distinct ASCII
+// letters and digits).
+func NewHashIDGenerator(seq ShortNameSequence, alphabet, salt string, minLength int) HashIDGenerator {
+	return HashIDGenerator{
+		seq:       seq,
+		alphabet:  shuffle([]byte(alphabet), salt),
+		salt:      salt,
+		minLength: minLength,
+	}
+}
+
+func (g HashIDGenerator) Generate(ctx context.Context) (string, error) {
+	n, err := g.seq.NextShortNameSeq(ctx)
+	if err != nil {
+		return "", fmt.Errorf("next short name seq: %w", err)
+	}
+
+	return g.encode(n), nil
+}
+
+// encode prefixes the digits of n with a lottery character that picks the
+// alphabet they are written in, so each code decodes to exactly one n.
+func (g HashIDGenerator) encode(n int64) string {
+	n += pow(len(g.alphabet), g.minLength-2)
+
+	lottery := g.alphabet[n%int64(len(g.alphabet))]
+	digits := shuffle(append([]byte(nil), g.alphabet...), string(lottery)+g.salt)
+
+	return string(lottery) + encodeBase(n, digits)
+}
+
+// WordsGenerator produces readable codes such as "brave-otter-42".
+type WordsGenerator struct{}
+
+func (WordsGenerator) Generate(context.Context) (string, error) {
+	adj, err := randIndex(len(codeAdjectives))
+	if err != nil {
+		return "", err
+	}
+
+	noun, err := randIndex(len(codeNouns))
+	if err != nil {
+		return "", err
+	}
+
+	num, err := randIndex(100)
+	if err != nil {
+		return "", err
+	}
+
+	return codeAdjectives[adj] + "-" + codeNouns[noun] + "-" + strconv.Itoa(num), nil
+}
+
+func randIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("rand int: %w", err)
+	}
+
+	return int(v.Int64()), nil
+}
+
+func encodeBase(n int64, alphabet []byte) string {
+	base := int64(len(alphabet))
+
+	var buf [64]byte
+
+	i := len(buf)
+	for {
+		i--
+		buf[i] = alphabet[n%base]
+
+		n /= base
+		if n == 0 {
+			return string(buf[i:])
+		}
+	}
+}
+
+// shuffle permutes alphabet in place, deterministically for a given salt.
+func shuffle(alphabet []byte, salt string) []byte {
+	if salt == "" {
+		return alphabet
+	}
+
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+		v++
+	}
+
+	return alphabet
+}
+
+func pow(base, exp int) int64 {
+	out := int64(1)
+	for range exp {
+		out *= int64(base)
+	}
+
+	return out
+}
@@ -0,0 +1,82 @@
+package links
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+type counterSeq struct {
+	next int64
+}
+
+func (c *counterSeq) NextShortNameSeq(context.Context) (int64, error) {
+	c.next++
+
+	return c.next, nil
+}
+
+func TestRandomGenerator(t *testing.T) {
+	gen := RandomGenerator{Length: 12, Alphabet: ReadableShortNameAlphabet}
+
+	for range 100 {
+		name, err := gen.Generate(context.Background())
+		require.NoError(t, err)
+		require.Len(t, name, 12)
+		require.False(t, strings.ContainsAny(name, "0O1lI"), name)
+		require.NoError(t, domain.ValidateShortName(name))
+	}
+}
+
+func TestSequentialGenerator(t *testing.T) {
+	gen := NewSequentialGenerator(&counterSeq{})
+
+	var names []string
+
+	for range 3 {
+		name, err := gen.Generate(context.Background())
+		require.NoError(t, err)
+
+		names = append(names, name)
+	}
+
+	require.Equal(t, []string{"101", "102", "103"}, names)
+}
+
+func TestHashIDGenerator(t *testing.T) {
+	gen := NewHashIDGenerator(&counterSeq{}, DefaultShortNameAlphabet, "pepper", 6)
+	seen := make(map[string]struct{})
+
+	for range 20000 {
+		name, err := gen.Generate(context.Background())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(name), 6)
+		require.NoError(t, domain.ValidateShortName(name))
+
+		_, dup := seen[name]
+		require.False(t, dup, name)
+		seen[name] = struct{}{}
+	}
+
+	// The salt changes every code.
+	other := NewHashIDGenerator(nil, DefaultShortNameAlphabet, "salt", 6)
+	require.NotEqual(t, gen.encode(1), other.encode(1))
+	require.Equal(t, gen.encode(1), NewHashIDGenerator(nil, DefaultShortNameAlphabet, "pepper", 6).encode(1))
+}
+
+func TestWordsGenerator(t *testing.T) {
+	re := regexp.MustCompile(`^[a-z]+-[a-z]+-[0-9]{1,2}$`)
+
+	for range 100 {
+		name, err := WordsGenerator{}.Generate(context.Background())
+		require.NoError(t, err)
+		require.Regexp(t, re, name)
+		require.NoError(t, domain.ValidateShortName(name))
+		require.NoError(t, domain.ShortNamePolicy{}.Check(name))
+	}
+}
@@ -0,0 +1,27 @@
+package links
+
+// Word lists of the words strategy: short, common and neutral, so codes are
+// easy to read aloud and never need the denylist.
+var (
+	codeAdjectives = []string{
+		"amber", "bold", "brave", "brisk", "calm", "clear", "clever", "cosy",
+		"crisp", "daring", "eager", "early", "fair", "fancy", "fast", "fresh",
+		"gentle", "glad", "golden", "grand", "green", "happy", "honest", "jolly",
+		"keen", "kind", "lively", "lucky", "merry", "mighty", "misty", "modest",
+		"neat", "nimble", "noble", "polite", "proud", "quick", "quiet", "rapid",
+		"ready", "rosy", "royal", "rustic", "shiny", "silver", "simple", "smart",
+		"snowy", "solid", "sunny", "swift", "tidy", "tiny", "upbeat", "vivid",
+		"warm", "wise", "witty", "young", "zesty", "azure", "breezy", "cheery",
+	}
+
+	codeNouns = []string{
+		"acorn", "anchor", "apple", "badger", "beacon", "bison", "breeze", "brook",
+		"canyon", "cedar", "cloud", "comet", "coral", "crane", "delta", "dune",
+		"eagle", "falcon", "fern", "finch", "forest", "fox", "galaxy", "garden",
+		"glacier", "harbor", "hazel", "heron", "island", "lagoon", "lake", "lark",
+		"lemon", "lily", "lynx", "maple", "meadow", "meteor", "moose", "nebula",
+		"oak", "ocean", "orbit", "otter", "owl", "panda", "pebble", "pine",
+		"planet", "pond", "prairie", "quartz", "raven", "reef", "river", "robin",
+		"sparrow", "spruce", "star", "summit", "tiger", "tulip", "valley", "willow",
+	}
+)
@@ -18,6 +18,8 @@ const (
 	SortFieldStatus      SortField = "status"
 	SortFieldReferer     SortField = "referer"
 	SortFieldCreatedAt   SortField = "created_at"
+	SortFieldAction      SortField = "action"
+	SortFieldActor       SortField = "actor"
 )
 
 type Sort struct {
@@ -27,3 +29,7 @@ type Sort struct {
 
 var DefaultLinksSort = Sort{Field: SortFieldID, Order: SortAsc}
 var DefaultLinkVisitsSort = Sort{Field: SortFieldCreatedAt, Order: SortDesc}
+
+var DefaultAuditSort = Sort{Field: SortFieldCreatedAt, Order: SortDesc}
+
+var DefaultRevisionsSort = Sort{Field: SortFieldID, Order: SortDesc}
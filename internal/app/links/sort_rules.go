@@ -26,3 +26,20 @@ func AllowedLinkVisitsSortFields() AllowedSortFields {
 		SortFieldCreatedAt: {},
 	}
 }
+
+func AllowedAuditSortFields() AllowedSortFields {
+	return AllowedSortFields{
+		SortFieldID:        {},
+		SortFieldLinkID:    {},
+		SortFieldAction:    {},
+		SortFieldActor:     {},
+		SortFieldCreatedAt: {},
+	}
+}
+
+func AllowedRevisionsSortFields() AllowedSortFields {
+	return AllowedSortFields{
+		SortFieldID:        {},
+		SortFieldCreatedAt: {},
+	}
+}
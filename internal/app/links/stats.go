@@ -0,0 +1,50 @@
+package links
+
+import (
+	"context"
+	"fmt"
+
+	"code/internal/domain"
+)
+
+// Visit stats groupings.
+const (
+	// StatsGroupCampaign groups visits by the UTM campaign of their link;
+	// links without one share the empty key.
+	StatsGroupCampaign = "campaign"
+	// StatsGroupRule groups visits by the index of the redirect rule they
+	// matched; visits sent to the link destination share the empty key.
+	StatsGroupRule = "rule"
+	// StatsGroupVariant groups visits by the index of the variant drawn for
+	// them; visits without one share the empty key.
+	StatsGroupVariant = "variant"
+)
+
+type VisitStatsQuery struct {
+	GroupBy string
+	// LinkID limits the stats to the visits of one link when non-zero.
+	LinkID int64
+}
+
+var statsGroups = map[string]struct{}{
+	StatsGroupCampaign: {},
+	StatsGroupRule:     {},
+	StatsGroupVariant:  {},
+}
+
+func (s *Service) VisitStats(ctx context.Context, query VisitStatsQuery) ([]domain.VisitStat, error) {
+	if s.visitsRepo == nil {
+		return nil, errVisitsRepoNil
+	}
+
+	if _, ok := statsGroups[query.GroupBy]; !ok {
+		return nil, ErrInvalidStatsGroup
+	}
+
+	stats, err := s.visitsRepo.Stats(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("link visits stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,16 @@
+package links
+
+import "context"
+
+// TxManager runs fn inside a single unit of work. Nested calls must be
+// supported so that a failed inner step can be rolled back on its own.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NopTxManager runs fn directly without any transaction.
+type NopTxManager struct{}
+
+func (NopTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
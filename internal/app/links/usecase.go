@@ -10,10 +10,34 @@ import (
 type UseCase interface {
 	ListLinks(ctx context.Context, query LinksQuery) ([]domain.Link, int64, error)
 	Get(ctx context.Context, id int64) (domain.Link, error)
-	GetByShortName(ctx context.Context, shortName string) (domain.Link, error)
-	Redirect(ctx context.Context, shortName string, meta VisitMeta) (string, int, error)
-	Create(ctx context.Context, originalURL, shortName string) (domain.Link, error)
-	Update(ctx context.Context, id int64, originalURL, shortName string) (domain.Link, error)
-	Delete(ctx context.Context, id int64) error
+	// GetByShortName and Redirect resolve shortName among the links of the
+	// domain serving host, the Host of the request.
+	GetByShortName(ctx context.Context, host, shortName string) (domain.Link, error)
+	Redirect(ctx context.Context, host, shortName string, meta VisitMeta) (Redirection, error)
+	// Preview resolves shortName like Redirect but records no visit.
+	Preview(ctx context.Context, host, shortName string) (LinkPreview, error)
+	Create(ctx context.Context, in LinkInput) (domain.Link, error)
+	CreateOrReuse(ctx context.Context, in LinkInput) (domain.Link, bool, error)
+	// Update and Delete apply only when ifVersion matches the stored link
+	// version; zero skips the check.
+	Update(ctx context.Context, id int64, in LinkInput, ifVersion int64) (domain.Link, error)
+	Delete(ctx context.Context, id int64, ifVersion int64) error
+	Patch(ctx context.Context, id int64, patch LinkPatch, ifVersion int64) (domain.Link, error)
+	BulkCreate(ctx context.Context, items []LinkInput, mode BulkMode) ([]BulkItemResult, error)
+	BulkDelete(ctx context.Context, filter LinksFilter) ([]int64, error)
+	BulkPatch(ctx context.Context, filter LinksFilter, patch LinkPatch) ([]int64, error)
+	ImportLinks(ctx context.Context, src ImportSource, opts ImportOptions) (ImportResult, error)
 	ListLinkVisits(ctx context.Context, query LinkVisitsQuery) ([]domain.LinkVisit, int64, error)
+	VisitStats(ctx context.Context, query VisitStatsQuery) ([]domain.VisitStat, error)
+	ExportLinks(ctx context.Context, filter LinksFilter, sort Sort, fn func(domain.Link) error) error
+	ExportLinkVisits(ctx context.Context, sort Sort, fn func(domain.LinkVisit) error) error
+	Usage(ctx context.Context) (Usage, error)
+	ListAuditEvents(ctx context.Context, query AuditQuery) ([]domain.AuditEvent, int64, error)
+	ListLinkRevisions(ctx context.Context, linkID int64, query LinkRevisionsQuery) ([]domain.LinkRevision, int64, error)
+	RevertLink(ctx context.Context, id, revisionID int64) (domain.Link, error)
+	ListFlaggedLinks(ctx context.Context) ([]domain.Link, error)
+	ListDomains(ctx context.Context) ([]domain.Domain, error)
+	GetDomain(ctx context.Context, id int64) (domain.Domain, error)
+	CreateDomain(ctx context.Context, host string) (domain.Domain, error)
+	DeleteDomain(ctx context.Context, id int64) error
 }
@@ -4,4 +4,30 @@ type VisitMeta struct {
 	IP        string
 	UserAgent string
 	Referer   string
+	// Path is the requested path with its query string; it is recorded on
+	// the visit.
+	Path string
+	// ExtraPath is the part of the request path after the short name and
+	// RawQuery the query string; links configured to forward them pass them
+	// on to the destination.
+	ExtraPath string
+	RawQuery  string
+	// Country is the ISO 3166-1 alpha-2 code of the visitor when the caller
+	// knows it, e.g. from a trusted proxy header; otherwise the GeoIP
+	// resolver, if any, looks up IP.
+	Country string
+	// Variant is the variant remembered for the visitor by an earlier
+	// Redirection with StickyVariant, if any.
+	Variant *int
+}
+
+// Redirection is where Redirect sends the visitor.
+type Redirection struct {
+	URL    string
+	Status int
+	// Variant is the index of the variant drawn for the visitor; nil when
+	// the link has none or a rule matched. StickyVariant asks the caller to
+	// pass it back in VisitMeta.Variant on the next visits.
+	Variant       *int
+	StickyVariant bool
 }
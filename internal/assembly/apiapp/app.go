@@ -7,23 +7,32 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 
+	"code/internal/adapters/blocklist"
+	"code/internal/adapters/geoip"
 	httpapi "code/internal/adapters/httpapi"
 	"code/internal/adapters/httpapi/handlers"
+	"code/internal/adapters/httpapi/middleware"
 	"code/internal/adapters/httpapi/stack"
 	pgrepo "code/internal/adapters/postgres"
 	"code/internal/app/links"
+	"code/internal/domain"
 	"code/internal/platform/config"
 	"code/internal/platform/postgres"
 )
 
 type App struct {
-	cfg    config.Config
-	db     *sql.DB
-	router http.Handler
+	cfg         config.Config
+	db          *sql.DB
+	router      http.Handler
+	logger      *slog.Logger
+	idempotency *pgrepo.IdempotencyRepo
+	links       *links.Service
+	blocklist   *blocklist.File
 }
 
 func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*App, error) {
@@ -35,6 +44,36 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*App, err
 		}
 	}
 
+	shortNames, err := LoadShortNamePolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bl   *blocklist.File
+		opts = []links.Option{links.WithShortNamePolicy(shortNames)}
+	)
+
+	if cfg.BlocklistFile != "" {
+		bl, err = blocklist.Open(cfg.BlocklistFile)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, links.WithBlocklist(bl))
+	}
+
+	if cfg.GeoIPFile != "" {
+		geo, err := geoip.Open(cfg.GeoIPFile)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("geoip database loaded", "path", cfg.GeoIPFile, "ranges", geo.Len())
+
+		opts = append(opts, links.WithGeoIP(geo))
+	}
+
 	db, err := postgres.Open(ctx, postgres.OpenConfig{
 		DSN:             cfg.DatabaseURL,
 		MaxOpenConns:    cfg.DBMaxOpenConns,
@@ -45,17 +84,21 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*App, err
 		return nil, fmt.Errorf("open db: %w", err)
 	}
 
-	repo := pgrepo.NewRepo(db)
-	visitsRepo := pgrepo.NewLinkVisitsRepo(db)
+	if cfg.ShortNameCaseInsensitive {
+		if err := requireCaseInsensitiveShortNames(ctx, pgrepo.NewRepo(db)); err != nil {
+			_ = db.Close()
 
-	appLogger := linksSlogLogger{l: logger}
-	svc := links.New(repo, visitsRepo, appLogger)
+			return nil, err
+		}
+	}
+
+	svc := NewLinksService(db, cfg, logger, opts...)
 
 	plugins := []httpapi.EnginePlugin{
 		stack.Logger(),
 		stack.RequestID(),
 		stack.Recovery(),
-		stack.RequestTimeout(cfg.RequestBudget),
+		stack.RequestTimeout(cfg.RequestBudget, transferTimeouts(cfg.TransferBudget)...),
 		stack.CORS(cfg.CORSAllowedOrigins),
 	}
 	if cfg.SentryDSN != "" {
@@ -64,12 +107,76 @@ func New(ctx context.Context, cfg config.Config, logger *slog.Logger) (*App, err
 
 	r := httpapi.NewEngine(plugins...)
 
+	idempotency := pgrepo.NewIdempotencyRepo(db)
+	idempotencyLease := cfg.RequestBudget + idempotencyLeaseMargin
+
 	httpapi.RegisterRoutes(r, httpapi.RouterDeps{
-		Links:   svc,
-		BaseURL: cfg.BaseURL,
+		Links:         svc,
+		BaseURL:       cfg.BaseURL,
+		RootRedirects: cfg.RedirectPathMode == config.RedirectPathRoot,
+		Idempotency:   middleware.Idempotency(idempotencyStore{repo: idempotency}, cfg.IdempotencyTTL, idempotencyLease),
 	})
 
-	return &App{cfg: cfg, db: db, router: r}, nil
+	return &App{
+		cfg:         cfg,
+		db:          db,
+		router:      r,
+		logger:      logger,
+		idempotency: idempotency,
+		links:       svc,
+		blocklist:   bl,
+	}, nil
+}
+
+// NewLinksService wires the links service on top of PostgreSQL; it is shared
+// by the API and the CLI tools. opts are applied after the configured ones.
+func NewLinksService(db *sql.DB, cfg config.Config, logger *slog.Logger, opts ...links.Option) *links.Service {
+	repo := pgrepo.NewRepo(db)
+
+	base := []links.Option{
+		links.WithTxManager(pgrepo.NewTxManager(db)),
+		links.WithQuota(links.Quota{
+			MaxLinks:         cfg.QuotaMaxLinks,
+			MaxMonthlyClicks: cfg.QuotaMaxMonthlyClicks,
+			BlockRedirects:   cfg.QuotaBlockRedirects,
+		}, pgrepo.NewQuotaRepo(db)),
+		links.WithAudit(pgrepo.NewAuditRepo(db)),
+		links.WithRevisions(pgrepo.NewRevisionsRepo(db)),
+		links.WithURLNormalization(domain.URLNormalization{
+			SortQuery:     cfg.URLSortQuery,
+			StripTracking: cfg.URLStripTracking,
+		}),
+		links.WithURLPolicy(domain.URLPolicy{
+			BlockPrivate: cfg.URLBlockPrivate,
+			SelfHosts:    cfg.URLSelfHosts,
+			AllowDomains: cfg.URLAllowedDomains,
+			DenyDomains:  cfg.URLDeniedDomains,
+		}),
+	}
+
+	base = append(base, shortNameGenerators(cfg, repo)...)
+	base = append(base, links.WithCaseInsensitiveShortNames(cfg.ShortNameCaseInsensitive))
+
+	// config.Load validated BASE_URL, so it parses.
+	baseURL, _ := url.Parse(cfg.BaseURL)
+	base = append(base, links.WithDomains(pgrepo.NewDomainsRepo(db), links.DomainRouting{
+		DefaultHost: baseURL.Host,
+		Fallback:    cfg.DomainFallback,
+	}))
+
+	return links.New(repo, pgrepo.NewLinkVisitsRepo(db), linksSlogLogger{l: logger},
+		append(base, opts...)...)
+}
+
+func transferTimeouts(d time.Duration) []middleware.RouteTimeout {
+	routes := httpapi.TransferRoutes()
+
+	out := make([]middleware.RouteTimeout, 0, len(routes))
+	for _, path := range routes {
+		out = append(out, middleware.RouteTimeout{Path: path, Timeout: d})
+	}
+
+	return out
 }
 
 func (a *App) Close() error {
@@ -99,6 +206,21 @@ func (a *App) Run(ctx context.Context) error {
 		errCh <- srv.ListenAndServe()
 	}()
 
+	if a.idempotency != nil {
+		pruneCtx, stopPrune := context.WithCancel(ctx)
+		defer stopPrune()
+
+		go pruneIdempotencyKeys(pruneCtx, a.idempotency, min(a.cfg.IdempotencyTTL, idempotencyPruneInterval), a.logger)
+	}
+
+	if a.blocklist != nil {
+		blocklistCtx, stopBlocklist := context.WithCancel(ctx)
+		defer stopBlocklist()
+
+		go runBlocklist(blocklistCtx, a.blocklist, a.links, a.cfg.BlocklistReloadInterval,
+			a.cfg.BlocklistRescanInterval, a.logger)
+	}
+
 	select {
 	case err := <-errCh:
 		if errors.Is(err, http.ErrServerClosed) {
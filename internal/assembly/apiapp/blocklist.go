@@ -0,0 +1,69 @@
+package apiapp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"code/internal/adapters/blocklist"
+	"code/internal/app/links"
+)
+
+type blocklistRescanner interface {
+	RescanBlocklist(ctx context.Context) (links.BlocklistRescan, error)
+}
+
+// runBlocklist hot-reloads the blocklist file and rescans existing links on
+// start, on every rescan tick and after each reload that changed the list,
+// until ctx is done.
+func runBlocklist(
+	ctx context.Context,
+	file *blocklist.File,
+	svc blocklistRescanner,
+	reloadInterval, rescanInterval time.Duration,
+	logger *slog.Logger,
+) {
+	reload := time.NewTicker(reloadInterval)
+	defer reload.Stop()
+
+	rescan := time.NewTicker(rescanInterval)
+	defer rescan.Stop()
+
+	rescanBlocklist(ctx, svc, logger)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload.C:
+			changed, err := file.Reload()
+			if err != nil {
+				logger.Warn("reload blocklist", "path", file.Path(), "err", err)
+
+				continue
+			}
+
+			if changed {
+				logger.Info("blocklist reloaded", "path", file.Path(), "entries", file.Len())
+				rescanBlocklist(ctx, svc, logger)
+			}
+		case <-rescan.C:
+			rescanBlocklist(ctx, svc, logger)
+		}
+	}
+}
+
+func rescanBlocklist(ctx context.Context, svc blocklistRescanner, logger *slog.Logger) {
+	res, err := svc.RescanBlocklist(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn("rescan blocklist", "err", err)
+		}
+
+		return
+	}
+
+	if res.Flagged > 0 {
+		logger.Info("blocklist rescan disabled links", "scanned", res.Scanned, "flagged", res.Flagged)
+	}
+}
@@ -0,0 +1,81 @@
+package apiapp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"code/internal/adapters/httpapi/middleware"
+	pgrepo "code/internal/adapters/postgres"
+)
+
+// idempotencyPruneInterval caps how long expired idempotency keys linger.
+const idempotencyPruneInterval = time.Hour
+
+// idempotencyLeaseMargin is added to the request budget to get the lease of a
+// running request, leaving room for a handler that overruns its deadline.
+const idempotencyLeaseMargin = 30 * time.Second
+
+type idempotencyStore struct {
+	repo *pgrepo.IdempotencyRepo
+}
+
+func (s idempotencyStore) Claim(
+	ctx context.Context,
+	key string,
+	requestHash []byte,
+	expiresAt time.Time,
+) (middleware.IdempotencyEntry, error) {
+	rec, claimed, err := s.repo.Claim(ctx, key, requestHash, expiresAt)
+	if err != nil {
+		return middleware.IdempotencyEntry{}, err
+	}
+
+	if claimed {
+		return middleware.IdempotencyEntry{}, nil
+	}
+
+	entry := middleware.IdempotencyEntry{RequestHash: rec.RequestHash}
+	if rec.Status != 0 {
+		entry.Response = &middleware.IdempotentResponse{
+			Status: rec.Status,
+			Header: rec.Headers,
+			Body:   rec.Body,
+		}
+	}
+
+	return entry, middleware.ErrIdempotencyKeyTaken
+}
+
+func (s idempotencyStore) Save(
+	ctx context.Context,
+	key string,
+	resp middleware.IdempotentResponse,
+	expiresAt time.Time,
+) error {
+	return s.repo.Complete(ctx, key, resp.Status, resp.Header, resp.Body, expiresAt)
+}
+
+func (s idempotencyStore) Release(ctx context.Context, key string) error {
+	return s.repo.Release(ctx, key)
+}
+
+var _ middleware.IdempotencyStore = idempotencyStore{}
+
+// pruneIdempotencyKeys deletes expired keys until ctx is done. Expired keys
+// are already ignored and reclaimed; this only bounds the table size.
+func pruneIdempotencyKeys(ctx context.Context, repo *pgrepo.IdempotencyRepo, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := repo.DeleteExpired(ctx); err != nil && ctx.Err() == nil && logger != nil {
+				logger.Warn("prune idempotency keys", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package apiapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	pgrepo "code/internal/adapters/postgres"
+	"code/internal/app/links"
+	"code/internal/domain"
+	"code/internal/platform/config"
+)
+
+var errCaseFoldIndexMissing = errors.New(
+	"SHORT_NAME_CASE_INSENSITIVE needs case-insensitive unique short names; run cmd/casefold -apply first")
+
+// LoadShortNamePolicy reads the short name denylist named by cfg, if any.
+func LoadShortNamePolicy(cfg config.Config) (domain.ShortNamePolicy, error) {
+	if cfg.ShortNameDenylistFile == "" {
+		return domain.ShortNamePolicy{}, nil
+	}
+
+	f, err := os.Open(cfg.ShortNameDenylistFile)
+	if err != nil {
+		return domain.ShortNamePolicy{}, fmt.Errorf("open short name denylist: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	denied, err := domain.ParseShortNameDenylist(f)
+	if err != nil {
+		return domain.ShortNamePolicy{}, fmt.Errorf("%s: %w", cfg.ShortNameDenylistFile, err)
+	}
+
+	return domain.ShortNamePolicy{Denied: denied}, nil
+}
+
+// shortNameGenerators registers every strategy with the configured settings
+// and selects the default one.
+func shortNameGenerators(cfg config.Config, seq links.ShortNameSequence) []links.Option {
+	alphabet := cfg.ShortNameAlphabet
+	if alphabet == "" {
+		alphabet = links.DefaultShortNameAlphabet
+	}
+
+	return []links.Option{
+		links.WithShortNameGenerator(links.StrategyRandom, links.RandomGenerator{
+			Length:   cfg.ShortNameLength,
+			Alphabet: alphabet,
+		}),
+		links.WithShortNameGenerator(links.StrategySequential, links.NewSequentialGenerator(seq)),
+		links.WithShortNameGenerator(links.StrategyHashID,
+			links.NewHashIDGenerator(seq, alphabet, cfg.ShortNameHashIDSalt, cfg.ShortNameHashIDMinLength)),
+		links.WithShortNameStrategy(cfg.ShortNameStrategy),
+	}
+}
+
+// requireCaseInsensitiveShortNames refuses to start in case-insensitive mode
+// while links may still differ only in case.
+func requireCaseInsensitiveShortNames(ctx context.Context, repo *pgrepo.Repo) error {
+	ok, err := repo.CaseInsensitiveShortNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errCaseFoldIndexMissing
+	}
+
+	return nil
+}
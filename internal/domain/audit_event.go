@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionRevert = "revert"
+	// AuditActionFlag records a link disabled by the blocklist rescan.
+	AuditActionFlag = "flag"
+)
+
+// AuditEvent records a single link mutation. Before is nil for creations
+// and After is nil for deletions.
+type AuditEvent struct {
+	ID        int64
+	CreatedAt time.Time
+	Action    string
+	LinkID    int64
+	Actor     string
+	RequestID string
+	IP        string
+	Before    *Link
+	After     *Link
+}
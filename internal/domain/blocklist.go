@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Blocklist holds destinations that must not be shortened. Each line of its
+// source is a host pattern, matched like URLPolicy domain patterns, or a URL
+// prefix when it contains "://". Blank lines and text after "#" are ignored.
+//
+// The zero value matches nothing.
+type Blocklist struct {
+	hosts    []string
+	prefixes []blocklistPrefix
+}
+
+type blocklistPrefix struct {
+	entry      string
+	normalized string
+}
+
+// ParseBlocklist reads a blocklist, failing on the first invalid entry.
+func ParseBlocklist(r io.Reader) (*Blocklist, error) {
+	bl := &Blocklist{}
+	sc := bufio.NewScanner(r)
+
+	for n := 1; sc.Scan(); n++ {
+		entry, _, _ := strings.Cut(sc.Text(), "#")
+
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if err := bl.add(entry); err != nil {
+			return nil, fmt.Errorf("blocklist line %d: %w: %q", n, err, entry)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read blocklist: %w", err)
+	}
+
+	return bl, nil
+}
+
+func (bl *Blocklist) add(entry string) error {
+	if strings.Contains(entry, "://") {
+		normalized, err := NormalizeURL(entry, URLNormalization{})
+		if err != nil {
+			return ErrInvalidBlocklistEntry
+		}
+
+		// A prefix without a host would block every URL of its scheme.
+		if u, err := url.Parse(normalized); err != nil || u.Hostname() == "" {
+			return ErrInvalidBlocklistEntry
+		}
+
+		bl.prefixes = append(bl.prefixes, blocklistPrefix{entry: entry, normalized: normalized})
+
+		return nil
+	}
+
+	host := canonicalHost(entry)
+	name := strings.TrimPrefix(host, "*.")
+
+	if name == "" || strings.ContainsAny(name, "/*?#@ \t") {
+		return ErrInvalidBlocklistEntry
+	}
+
+	bl.hosts = append(bl.hosts, host)
+
+	return nil
+}
+
+// Len returns the number of entries.
+func (bl *Blocklist) Len() int {
+	if bl == nil {
+		return 0
+	}
+
+	return len(bl.hosts) + len(bl.prefixes)
+}
+
+// Match returns the first entry matching rawURL. Prefixes are compared on
+// normalized URLs, so they ignore case in the scheme and host and default
+// ports.
+func (bl *Blocklist) Match(rawURL string) (string, bool) {
+	if bl.Len() == 0 {
+		return "", false
+	}
+
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", false
+	}
+
+	host := canonicalHost(u.Hostname())
+	for _, pattern := range bl.hosts {
+		if matchesAnyDomain([]string{pattern}, host) {
+			return pattern, true
+		}
+	}
+
+	if len(bl.prefixes) == 0 {
+		return "", false
+	}
+
+	normalized, err := NormalizeURL(rawURL, URLNormalization{})
+	if err != nil {
+		return "", false
+	}
+
+	for _, p := range bl.prefixes {
+		if strings.HasPrefix(normalized, p.normalized) {
+			return p.entry, true
+		}
+	}
+
+	return "", false
+}
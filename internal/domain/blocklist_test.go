@@ -0,0 +1,61 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestParseBlocklist(t *testing.T) {
+	src := `
+# phishing campaign 2026-10
+evil.test
+*.bad.test   # every subdomain
+HTTPS://Example.com:443/login
+`
+
+	bl, err := domain.ParseBlocklist(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Equal(t, 3, bl.Len())
+
+	tests := []struct {
+		name  string
+		in    string
+		entry string
+	}{
+		{"host/exact", "https://EVIL.test/x", "evil.test"},
+		{"host/subdomain_not_exact", "https://a.evil.test/", ""},
+		{"wildcard/subdomain", "http://a.b.bad.test/", "*.bad.test"},
+		{"wildcard/parent_not_matched", "http://bad.test/", ""},
+		{"prefix/normalized", "https://example.com/login?next=/", "HTTPS://Example.com:443/login"},
+		{"prefix/other_path", "https://example.com/about", ""},
+		{"prefix/other_scheme", "http://example.com/login", ""},
+		{"none", "https://example.org/", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := bl.Match(tt.in)
+			require.Equal(t, tt.entry != "", ok)
+			require.Equal(t, tt.entry, entry)
+		})
+	}
+}
+
+func TestParseBlocklist_InvalidEntry(t *testing.T) {
+	for _, src := range []string{"evil.test/path", "https://", "*."} {
+		_, err := domain.ParseBlocklist(strings.NewReader("ok.test\n" + src))
+		require.ErrorIs(t, err, domain.ErrInvalidBlocklistEntry, src)
+		require.ErrorContains(t, err, "line 2")
+	}
+}
+
+func TestBlocklist_NilMatchesNothing(t *testing.T) {
+	var bl *domain.Blocklist
+
+	_, ok := bl.Match("https://example.com/")
+	require.False(t, ok)
+}
@@ -1,10 +1,60 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNotFound          = errors.New("not found")
 	ErrInvalidURL        = errors.New("invalid url")
 	ErrInvalidShortName  = errors.New("invalid short name")
 	ErrShortNameConflict = errors.New("short name already exists")
+	ErrVersionMismatch   = errors.New("version mismatch")
+	ErrInvalidTags       = errors.New("invalid tags")
+	ErrInvalidTitle      = errors.New("invalid title")
+	ErrLinkDisabled      = errors.New("link is disabled")
+	ErrLinkNotActive     = errors.New("link is not active yet")
+)
+
+// ErrInvalidForwardQuery is an unknown query forwarding policy.
+var ErrInvalidForwardQuery = errors.New("invalid forward query policy")
+
+// ErrInvalidUTM is a UTM parameter set without a source, or with a value that
+// is too long or contains control characters.
+var ErrInvalidUTM = errors.New("invalid utm parameters")
+
+// ErrInvalidRedirectRules is a rule list that is too long or holds a rule with
+// an unknown or missing condition, an empty time window or an invalid URL.
+var ErrInvalidRedirectRules = errors.New("invalid redirect rules")
+
+// ErrInvalidVariants is an A/B split with too few or too many variants, an
+// invalid URL or weight, or only zero weights.
+var ErrInvalidVariants = errors.New("invalid variants")
+
+var (
+	ErrInvalidDomain  = errors.New("invalid domain")
+	ErrUnknownDomain  = errors.New("unknown domain")
+	ErrDomainConflict = errors.New("domain already exists")
+	ErrDomainInUse    = errors.New("domain has links")
+)
+
+// URL policy rejections; each one is also an ErrInvalidURL.
+var (
+	ErrURLPrivateHost      = fmt.Errorf("%w: private or local address", ErrInvalidURL)
+	ErrURLSelfReference    = fmt.Errorf("%w: points to this link shortener", ErrInvalidURL)
+	ErrURLDomainDenied     = fmt.Errorf("%w: domain is denied", ErrInvalidURL)
+	ErrURLDomainNotAllowed = fmt.Errorf("%w: domain is not allowed", ErrInvalidURL)
+	ErrURLBlocked          = fmt.Errorf("%w: blocklisted", ErrInvalidURL)
+)
+
+// Short name policy rejections; each one is also an ErrInvalidShortName.
+var (
+	ErrShortNameReserved = fmt.Errorf("%w: reserved", ErrInvalidShortName)
+	ErrShortNameDenied   = fmt.Errorf("%w: denied word", ErrInvalidShortName)
+)
+
+var (
+	ErrInvalidBlocklistEntry = errors.New("invalid blocklist entry")
+	ErrInvalidDenylistEntry  = errors.New("invalid denylist entry")
 )
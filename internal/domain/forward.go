@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Query forwarding policies for Link.ForwardQuery. They differ in which value
+// wins when the request and the destination set the same parameter.
+const (
+	// ForwardQueryNone drops the query string of the request.
+	ForwardQueryNone = ""
+	// ForwardQueryPreserve only adds parameters the destination lacks.
+	ForwardQueryPreserve = "preserve"
+	// ForwardQueryOverride replaces destination values with request ones.
+	ForwardQueryOverride = "override"
+	// ForwardQueryAppend keeps the values of both, destination first.
+	ForwardQueryAppend = "append"
+)
+
+// ValidateForwardQuery reports whether policy is one of the ForwardQuery
+// constants.
+func ValidateForwardQuery(policy string) error {
+	switch policy {
+	case ForwardQueryNone, ForwardQueryPreserve, ForwardQueryOverride, ForwardQueryAppend:
+		return nil
+	default:
+		return ErrInvalidForwardQuery
+	}
+}
+
+// Forward applies the forwarding options of the link to dest. With
+// ForwardPath, extraPath (the decoded request path after the short name) is
+// appended to the destination path; its "." and ".." segments cannot climb
+// above it. rawQuery is merged into the destination query per ForwardQuery.
+func (l Link) Forward(dest, extraPath, rawQuery string) (string, error) {
+	forwardPath := l.ForwardPath && strings.Trim(extraPath, "/") != ""
+	forwardQuery := l.ForwardQuery != ForwardQueryNone && rawQuery != ""
+
+	if !forwardPath && !forwardQuery {
+		return dest, nil
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	if forwardPath {
+		u = u.JoinPath(escapeSegments(extraPath)...)
+	}
+
+	if forwardQuery {
+		u.RawQuery = mergeQuery(u.Query(), rawQuery, l.ForwardQuery).Encode()
+	}
+
+	return u.String(), nil
+}
+
+// escapeSegments cleans p and escapes each of its segments, keeping a
+// trailing slash.
+func escapeSegments(p string) []string {
+	segs := strings.Split(strings.TrimPrefix(path.Clean("/"+p), "/"), "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+
+	if strings.HasSuffix(p, "/") {
+		segs[len(segs)-1] += "/"
+	}
+
+	return segs
+}
+
+// mergeQuery ignores malformed pairs of rawQuery.
+func mergeQuery(dest url.Values, rawQuery, policy string) url.Values {
+	incoming, _ := url.ParseQuery(rawQuery)
+
+	for key, values := range incoming {
+		switch policy {
+		case ForwardQueryPreserve:
+			if _, ok := dest[key]; !ok {
+				dest[key] = values
+			}
+		case ForwardQueryOverride:
+			dest[key] = values
+		case ForwardQueryAppend:
+			dest[key] = append(dest[key], values...)
+		}
+	}
+
+	return dest
+}
@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestLinkForward(t *testing.T) {
+	const dest = "https://example.com/docs?lang=en&ref=short#top"
+
+	tests := []struct {
+		name      string
+		link      domain.Link
+		extraPath string
+		rawQuery  string
+		want      string
+	}{
+		{"off", domain.Link{}, "/guide", "lang=fr", dest},
+		{"path", domain.Link{ForwardPath: true}, "/getting-started/", "lang=fr",
+			"https://example.com/docs/getting-started/?lang=en&ref=short#top"},
+		{"path/dot_segments", domain.Link{ForwardPath: true}, "/../../admin", "",
+			"https://example.com/docs/admin?lang=en&ref=short#top"},
+		{"path/escaped", domain.Link{ForwardPath: true}, "/a b?c", "",
+			"https://example.com/docs/a%20b%3Fc?lang=en&ref=short#top"},
+		{"path/only_slash", domain.Link{ForwardPath: true}, "/", "", dest},
+		{"query/preserve", domain.Link{ForwardQuery: domain.ForwardQueryPreserve}, "", "lang=fr&utm_source=x",
+			"https://example.com/docs?lang=en&ref=short&utm_source=x#top"},
+		{"query/override", domain.Link{ForwardQuery: domain.ForwardQueryOverride}, "", "lang=fr&utm_source=x",
+			"https://example.com/docs?lang=fr&ref=short&utm_source=x#top"},
+		{"query/append", domain.Link{ForwardQuery: domain.ForwardQueryAppend}, "", "lang=fr",
+			"https://example.com/docs?lang=en&lang=fr&ref=short#top"},
+		{"query/malformed_pairs_skipped", domain.Link{ForwardQuery: domain.ForwardQueryOverride}, "", "a=%zz&b=1",
+			"https://example.com/docs?b=1&lang=en&ref=short#top"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.link.Forward(dest, tc.extraPath, tc.rawQuery)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateForwardQuery(t *testing.T) {
+	for _, policy := range []string{"", "preserve", "override", "append"} {
+		require.NoError(t, domain.ValidateForwardQuery(policy))
+	}
+
+	require.ErrorIs(t, domain.ValidateForwardQuery("merge"), domain.ErrInvalidForwardQuery)
+}
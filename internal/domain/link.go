@@ -7,4 +7,63 @@ type Link struct {
 	OriginalURL string
 	ShortName   string
 	CreatedAt   time.Time
+	// Version is bumped on every update and backs optimistic concurrency.
+	Version int64
+	Tags    []string
+	// Disabled links are kept but no longer redirect.
+	Disabled bool
+	// NormalizedURL is OriginalURL in canonical form, used to find links to
+	// the same destination.
+	NormalizedURL string
+	// FlaggedAt is set when the blocklist rescan disabled the link, with the
+	// matching blocklist entry in FlagReason. Enabling the link clears both.
+	FlaggedAt  *time.Time
+	FlagReason string
+	// Domain is the host the link is served on; empty means the BASE_URL
+	// host. It is set when the link is created and never changes.
+	Domain string
+	// Title is an optional label shown on the preview page.
+	Title string
+	// ForwardPath appends the request path following the short name to the
+	// destination, and ForwardQuery merges the request query string into it
+	// (one of the ForwardQuery* policies).
+	ForwardPath  bool
+	ForwardQuery string
+	// UTM is kept apart from OriginalURL and added to it on redirect.
+	UTM UTM
+	// Rules send matching visitors elsewhere than OriginalURL, which stays
+	// the fallback; the first matching rule wins.
+	Rules []RedirectRule
+	// Variants, when set, split the visitors no rule matched between their
+	// URLs instead of sending them to OriginalURL. StickyVariant keeps a
+	// visitor on the variant first drawn for them.
+	Variants      []Variant
+	StickyVariant bool
+	// ActiveFrom, when set, is when the link goes live; it does not
+	// redirect before.
+	ActiveFrom *time.Time
+}
+
+// ActiveAt reports whether the link has gone live at t; Disabled is checked
+// separately.
+func (l Link) ActiveAt(t time.Time) bool {
+	return l.ActiveFrom == nil || !t.Before(*l.ActiveFrom)
+}
+
+// DestinationURLs returns every URL the link can redirect to, before UTM
+// parameters are added: OriginalURL, then the rule URLs, then the variant
+// URLs.
+func (l Link) DestinationURLs() []string {
+	urls := make([]string, 0, 1+len(l.Rules)+len(l.Variants))
+	urls = append(urls, l.OriginalURL)
+
+	for _, rule := range l.Rules {
+		urls = append(urls, rule.URL)
+	}
+
+	for _, variant := range l.Variants {
+		urls = append(urls, variant.URL)
+	}
+
+	return urls
 }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// LinkRevision is a snapshot of a link taken right before it was updated.
+type LinkRevision struct {
+	ID          int64
+	LinkID      int64
+	OriginalURL string
+	ShortName   string
+	CreatedAt   time.Time
+}
@@ -10,4 +10,18 @@ type LinkVisit struct {
 	UserAgent string
 	Referer   string
 	Status    int
+	// Path is the requested path with its query string.
+	Path string
+	// Rule is the index of the redirect rule the visitor matched; nil means
+	// the link destination was used.
+	Rule *int
+	// Variant is the index of the variant drawn for the visitor; nil when
+	// the link has none or a rule matched.
+	Variant *int
+}
+
+// VisitStat counts the visits sharing one value of a grouping.
+type VisitStat struct {
+	Key    string
+	Visits int64
 }
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// URLNormalization selects the optional steps of NormalizeURL. Lowercasing
+// the scheme and host, dropping default ports and giving an empty path "/"
+// always apply.
+type URLNormalization struct {
+	// SortQuery orders query parameters by name, keeping the order of
+	// repeated names.
+	SortQuery bool
+	// StripTracking removes utm_* and well-known click-ID parameters.
+	StripTracking bool
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+var trackingParams = map[string]struct{}{
+	"fbclid":  {},
+	"gclid":   {},
+	"dclid":   {},
+	"gbraid":  {},
+	"wbraid":  {},
+	"msclkid": {},
+	"yclid":   {},
+	"igshid":  {},
+	"mc_cid":  {},
+	"mc_eid":  {},
+	"_ga":     {},
+	"_gl":     {},
+}
+
+const trackingParamPrefix = "utm_"
+
+// NormalizeURL returns the canonical form of an original URL, so links to
+// the same destination compare equal. Path, query values and fragment are
+// kept byte for byte.
+func NormalizeURL(raw string, opts URLNormalization) (string, error) {
+	if err := ValidateOriginalURL(raw); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = normalizeHost(u.Scheme, u.Host)
+
+	if u.Path == "" && u.RawPath == "" {
+		u.Path = "/"
+	}
+
+	u.RawQuery = normalizeQuery(u.RawQuery, opts)
+	u.ForceQuery = false
+
+	return u.String(), nil
+}
+
+func normalizeHost(scheme, host string) string {
+	host = strings.ToLower(host)
+
+	name, port, err := net.SplitHostPort(host)
+	if err != nil || port != defaultPorts[scheme] {
+		return host
+	}
+
+	if strings.Contains(name, ":") {
+		return "[" + name + "]"
+	}
+
+	return name
+}
+
+func normalizeQuery(raw string, opts URLNormalization) string {
+	if raw == "" || (!opts.SortQuery && !opts.StripTracking) {
+		return raw
+	}
+
+	pairs := strings.Split(raw, "&")
+
+	if opts.StripTracking {
+		pairs = slices.DeleteFunc(pairs, func(pair string) bool {
+			return pair == "" || isTrackingParam(queryKey(pair))
+		})
+	}
+
+	if opts.SortQuery {
+		slices.SortStableFunc(pairs, func(a, b string) int {
+			return strings.Compare(queryKey(a), queryKey(b))
+		})
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func queryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		return unescaped
+	}
+
+	return key
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, trackingParamPrefix) {
+		return true
+	}
+
+	_, ok := trackingParams[key]
+
+	return ok
+}
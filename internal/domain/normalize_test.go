@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	all := domain.URLNormalization{SortQuery: true, StripTracking: true}
+
+	tests := []struct {
+		name string
+		in   string
+		opts domain.URLNormalization
+		want string
+	}{
+		{"scheme_and_host_case", "HTTPS://Example.COM/Path", domain.URLNormalization{}, "https://example.com/Path"},
+		{"default_http_port", "http://example.com:80/a", domain.URLNormalization{}, "http://example.com/a"},
+		{"default_https_port", "https://example.com:443/a", domain.URLNormalization{}, "https://example.com/a"},
+		{"other_port_kept", "https://example.com:8443/a", domain.URLNormalization{}, "https://example.com:8443/a"},
+		{"ipv6_default_port", "http://[::1]:80/", domain.URLNormalization{}, "http://[::1]/"},
+		{"empty_path", "https://example.com", domain.URLNormalization{}, "https://example.com/"},
+		{"query_untouched_by_default", "https://example.com/?b=1&utm_source=x&a=2", domain.URLNormalization{}, "https://example.com/?b=1&utm_source=x&a=2"},
+		{"sort_query", "https://example.com/?b=1&a=2&b=0", domain.URLNormalization{SortQuery: true}, "https://example.com/?a=2&b=1&b=0"},
+		{"strip_tracking", "https://example.com/?utm_source=x&id=7&fbclid=abc&UTM_Medium=y", domain.URLNormalization{StripTracking: true}, "https://example.com/?id=7"},
+		{"strip_all_params", "https://example.com/a?gclid=1", all, "https://example.com/a"},
+		{"fragment_kept", "https://example.com/a?b=1&a=2#Top", all, "https://example.com/a?a=2&b=1#Top"},
+		{"encoding_kept", "https://example.com/a%2Fb?q=a%20b", all, "https://example.com/a%2Fb?q=a%20b"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := domain.NormalizeURL(tc.in, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := domain.NormalizeURL("ftp://example.com", all)
+	require.ErrorIs(t, err, domain.ErrInvalidURL)
+}
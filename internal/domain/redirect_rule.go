@@ -0,0 +1,141 @@
+package domain
+
+import "time"
+
+// MaxRedirectRules bounds the rules of one link.
+const MaxRedirectRules = 20
+
+// RedirectRule sends the visitors matching all of its conditions to URL
+// instead of the destination of the link. Empty conditions match anyone, but
+// a rule needs at least one.
+type RedirectRule struct {
+	// OS is one of the OS* constants.
+	OS string
+	// Device is one of the Device* constants.
+	Device string
+	// Country is an ISO 3166-1 alpha-2 code in upper case.
+	Country string
+	// After and Before bound the time window of the rule: it matches from
+	// After on and until just before Before. Both are in UTC; zero leaves
+	// that side open.
+	After  time.Time
+	Before time.Time
+	URL    string
+}
+
+// Visitor is what redirect rules are matched against.
+type Visitor struct {
+	OS     string
+	Device string
+	// Country is empty when it could not be resolved; no country rule
+	// matches then.
+	Country string
+	// Time is when the visit happens.
+	Time time.Time
+}
+
+// Matches reports whether v meets every condition of r.
+func (r RedirectRule) Matches(v Visitor) bool {
+	if r.OS != "" && r.OS != v.OS {
+		return false
+	}
+
+	if r.Device != "" && r.Device != v.Device {
+		return false
+	}
+
+	if r.Country != "" && r.Country != v.Country {
+		return false
+	}
+
+	if !r.After.IsZero() && v.Time.Before(r.After) {
+		return false
+	}
+
+	if !r.Before.IsZero() && !v.Time.Before(r.Before) {
+		return false
+	}
+
+	return true
+}
+
+// ValidateRedirectRules accepts up to MaxRedirectRules rules, each with known
+// conditions, at least one of them set, a non-empty time window, and a valid
+// URL.
+func ValidateRedirectRules(rules []RedirectRule) error {
+	if len(rules) > MaxRedirectRules {
+		return ErrInvalidRedirectRules
+	}
+
+	for _, rule := range rules {
+		if rule.OS == "" && rule.Device == "" && rule.Country == "" && rule.After.IsZero() && rule.Before.IsZero() {
+			return ErrInvalidRedirectRules
+		}
+
+		if !rule.After.IsZero() && !rule.Before.IsZero() && !rule.After.Before(rule.Before) {
+			return ErrInvalidRedirectRules
+		}
+
+		switch rule.OS {
+		case "", OSiOS, OSAndroid, OSWindows, OSMacOS, OSLinux, OSOther:
+		default:
+			return ErrInvalidRedirectRules
+		}
+
+		switch rule.Device {
+		case "", DeviceMobile, DeviceTablet, DeviceDesktop:
+		default:
+			return ErrInvalidRedirectRules
+		}
+
+		if rule.Country != "" && !IsCountryCode(rule.Country) {
+			return ErrInvalidRedirectRules
+		}
+
+		if err := ValidateOriginalURL(rule.URL); err != nil {
+			return ErrInvalidRedirectRules
+		}
+	}
+
+	return nil
+}
+
+// Route picks the URL v is sent to: that of the first rule it matches, with
+// the index of the rule, or OriginalURL and -1 when none does.
+func (l Link) Route(v Visitor) (string, int) {
+	for i, rule := range l.Rules {
+		if rule.Matches(v) {
+			return rule.URL, i
+		}
+	}
+
+	return l.OriginalURL, -1
+}
+
+// HasCountryRules reports whether routing the link needs the country of the
+// visitor.
+func (l Link) HasCountryRules() bool {
+	for _, rule := range l.Rules {
+		if rule.Country != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCountryCode reports whether code looks like an ISO 3166-1 alpha-2 code:
+// two upper case ASCII letters. Whether the code is assigned is not checked.
+func IsCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+
+	for i := range len(code) {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,141 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name   string
+		ua     string
+		os     string
+		device string
+	}{
+		{"iphone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148",
+			domain.OSiOS, domain.DeviceMobile},
+		{"ipad", "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148",
+			domain.OSiOS, domain.DeviceTablet},
+		{"android_phone", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36",
+			domain.OSAndroid, domain.DeviceMobile},
+		{"android_tablet", "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 Chrome/120.0 Safari/537.36",
+			domain.OSAndroid, domain.DeviceTablet},
+		{"windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36",
+			domain.OSWindows, domain.DeviceDesktop},
+		{"macos", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15",
+			domain.OSMacOS, domain.DeviceDesktop},
+		{"linux", "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			domain.OSLinux, domain.DeviceDesktop},
+		{"unknown", "curl/8.5.0", domain.OSOther, domain.DeviceDesktop},
+		{"empty", "", domain.OSOther, domain.DeviceDesktop},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			os, device := domain.ParseUserAgent(tc.ua)
+			require.Equal(t, tc.os, os)
+			require.Equal(t, tc.device, device)
+		})
+	}
+}
+
+func TestLinkRoute(t *testing.T) {
+	link := domain.Link{
+		OriginalURL: "https://example.com",
+		Rules: []domain.RedirectRule{
+			{OS: domain.OSiOS, Device: domain.DeviceTablet, URL: "https://example.com/ipad"},
+			{OS: domain.OSiOS, URL: "https://example.com/ios"},
+			{Device: domain.DeviceMobile, URL: "https://example.com/mobile"},
+			{Country: "DE", Device: domain.DeviceDesktop, URL: "https://example.de"},
+			{Country: "FR", URL: "https://example.fr"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		visitor domain.Visitor
+		want    string
+		rule    int
+	}{
+		{"first_match_wins", domain.Visitor{OS: domain.OSiOS, Device: domain.DeviceTablet}, "https://example.com/ipad", 0},
+		{"partial_conditions", domain.Visitor{OS: domain.OSiOS, Device: domain.DeviceMobile}, "https://example.com/ios", 1},
+		{"device_only", domain.Visitor{OS: domain.OSAndroid, Device: domain.DeviceMobile}, "https://example.com/mobile", 2},
+		{"country_and_device", domain.Visitor{OS: domain.OSWindows, Device: domain.DeviceDesktop, Country: "DE"},
+			"https://example.de", 3},
+		{"country_only", domain.Visitor{OS: domain.OSLinux, Device: domain.DeviceDesktop, Country: "FR"},
+			"https://example.fr", 4},
+		{"unknown_country", domain.Visitor{OS: domain.OSLinux, Device: domain.DeviceDesktop},
+			"https://example.com", -1},
+		{"fallback", domain.Visitor{OS: domain.OSWindows, Device: domain.DeviceDesktop}, "https://example.com", -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, rule := link.Route(tc.visitor)
+			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.rule, rule)
+		})
+	}
+}
+
+func TestRedirectRuleTimeWindow(t *testing.T) {
+	start := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	rule := domain.RedirectRule{After: start, Before: end, URL: "https://example.com/sale"}
+
+	require.False(t, rule.Matches(domain.Visitor{Time: start.Add(-time.Nanosecond)}))
+	require.True(t, rule.Matches(domain.Visitor{Time: start}))
+	require.True(t, rule.Matches(domain.Visitor{Time: end.Add(-time.Nanosecond)}))
+	require.False(t, rule.Matches(domain.Visitor{Time: end}))
+
+	mobile := domain.RedirectRule{Device: domain.DeviceMobile, After: start, URL: "https://m.example.com/sale"}
+	require.True(t, mobile.Matches(domain.Visitor{Device: domain.DeviceMobile, Time: end}))
+	require.False(t, mobile.Matches(domain.Visitor{Device: domain.DeviceDesktop, Time: end}))
+}
+
+func TestLinkActiveAt(t *testing.T) {
+	launch := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	require.True(t, domain.Link{}.ActiveAt(launch))
+
+	link := domain.Link{ActiveFrom: &launch}
+	require.False(t, link.ActiveAt(launch.Add(-time.Second)))
+	require.True(t, link.ActiveAt(launch))
+}
+
+func TestValidateRedirectRules(t *testing.T) {
+	require.NoError(t, domain.ValidateRedirectRules(nil))
+	require.NoError(t, domain.ValidateRedirectRules([]domain.RedirectRule{
+		{OS: domain.OSAndroid, URL: "https://play.google.com/store"},
+		{Device: domain.DeviceDesktop, URL: "https://example.com/desktop"},
+		{Country: "BR", URL: "https://example.com.br"},
+		{Before: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), URL: "https://example.com/teaser"},
+	}))
+
+	invalid := []domain.RedirectRule{
+		{URL: "https://example.com"},
+		{OS: "beos", URL: "https://example.com"},
+		{Device: "watch", URL: "https://example.com"},
+		{Country: "de", URL: "https://example.de"},
+		{Country: "DEU", URL: "https://example.de"},
+		{
+			After:  time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+			Before: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			URL:    "https://example.com",
+		},
+		{OS: domain.OSiOS, URL: "itms-apps://apps.apple.com/app/id1"},
+	}
+	for _, rule := range invalid {
+		require.ErrorIs(t, domain.ValidateRedirectRules([]domain.RedirectRule{rule}), domain.ErrInvalidRedirectRules)
+	}
+
+	tooMany := make([]domain.RedirectRule, domain.MaxRedirectRules+1)
+	for i := range tooMany {
+		tooMany[i] = domain.RedirectRule{OS: domain.OSiOS, URL: "https://example.com"}
+	}
+	require.ErrorIs(t, domain.ValidateRedirectRules(tooMany), domain.ErrInvalidRedirectRules)
+}
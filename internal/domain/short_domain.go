@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// hostLabelRe matches one DNS label: letters, digits and inner hyphens.
+var hostLabelRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+const maxHostLength = 253
+
+// Domain is a host serving short links besides the BASE_URL one. Links keep
+// its Host in Link.Domain, and short names are unique per domain.
+type Domain struct {
+	ID        int64
+	Host      string
+	CreatedAt time.Time
+}
+
+// NormalizeHost lowercases host and strips a port and a trailing dot, so a
+// Host header compares equal to a registered domain.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(host, ".")
+}
+
+// ValidateHost accepts a normalized DNS name of at least two labels; IP
+// literals are rejected.
+func ValidateHost(host string) error {
+	if host == "" || len(host) > maxHostLength {
+		return ErrInvalidDomain
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return ErrInvalidDomain
+	}
+
+	for _, label := range labels {
+		if !hostLabelRe.MatchString(label) {
+			return ErrInvalidDomain
+		}
+	}
+
+	// A numeric top-level label means an IPv4 address.
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return ErrInvalidDomain
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// reservedShortNames cannot be claimed because they are, or could become,
+// top-level paths of the service and its proxy, or because they look like
+// official pages. Matching ignores case.
+var reservedShortNames = map[string]struct{}{
+	// Served by the API and the proxy in front of it; "r" is shorter than
+	// any valid name but keeps the list complete.
+	"api":    {},
+	"ping":   {},
+	"r":      {},
+	"assets": {},
+	// Likely future pages and well-known names.
+	"about":     {},
+	"account":   {},
+	"admin":     {},
+	"auth":      {},
+	"billing":   {},
+	"dashboard": {},
+	"docs":      {},
+	"health":    {},
+	"healthz":   {},
+	"help":      {},
+	"login":     {},
+	"logout":    {},
+	"metrics":   {},
+	"oauth":     {},
+	"openapi":   {},
+	"preview":   {},
+	"privacy":   {},
+	"register":  {},
+	"root":      {},
+	"security":  {},
+	"settings":  {},
+	"signin":    {},
+	"signout":   {},
+	"signup":    {},
+	"static":    {},
+	"status":    {},
+	"support":   {},
+	"swagger":   {},
+	"terms":     {},
+	"www":       {},
+}
+
+// leetFold maps digits commonly used as letters, so "b00b" matches "boob".
+var leetFold = strings.NewReplacer(
+	"-", "",
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"8", "b",
+)
+
+// IsReservedShortName reports whether name is on the built-in reserved list.
+func IsReservedShortName(name string) bool {
+	_, ok := reservedShortNames[strings.ToLower(name)]
+
+	return ok
+}
+
+// ShortNamePolicy restricts custom and generated short names beyond
+// ValidateShortName. The zero value only rejects reserved names.
+type ShortNamePolicy struct {
+	// Denied words are rejected anywhere in a short name, ignoring case,
+	// hyphens and digits used as letters. Use ParseShortNameDenylist to
+	// build it.
+	Denied []string
+}
+
+// Check returns ErrShortNameReserved or ErrShortNameDenied for a rejected
+// name; name must already be valid.
+func (p ShortNamePolicy) Check(name string) error {
+	if IsReservedShortName(name) {
+		return ErrShortNameReserved
+	}
+
+	if len(p.Denied) == 0 {
+		return nil
+	}
+
+	folded := foldShortName(name)
+	for _, word := range p.Denied {
+		if strings.Contains(folded, word) {
+			return ErrShortNameDenied
+		}
+	}
+
+	return nil
+}
+
+// ParseShortNameDenylist reads one word per line; blank lines and text after
+// "#" are ignored. Words may only use the short name alphabet.
+func ParseShortNameDenylist(r io.Reader) ([]string, error) {
+	var words []string
+
+	sc := bufio.NewScanner(r)
+
+	for n := 1; sc.Scan(); n++ {
+		word, _, _ := strings.Cut(sc.Text(), "#")
+
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+
+		folded := foldShortName(word)
+		if folded == "" || strings.Trim(folded, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+			return nil, fmt.Errorf("denylist line %d: %w: %q", n, ErrInvalidDenylistEntry, word)
+		}
+
+		words = append(words, folded)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read denylist: %w", err)
+	}
+
+	return words, nil
+}
+
+func foldShortName(s string) string {
+	return leetFold.Replace(strings.ToLower(s))
+}
@@ -0,0 +1,59 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestShortNamePolicyCheck(t *testing.T) {
+	denied, err := domain.ParseShortNameDenylist(strings.NewReader("# words\nbadword\nN4STY  # folded too\n\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"badword", "nasty"}, denied)
+
+	policy := domain.ShortNamePolicy{Denied: denied}
+
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"ok", "promo-2026", nil},
+		{"reserved/route", "api", domain.ErrShortNameReserved},
+		{"reserved/case", "Admin", domain.ErrShortNameReserved},
+		{"reserved/prefix_ok", "admins", nil},
+		{"denied/exact", "badword", domain.ErrShortNameDenied},
+		{"denied/inside", "xxBadWordxx", domain.ErrShortNameDenied},
+		{"denied/leet", "b4dw0rd", domain.ErrShortNameDenied},
+		{"denied/hyphens", "bad-word", domain.ErrShortNameDenied},
+		{"denied/folded_entry", "nasty1", domain.ErrShortNameDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.in)
+			if tt.want == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.want)
+			require.ErrorIs(t, err, domain.ErrInvalidShortName)
+		})
+	}
+
+	require.ErrorIs(t, domain.ShortNamePolicy{}.Check("login"), domain.ErrShortNameReserved)
+	require.NoError(t, domain.ShortNamePolicy{}.Check("badword"))
+}
+
+func TestParseShortNameDenylist_Invalid(t *testing.T) {
+	for _, src := range []string{"two words", "---", "naïve"} {
+		_, err := domain.ParseShortNameDenylist(strings.NewReader("ok\n" + src))
+		require.ErrorIs(t, err, domain.ErrInvalidDenylistEntry, src)
+		require.ErrorContains(t, err, "line 2")
+	}
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// netip does not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// URLPolicy restricts link destinations beyond ValidateOriginalURL. The zero
+// value allows every valid URL.
+//
+// Domain patterns match a host exactly, or any of its subdomains when written
+// as "*.example.com".
+type URLPolicy struct {
+	// BlockPrivate rejects IP literals outside the public internet, in any
+	// form inet_aton accepts, and single-label or .localhost host names.
+	// Names are not resolved.
+	BlockPrivate bool
+	// SelfHosts match the hosts serving short links; pointing a link at them
+	// would create redirect loops and chains.
+	SelfHosts []string
+	// AllowDomains, when not empty, lists the only allowed destinations.
+	AllowDomains []string
+	DenyDomains  []string
+}
+
+// Enabled reports whether Check can reject anything.
+func (p URLPolicy) Enabled() bool {
+	return p.BlockPrivate || len(p.SelfHosts) > 0 || len(p.AllowDomains) > 0 || len(p.DenyDomains) > 0
+}
+
+// Check returns a specific ErrInvalidURL variant for a destination the policy
+// rejects; rawURL must already be valid.
+func (p URLPolicy) Check(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ErrInvalidURL
+	}
+
+	host := canonicalHost(u.Hostname())
+	if host == "" {
+		return ErrInvalidURL
+	}
+
+	if p.BlockPrivate && isPrivateHost(host) {
+		return ErrURLPrivateHost
+	}
+
+	if matchesAnyDomain(p.SelfHosts, host) {
+		return ErrURLSelfReference
+	}
+
+	if matchesAnyDomain(p.DenyDomains, host) {
+		return ErrURLDomainDenied
+	}
+
+	if len(p.AllowDomains) > 0 && !matchesAnyDomain(p.AllowDomains, host) {
+		return ErrURLDomainNotAllowed
+	}
+
+	return nil
+}
+
+func canonicalHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func isPrivateHost(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil && isNumericLabel(host[strings.LastIndex(host, ".")+1:]) {
+		// Browsers and resolvers read such hosts as IPv4 addresses; one that
+		// does not parse has no public meaning either.
+		v4, ok := parseNumericIPv4(host)
+		if !ok {
+			return true
+		}
+
+		addr, err = v4, nil
+	}
+
+	if err == nil {
+		addr = addr.Unmap()
+
+		return !addr.IsGlobalUnicast() ||
+			addr.IsPrivate() ||
+			addr.IsLoopback() ||
+			addr.IsLinkLocalUnicast() ||
+			sharedAddressSpace.Contains(addr)
+	}
+
+	return !strings.Contains(host, ".") || host == "localhost" || strings.HasSuffix(host, ".localhost")
+}
+
+// isNumericLabel reports whether label is a decimal, octal or 0x-prefixed
+// hex number, which makes a host an IPv4 address rather than a name.
+func isNumericLabel(label string) bool {
+	if hex, ok := strings.CutPrefix(label, "0x"); ok {
+		return strings.Trim(hex, "0123456789abcdef") == ""
+	}
+
+	return label != "" && strings.Trim(label, "0123456789") == ""
+}
+
+// parseNumericIPv4 parses the inet_aton forms netip rejects, like
+// "127.1", "0x7f.0.0.1" or "2130706433": one to four parts, each decimal,
+// octal with a leading 0 or hex with 0x, the last filling the low bytes.
+func parseNumericIPv4(host string) (netip.Addr, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+
+	var ip uint64
+
+	for i, part := range parts {
+		base := 10
+
+		switch {
+		case strings.HasPrefix(part, "0x"):
+			base, part = 16, part[2:]
+			if part == "" {
+				part = "0"
+			}
+		case len(part) > 1 && part[0] == '0':
+			base, part = 8, part[1:]
+		}
+
+		n, err := strconv.ParseUint(part, base, 32)
+		if err != nil {
+			return netip.Addr{}, false
+		}
+
+		if i < len(parts)-1 {
+			if n > 0xff {
+				return netip.Addr{}, false
+			}
+
+			ip |= n << (8 * (3 - i))
+
+			continue
+		}
+
+		if n >= 1<<(8*(4-i)) {
+			return netip.Addr{}, false
+		}
+
+		ip |= n
+	}
+
+	return netip.AddrFrom4([4]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)}), true
+}
+
+func matchesAnyDomain(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = canonicalHost(pattern)
+
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestURLPolicyCheck(t *testing.T) {
+	policy := domain.URLPolicy{
+		BlockPrivate: true,
+		SelfHosts:    []string{"sho.rt", "Links.Example.com"},
+		DenyDomains:  []string{"*.evil.test", "bad.test"},
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"ok/public_host", "https://example.com/a", nil},
+		{"ok/public_ip", "http://93.184.216.34/", nil},
+		{"ok/denied_parent_not_matched", "https://evil.test/", nil},
+		{"private/loopback_v4", "http://127.0.0.1:8080/", domain.ErrURLPrivateHost},
+		{"private/rfc1918", "http://10.1.2.3/", domain.ErrURLPrivateHost},
+		{"private/link_local_metadata", "http://169.254.169.254/latest", domain.ErrURLPrivateHost},
+		{"private/cgnat", "http://100.64.0.1/", domain.ErrURLPrivateHost},
+		{"private/unspecified", "http://0.0.0.0/", domain.ErrURLPrivateHost},
+		{"private/loopback_v6", "http://[::1]/", domain.ErrURLPrivateHost},
+		{"private/mapped_v4", "http://[::ffff:192.168.0.1]/", domain.ErrURLPrivateHost},
+		{"private/ula_v6", "http://[fd00::1]/", domain.ErrURLPrivateHost},
+		{"private/single_label", "http://intranet/", domain.ErrURLPrivateHost},
+		{"private/localhost", "http://localhost:3000/", domain.ErrURLPrivateHost},
+		{"private/dot_localhost", "http://app.localhost/", domain.ErrURLPrivateHost},
+		{"private/short_v4", "http://127.1/", domain.ErrURLPrivateHost},
+		{"private/hex_part", "http://0x7f.0.0.1/", domain.ErrURLPrivateHost},
+		{"private/octal_parts", "http://0177.0.0.01/", domain.ErrURLPrivateHost},
+		{"private/decimal", "http://2130706433/", domain.ErrURLPrivateHost},
+		{"private/hex", "http://0XA9FEA9FE/latest", domain.ErrURLPrivateHost},
+		{"private/three_parts", "http://10.1.515/", domain.ErrURLPrivateHost},
+		{"private/bad_numeric", "http://1.2.3.256/", domain.ErrURLPrivateHost},
+		{"ok/public_decimal", "http://1572395042/", nil},
+		{"ok/numeric_label_not_last", "https://123.example.com/", nil},
+		{"self/exact", "https://sho.rt/r/abc", domain.ErrURLSelfReference},
+		{"self/case_and_dot", "https://links.example.com./x", domain.ErrURLSelfReference},
+		{"deny/wildcard", "https://a.b.evil.test/", domain.ErrURLDomainDenied},
+		{"deny/exact", "https://BAD.test/", domain.ErrURLDomainDenied},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.in)
+			if tc.want == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tc.want)
+			require.ErrorIs(t, err, domain.ErrInvalidURL)
+		})
+	}
+}
+
+func TestURLPolicyCheck_AllowList(t *testing.T) {
+	policy := domain.URLPolicy{AllowDomains: []string{"example.com", "*.example.org"}}
+
+	require.NoError(t, policy.Check("https://example.com/"))
+	require.NoError(t, policy.Check("https://docs.example.org/"))
+	require.ErrorIs(t, policy.Check("https://www.example.com/"), domain.ErrURLDomainNotAllowed)
+	require.ErrorIs(t, policy.Check("https://example.org/"), domain.ErrURLDomainNotAllowed)
+
+	require.False(t, domain.URLPolicy{}.Enabled())
+	require.True(t, policy.Enabled())
+}
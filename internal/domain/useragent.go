@@ -0,0 +1,54 @@
+package domain
+
+import "strings"
+
+// Operating systems told apart by ParseUserAgent.
+const (
+	OSiOS     = "ios"
+	OSAndroid = "android"
+	OSWindows = "windows"
+	OSMacOS   = "macos"
+	OSLinux   = "linux"
+	OSOther   = "other"
+)
+
+// Device types told apart by ParseUserAgent.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+// ParseUserAgent derives the operating system and device type of a client
+// from its User-Agent header. It only looks for the platform tokens browsers
+// send, which is enough to route visitors but not to identify browsers; an
+// unknown agent is an OSOther desktop.
+func ParseUserAgent(ua string) (os, device string) {
+	switch {
+	case strings.Contains(ua, "iPad"):
+		return OSiOS, DeviceTablet
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		return OSiOS, DeviceMobile
+	case strings.Contains(ua, "Android"):
+		// Android tablets leave out the Mobile token phones send.
+		if strings.Contains(ua, "Mobile") {
+			return OSAndroid, DeviceMobile
+		}
+
+		return OSAndroid, DeviceTablet
+	case strings.Contains(ua, "Windows Phone"):
+		return OSWindows, DeviceMobile
+	case strings.Contains(ua, "Windows"):
+		return OSWindows, DeviceDesktop
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		return OSMacOS, DeviceDesktop
+	case strings.Contains(ua, "Linux"), strings.Contains(ua, "X11"):
+		return OSLinux, DeviceDesktop
+	}
+
+	if strings.Contains(ua, "Mobile") {
+		return OSOther, DeviceMobile
+	}
+
+	return OSOther, DeviceDesktop
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxUTMLength is counted in characters, per parameter.
+const MaxUTMLength = 100
+
+// UTM holds the campaign parameters added to the destination of a link on
+// redirect; empty fields are left out.
+type UTM struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Term     string
+	Content  string
+}
+
+// IsZero reports whether no parameter is set.
+func (u UTM) IsZero() bool {
+	return u == UTM{}
+}
+
+// ValidateUTM requires Source once any parameter is set, and accepts up to
+// MaxUTMLength characters without control characters per parameter.
+func ValidateUTM(u UTM) error {
+	if u.IsZero() {
+		return nil
+	}
+
+	if u.Source == "" {
+		return ErrInvalidUTM
+	}
+
+	for _, v := range u.params() {
+		if utf8.RuneCountInString(v[1]) > MaxUTMLength || strings.ContainsFunc(v[1], unicode.IsControl) {
+			return ErrInvalidUTM
+		}
+	}
+
+	return nil
+}
+
+// Apply sets the parameters of u on dest, replacing the utm_* values dest
+// already carries for them.
+func (u UTM) Apply(dest string) (string, error) {
+	if u.IsZero() {
+		return dest, nil
+	}
+
+	parsed, err := url.Parse(dest)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	query := parsed.Query()
+	for _, v := range u.params() {
+		if v[1] != "" {
+			query.Set(v[0], v[1])
+		}
+	}
+
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+func (u UTM) params() [5][2]string {
+	return [5][2]string{
+		{"utm_source", u.Source},
+		{"utm_medium", u.Medium},
+		{"utm_campaign", u.Campaign},
+		{"utm_term", u.Term},
+		{"utm_content", u.Content},
+	}
+}
+
+// Destinations returns DestinationURLs with the UTM parameters of the link,
+// without duplicates.
+func (l Link) Destinations() ([]string, error) {
+	urls := l.DestinationURLs()
+	dests := make([]string, 0, len(urls))
+
+	for _, rawURL := range urls {
+		dest, err := l.UTM.Apply(rawURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(dests, dest) {
+			dests = append(dests, dest)
+		}
+	}
+
+	return dests, nil
+}
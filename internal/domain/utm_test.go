@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestUTMApply(t *testing.T) {
+	tests := []struct {
+		name string
+		utm  domain.UTM
+		dest string
+		want string
+	}{
+		{"zero", domain.UTM{}, "https://example.com/a?b=1", "https://example.com/a?b=1"},
+		{"all", domain.UTM{Source: "news", Medium: "email", Campaign: "spring sale", Term: "shoes", Content: "cta"},
+			"https://example.com/a",
+			"https://example.com/a?utm_campaign=spring+sale&utm_content=cta&utm_medium=email&utm_source=news&utm_term=shoes"},
+		{"replaces_existing", domain.UTM{Source: "news"}, "https://example.com/a?utm_source=old&utm_medium=cpc#top",
+			"https://example.com/a?utm_medium=cpc&utm_source=news#top"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.utm.Apply(tc.dest)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateUTM(t *testing.T) {
+	require.NoError(t, domain.ValidateUTM(domain.UTM{}))
+	require.NoError(t, domain.ValidateUTM(domain.UTM{Source: "news", Campaign: "spring"}))
+	require.NoError(t, domain.ValidateUTM(domain.UTM{Source: strings.Repeat("é", domain.MaxUTMLength)}))
+
+	require.ErrorIs(t, domain.ValidateUTM(domain.UTM{Campaign: "spring"}), domain.ErrInvalidUTM)
+	require.ErrorIs(t, domain.ValidateUTM(domain.UTM{Source: strings.Repeat("a", domain.MaxUTMLength+1)}), domain.ErrInvalidUTM)
+	require.ErrorIs(t, domain.ValidateUTM(domain.UTM{Source: "news", Term: "a\nb"}), domain.ErrInvalidUTM)
+}
@@ -4,9 +4,20 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-var shortNameRe = regexp.MustCompile(`^[a-zA-Z0-9-]{3,32}$`)
+var (
+	shortNameRe = regexp.MustCompile(`^[a-zA-Z0-9-]{3,32}$`)
+	tagRe       = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+)
+
+const (
+	MaxTags = 20
+	// MaxTitleLength is counted in characters.
+	MaxTitleLength = 200
+)
 
 func ValidateOriginalURL(s string) error {
 	s = strings.TrimSpace(s)
@@ -38,3 +49,55 @@ func ValidateShortName(s string) error {
 
 	return nil
 }
+
+// NormalizeTags trims and lowercases tags and drops blanks and duplicates,
+// keeping the first occurrence order. The result is never nil.
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+
+	return out
+}
+
+// ValidateTags expects tags already passed through NormalizeTags.
+func ValidateTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return ErrInvalidTags
+	}
+
+	for _, tag := range tags {
+		if !tagRe.MatchString(tag) {
+			return ErrInvalidTags
+		}
+	}
+
+	return nil
+}
+
+// ValidateTitle accepts up to MaxTitleLength characters without control
+// characters; the empty title is valid.
+func ValidateTitle(s string) error {
+	if utf8.RuneCountInString(s) > MaxTitleLength {
+		return ErrInvalidTitle
+	}
+
+	if strings.ContainsFunc(s, unicode.IsControl) {
+		return ErrInvalidTitle
+	}
+
+	return nil
+}
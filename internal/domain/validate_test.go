@@ -70,3 +70,22 @@ func TestValidateOriginalURL(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeAndValidateTags(t *testing.T) {
+	require.Equal(t, []string{"promo", "q4"}, domain.NormalizeTags([]string{" Promo ", "", "q4", "PROMO"}))
+	require.NotNil(t, domain.NormalizeTags(nil))
+
+	require.NoError(t, domain.ValidateTags([]string{"promo", "q4_2026", "a-b"}))
+	require.Error(t, domain.ValidateTags([]string{"has space"}))
+	require.Error(t, domain.ValidateTags([]string{"-leading"}))
+	require.Error(t, domain.ValidateTags([]string{strings.Repeat("a", 33)}))
+	require.Error(t, domain.ValidateTags(make([]string, domain.MaxTags+1)))
+}
+
+func TestValidateTitle(t *testing.T) {
+	require.NoError(t, domain.ValidateTitle(""))
+	require.NoError(t, domain.ValidateTitle("Ссылка на <docs> & блог"))
+	require.NoError(t, domain.ValidateTitle(strings.Repeat("я", domain.MaxTitleLength)))
+	require.Error(t, domain.ValidateTitle(strings.Repeat("a", domain.MaxTitleLength+1)))
+	require.Error(t, domain.ValidateTitle("line\nbreak"))
+}
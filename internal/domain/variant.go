@@ -0,0 +1,70 @@
+package domain
+
+const (
+	// MaxVariants bounds the destinations of one A/B split.
+	MaxVariants = 10
+	// MaxVariantWeight bounds each weight; a zero weight pauses a variant.
+	MaxVariantWeight = 1000
+)
+
+// Variant is one destination of an A/B split, drawn in proportion to its
+// Weight among the variants of the link.
+type Variant struct {
+	URL    string
+	Weight int
+}
+
+// ValidateVariants accepts no variants, or 2 to MaxVariants of them with
+// valid URLs and weights from 0 to MaxVariantWeight, not all zero.
+func ValidateVariants(variants []Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	if len(variants) < 2 || len(variants) > MaxVariants {
+		return ErrInvalidVariants
+	}
+
+	total := 0
+	for _, v := range variants {
+		if v.Weight < 0 || v.Weight > MaxVariantWeight {
+			return ErrInvalidVariants
+		}
+
+		if err := ValidateOriginalURL(v.URL); err != nil {
+			return ErrInvalidVariants
+		}
+
+		total += v.Weight
+	}
+
+	if total == 0 {
+		return ErrInvalidVariants
+	}
+
+	return nil
+}
+
+// VariantsWeight is the sum of the variant weights of the link.
+func (l Link) VariantsWeight() int {
+	total := 0
+	for _, v := range l.Variants {
+		total += v.Weight
+	}
+
+	return total
+}
+
+// VariantAt returns the index of the variant n falls on when the weights
+// are laid end to end; n is drawn from [0, VariantsWeight()).
+func (l Link) VariantAt(n int) int {
+	for i, v := range l.Variants {
+		if n < v.Weight {
+			return i
+		}
+
+		n -= v.Weight
+	}
+
+	return len(l.Variants) - 1
+}
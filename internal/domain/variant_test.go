@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code/internal/domain"
+)
+
+func TestLinkVariantAt(t *testing.T) {
+	link := domain.Link{Variants: []domain.Variant{
+		{URL: "https://example.com/a", Weight: 70},
+		{URL: "https://example.com/paused", Weight: 0},
+		{URL: "https://example.com/b", Weight: 30},
+	}}
+
+	require.Equal(t, 100, link.VariantsWeight())
+	require.Equal(t, 0, link.VariantAt(0))
+	require.Equal(t, 0, link.VariantAt(69))
+	require.Equal(t, 2, link.VariantAt(70))
+	require.Equal(t, 2, link.VariantAt(99))
+}
+
+func TestValidateVariants(t *testing.T) {
+	a := domain.Variant{URL: "https://example.com/a", Weight: 70}
+	b := domain.Variant{URL: "https://example.com/b", Weight: 30}
+
+	require.NoError(t, domain.ValidateVariants(nil))
+	require.NoError(t, domain.ValidateVariants([]domain.Variant{a, b}))
+	require.NoError(t, domain.ValidateVariants([]domain.Variant{a, {URL: b.URL}}))
+
+	invalid := [][]domain.Variant{
+		{a},
+		{a, {URL: b.URL, Weight: -1}},
+		{a, {URL: b.URL, Weight: domain.MaxVariantWeight + 1}},
+		{a, {URL: "ftp://example.com/b", Weight: 30}},
+		{{URL: a.URL}, {URL: b.URL}},
+		make([]domain.Variant, domain.MaxVariants+1),
+	}
+	for _, variants := range invalid {
+		require.ErrorIs(t, domain.ValidateVariants(variants), domain.ErrInvalidVariants)
+	}
+}
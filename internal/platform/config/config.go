@@ -30,9 +30,37 @@ const (
 	defaultHTTPShutdownTimeout   = 5 * time.Second
 
 	// Request budget
-	defaultRequestBudget = 2 * time.Second
+	defaultRequestBudget  = 2 * time.Second
+	defaultTransferBudget = 10 * time.Minute
+
+	defaultIdempotencyTTL = 24 * time.Hour
+
+	// Blocklist
+	defaultBlocklistReloadInterval = 30 * time.Second
+	defaultBlocklistRescanInterval = time.Hour
+
+	// Short name generation
+	defaultShortNameStrategy        = "random"
+	defaultShortNameLength          = 8
+	defaultShortNameHashIDMinLength = 6
+)
+
+// Redirect path modes.
+const (
+	// RedirectPathPrefixed serves short links at /r/<code> only.
+	RedirectPathPrefixed = "prefixed"
+	// RedirectPathRoot also serves them at /<code>.
+	RedirectPathRoot = "root"
 )
 
+// shortNameStrategies mirrors the strategies registered by the links service.
+var shortNameStrategies = map[string]struct{}{
+	"random":     {},
+	"sequential": {},
+	"hashid":     {},
+	"words":      {},
+}
+
 type Config struct {
 	// HTTPAddr is the Go backend listen address; PORT is reserved for platform/Caddy.
 	HTTPAddr string
@@ -54,8 +82,65 @@ type Config struct {
 	HTTPIdleTimeout       time.Duration
 	HTTPShutdownTimeout   time.Duration
 	RequestBudget         time.Duration
+	// TransferBudget replaces RequestBudget on import/export routes.
+	TransferBudget time.Duration
 
 	CORSAllowedOrigins []string
+
+	// IdempotencyTTL is how long a response is replayed for an Idempotency-Key.
+	IdempotencyTTL time.Duration
+
+	// Quotas; zero means unlimited.
+	QuotaMaxLinks         int64
+	QuotaMaxMonthlyClicks int64
+	QuotaBlockRedirects   bool
+
+	// Optional URL normalization steps used to match duplicate destinations.
+	URLSortQuery     bool
+	URLStripTracking bool
+
+	// Destination URL policy; the BASE_URL host is always in URLSelfHosts.
+	URLBlockPrivate   bool
+	URLSelfHosts      []string
+	URLAllowedDomains []string
+	URLDeniedDomains  []string
+
+	// BlocklistFile enables the destination blocklist; it is re-read every
+	// BlocklistReloadInterval when changed and existing links are rescanned
+	// every BlocklistRescanInterval and after each reload.
+	BlocklistFile           string
+	BlocklistReloadInterval time.Duration
+	BlocklistRescanInterval time.Duration
+
+	// GeoIPFile is a DB-IP style country CSV used to resolve the country of
+	// visitors for country redirect rules when Cloudflare does not send
+	// CF-IPCountry. Read at startup.
+	GeoIPFile string
+
+	// ShortNameDenylistFile lists words rejected in custom and generated
+	// short names on top of the built-in reserved names.
+	ShortNameDenylistFile string
+
+	// Generated short names: the default strategy, the random strategy
+	// length, the alphabet of the random and hashid strategies (empty for
+	// the built-in one) and the hashid salt and minimum length.
+	ShortNameStrategy        string
+	ShortNameLength          int
+	ShortNameAlphabet        string
+	ShortNameHashIDSalt      string
+	ShortNameHashIDMinLength int
+
+	// ShortNameCaseInsensitive resolves short names in any casing; it needs
+	// the unique index created by cmd/casefold.
+	ShortNameCaseInsensitive bool
+
+	// DomainFallback is the registered domain whose links are served on
+	// hosts that are neither BASE_URL nor registered; empty serves the
+	// links without a domain.
+	DomainFallback string
+
+	// RedirectPathMode is RedirectPathPrefixed or RedirectPathRoot.
+	RedirectPathMode string
 }
 
 type durationSpec struct {
@@ -106,6 +191,40 @@ func Load() (Config, error) {
 
 	loadCORS(&cfg)
 
+	if err := loadIdempotency(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadQuota(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadURLNormalization(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadURLPolicy(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadBlocklist(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	cfg.GeoIPFile = env("GEOIP_FILE")
+
+	if err := loadShortNames(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadDomains(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := loadRedirectPath(&cfg); err != nil {
+		return Config{}, err
+	}
+
 	return cfg, nil
 }
 
@@ -217,6 +336,20 @@ func parseIntEnv(key string, def int) (int, error) {
 	return n, nil
 }
 
+func parseBoolEnv(key string, def bool) (bool, error) {
+	raw := env(key)
+	if raw == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s=%q", ErrInvalidBool, key, raw)
+	}
+
+	return b, nil
+}
+
 // grouped loaders
 
 func loadSentry(cfg *Config) error {
@@ -328,6 +461,32 @@ func loadRequestBudget(cfg *Config) error {
 
 	cfg.RequestBudget = budget
 
+	transfer, err := parseDurationEnv("TRANSFER_BUDGET", defaultTransferBudget)
+	if err != nil {
+		return err
+	}
+
+	if transfer <= 0 {
+		return fmt.Errorf("%w: TRANSFER_BUDGET=%s", ErrInvalidDuration, transfer)
+	}
+
+	cfg.TransferBudget = transfer
+
+	return nil
+}
+
+func loadIdempotency(cfg *Config) error {
+	ttl, err := parseDurationEnv("IDEMPOTENCY_TTL", defaultIdempotencyTTL)
+	if err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		return fmt.Errorf("%w: IDEMPOTENCY_TTL=%s", ErrInvalidDuration, ttl)
+	}
+
+	cfg.IdempotencyTTL = ttl
+
 	return nil
 }
 
@@ -347,3 +506,198 @@ func loadCORS(cfg *Config) {
 		cfg.CORSAllowedOrigins = append(cfg.CORSAllowedOrigins, origin)
 	}
 }
+
+func loadQuota(cfg *Config) error {
+	maxLinks, err := parseIntEnv("QUOTA_MAX_LINKS", 0)
+	if err != nil {
+		return err
+	}
+
+	maxClicks, err := parseIntEnv("QUOTA_MAX_MONTHLY_CLICKS", 0)
+	if err != nil {
+		return err
+	}
+
+	if maxLinks < 0 || maxClicks < 0 {
+		return fmt.Errorf("%w: links=%d monthly_clicks=%d", ErrInvalidQuota, maxLinks, maxClicks)
+	}
+
+	block, err := parseBoolEnv("QUOTA_BLOCK_REDIRECTS", false)
+	if err != nil {
+		return err
+	}
+
+	cfg.QuotaMaxLinks = int64(maxLinks)
+	cfg.QuotaMaxMonthlyClicks = int64(maxClicks)
+	cfg.QuotaBlockRedirects = block
+
+	return nil
+}
+
+func loadURLNormalization(cfg *Config) error {
+	sortQuery, err := parseBoolEnv("URL_NORMALIZE_SORT_QUERY", false)
+	if err != nil {
+		return err
+	}
+
+	stripTracking, err := parseBoolEnv("URL_NORMALIZE_STRIP_TRACKING", false)
+	if err != nil {
+		return err
+	}
+
+	cfg.URLSortQuery = sortQuery
+	cfg.URLStripTracking = stripTracking
+
+	return nil
+}
+
+func loadURLPolicy(cfg *Config) error {
+	blockPrivate, err := parseBoolEnv("URL_BLOCK_PRIVATE", true)
+	if err != nil {
+		return err
+	}
+
+	cfg.URLBlockPrivate = blockPrivate
+
+	selfHosts := splitList(env("URL_SELF_HOSTS"))
+	cfg.URLAllowedDomains = splitList(env("URL_ALLOWED_DOMAINS"))
+	cfg.URLDeniedDomains = splitList(env("URL_DENIED_DOMAINS"))
+
+	for _, list := range [][]string{selfHosts, cfg.URLAllowedDomains, cfg.URLDeniedDomains} {
+		for _, pattern := range list {
+			if !validDomainPattern(pattern) {
+				return fmt.Errorf("%w: %q", ErrInvalidDomainPattern, pattern)
+			}
+		}
+	}
+
+	// BASE_URL was validated above, so it parses.
+	base, _ := url.Parse(cfg.BaseURL)
+	cfg.URLSelfHosts = append([]string{base.Hostname()}, selfHosts...)
+
+	return nil
+}
+
+func loadBlocklist(cfg *Config) error {
+	cfg.BlocklistFile = env("BLOCKLIST_FILE")
+
+	reload, err := parseDurationEnv("BLOCKLIST_RELOAD_INTERVAL", defaultBlocklistReloadInterval)
+	if err != nil {
+		return err
+	}
+
+	rescan, err := parseDurationEnv("BLOCKLIST_RESCAN_INTERVAL", defaultBlocklistRescanInterval)
+	if err != nil {
+		return err
+	}
+
+	if reload <= 0 || rescan <= 0 {
+		return fmt.Errorf("%w: blocklist reload=%s rescan=%s", ErrInvalidDuration, reload, rescan)
+	}
+
+	cfg.BlocklistReloadInterval = reload
+	cfg.BlocklistRescanInterval = rescan
+
+	return nil
+}
+
+func loadShortNames(cfg *Config) error {
+	cfg.ShortNameDenylistFile = env("SHORT_NAME_DENYLIST_FILE")
+
+	foldCase, err := parseBoolEnv("SHORT_NAME_CASE_INSENSITIVE", false)
+	if err != nil {
+		return err
+	}
+
+	cfg.ShortNameCaseInsensitive = foldCase
+
+	cfg.ShortNameStrategy = getEnv("SHORT_NAME_STRATEGY", defaultShortNameStrategy)
+	if _, ok := shortNameStrategies[cfg.ShortNameStrategy]; !ok {
+		return fmt.Errorf("%w: strategy %q", ErrInvalidShortNames, cfg.ShortNameStrategy)
+	}
+
+	length, err := parseIntEnv("SHORT_NAME_LENGTH", defaultShortNameLength)
+	if err != nil {
+		return err
+	}
+
+	minLength, err := parseIntEnv("SHORT_NAME_HASHID_MIN_LENGTH", defaultShortNameHashIDMinLength)
+	if err != nil {
+		return err
+	}
+
+	// Longer hashid minimums would overflow the offset added to sequence values.
+	if length < 3 || length > 32 || minLength < 3 || minLength > 12 {
+		return fmt.Errorf("%w: length=%d hashid_min_length=%d", ErrInvalidShortNames, length, minLength)
+	}
+
+	cfg.ShortNameLength = length
+	cfg.ShortNameHashIDMinLength = minLength
+	cfg.ShortNameHashIDSalt = env("SHORT_NAME_HASHID_SALT")
+
+	cfg.ShortNameAlphabet = env("SHORT_NAME_ALPHABET")
+	if cfg.ShortNameAlphabet != "" && !validShortNameAlphabet(cfg.ShortNameAlphabet) {
+		return fmt.Errorf("%w: alphabet %q", ErrInvalidShortNames, cfg.ShortNameAlphabet)
+	}
+
+	return nil
+}
+
+func loadDomains(cfg *Config) error {
+	fallback := strings.ToLower(env("DOMAIN_FALLBACK"))
+	if fallback != "" && (strings.HasPrefix(fallback, "*.") || !validDomainPattern(fallback)) {
+		return fmt.Errorf("%w: DOMAIN_FALLBACK=%q", ErrInvalidDomainPattern, fallback)
+	}
+
+	cfg.DomainFallback = strings.TrimSuffix(fallback, ".")
+
+	return nil
+}
+
+func loadRedirectPath(cfg *Config) error {
+	mode := strings.ToLower(getEnv("REDIRECT_PATH_MODE", RedirectPathPrefixed))
+	if mode != RedirectPathPrefixed && mode != RedirectPathRoot {
+		return fmt.Errorf("%w: %q", ErrInvalidRedirectPathMode, mode)
+	}
+
+	cfg.RedirectPathMode = mode
+
+	return nil
+}
+
+// validShortNameAlphabet accepts at least two distinct ASCII letters and
+// digits.
+func validShortNameAlphabet(alphabet string) bool {
+	seen := make(map[rune]struct{}, len(alphabet))
+
+	for _, r := range alphabet {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if _, dup := seen[r]; dup || !isAlnum {
+			return false
+		}
+
+		seen[r] = struct{}{}
+	}
+
+	return len(seen) >= 2
+}
+
+// validDomainPattern accepts a host name, optionally with a leading "*."
+// wildcard label.
+func validDomainPattern(pattern string) bool {
+	name := strings.TrimPrefix(strings.TrimSuffix(pattern, "."), "*.")
+
+	return name != "" && !strings.ContainsAny(name, "*/:@ ")
+}
+
+func splitList(raw string) []string {
+	var out []string
+
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+
+	return out
+}
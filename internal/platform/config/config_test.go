@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -52,6 +53,200 @@ func TestLoad_RequestBudgetInvalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestLoad_TransferBudgetInvalid(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "http://localhost:8080")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+	t.Setenv("SENTRY_DSN", "https://public@o0.ingest.sentry.io/0")
+
+	t.Setenv("TRANSFER_BUDGET", "-1m")
+
+	_, err := config.Load()
+	require.ErrorIs(t, err, config.ErrInvalidDuration)
+}
+
+func TestLoad_IdempotencyTTLInvalid(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "http://localhost:8080")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+	t.Setenv("SENTRY_DSN", "https://public@o0.ingest.sentry.io/0")
+
+	t.Setenv("IDEMPOTENCY_TTL", "0s")
+
+	_, err := config.Load()
+	require.ErrorIs(t, err, config.ErrInvalidDuration)
+}
+
+func TestLoad_URLPolicy(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "https://sho.rt")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+	t.Setenv("SENTRY_DSN", "https://public@o0.ingest.sentry.io/0")
+
+	t.Setenv("URL_SELF_HOSTS", "go.example.com, ")
+	t.Setenv("URL_DENIED_DOMAINS", "*.evil.test,bad.test")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.True(t, cfg.URLBlockPrivate)
+	require.Equal(t, []string{"sho.rt", "go.example.com"}, cfg.URLSelfHosts)
+	require.Equal(t, []string{"*.evil.test", "bad.test"}, cfg.URLDeniedDomains)
+	require.Empty(t, cfg.URLAllowedDomains)
+
+	for _, bad := range []string{"*", "a.*.com", "example.com/path", "example.com:80"} {
+		t.Setenv("URL_ALLOWED_DOMAINS", bad)
+
+		_, err = config.Load()
+		require.ErrorIs(t, err, config.ErrInvalidDomainPattern, bad)
+	}
+}
+
 func TestMainEnvDoesNotLeak(t *testing.T) {
 	require.NotEqual(t, "", os.Getenv("PATH"))
 }
+
+func TestLoad_QuotaInvalid(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "http://localhost:8080")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+	t.Setenv("SENTRY_DSN", "")
+
+	t.Setenv("QUOTA_MAX_LINKS", "-1")
+
+	_, err := config.Load()
+	require.ErrorIs(t, err, config.ErrInvalidQuota)
+}
+
+func TestLoad_Blocklist(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "http://localhost:8080")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.BlocklistFile)
+	require.Equal(t, 30*time.Second, cfg.BlocklistReloadInterval)
+	require.Equal(t, time.Hour, cfg.BlocklistRescanInterval)
+
+	t.Setenv("BLOCKLIST_FILE", "/etc/shortener/blocklist.txt")
+	t.Setenv("BLOCKLIST_RESCAN_INTERVAL", "15m")
+
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	require.Equal(t, "/etc/shortener/blocklist.txt", cfg.BlocklistFile)
+	require.Equal(t, 15*time.Minute, cfg.BlocklistRescanInterval)
+
+	t.Setenv("BLOCKLIST_RELOAD_INTERVAL", "0s")
+
+	_, err = config.Load()
+	require.ErrorIs(t, err, config.ErrInvalidDuration)
+}
+
+func TestLoad_GeoIP(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "http://localhost:8080")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.GeoIPFile)
+
+	t.Setenv("GEOIP_FILE", "/var/lib/shortener/dbip-country-lite.csv")
+
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/shortener/dbip-country-lite.csv", cfg.GeoIPFile)
+}
+
+func TestLoad_ShortNames(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "https://sho.rt")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+	t.Setenv("SENTRY_DSN", "https://public@o0.ingest.sentry.io/0")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Equal(t, "random", cfg.ShortNameStrategy)
+	require.Equal(t, 8, cfg.ShortNameLength)
+	require.Equal(t, 6, cfg.ShortNameHashIDMinLength)
+	require.Empty(t, cfg.ShortNameAlphabet)
+	require.False(t, cfg.ShortNameCaseInsensitive)
+
+	t.Setenv("SHORT_NAME_STRATEGY", "hashid")
+	t.Setenv("SHORT_NAME_ALPHABET", "abcdefghjkmnpqrstuvwxyz23456789")
+	t.Setenv("SHORT_NAME_HASHID_SALT", "pepper")
+	t.Setenv("SHORT_NAME_CASE_INSENSITIVE", "true")
+
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	require.Equal(t, "hashid", cfg.ShortNameStrategy)
+	require.Equal(t, "pepper", cfg.ShortNameHashIDSalt)
+	require.True(t, cfg.ShortNameCaseInsensitive)
+
+	bad := map[string]string{
+		"SHORT_NAME_STRATEGY":          "uuid",
+		"SHORT_NAME_LENGTH":            "2",
+		"SHORT_NAME_HASHID_MIN_LENGTH": "13",
+		"SHORT_NAME_ALPHABET":          "abca",
+	}
+
+	for key, value := range bad {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+
+			_, err := config.Load()
+			require.ErrorIs(t, err, config.ErrInvalidShortNames)
+		})
+	}
+
+	t.Setenv("SHORT_NAME_ALPHABET", "ab-c")
+
+	_, err = config.Load()
+	require.ErrorIs(t, err, config.ErrInvalidShortNames)
+}
+
+func TestLoad_DomainFallback(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "https://sho.rt")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.DomainFallback)
+
+	t.Setenv("DOMAIN_FALLBACK", "Go.Example.com.")
+
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", cfg.DomainFallback)
+
+	for _, value := range []string{"*.example.com", "go.example.com:8080", "https://go.example.com"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("DOMAIN_FALLBACK", value)
+
+			_, err := config.Load()
+			require.ErrorIs(t, err, config.ErrInvalidDomainPattern)
+		})
+	}
+}
+
+func TestLoad_RedirectPathMode(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "8080")
+	t.Setenv("BASE_URL", "https://sho.rt")
+	t.Setenv("DATABASE_URL", "postgres://x:y@localhost:5432/db?sslmode=disable")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Equal(t, config.RedirectPathPrefixed, cfg.RedirectPathMode)
+
+	t.Setenv("REDIRECT_PATH_MODE", "Root")
+
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	require.Equal(t, config.RedirectPathRoot, cfg.RedirectPathMode)
+
+	t.Setenv("REDIRECT_PATH_MODE", "/")
+
+	_, err = config.Load()
+	require.ErrorIs(t, err, config.ErrInvalidRedirectPathMode)
+}
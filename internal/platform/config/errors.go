@@ -10,6 +10,13 @@ var (
 
 	ErrInvalidDuration = errors.New("invalid duration env")
 	ErrInvalidInt      = errors.New("invalid int env")
+	ErrInvalidBool     = errors.New("invalid bool env")
 
 	ErrInvalidDBPool = errors.New("invalid db pool config")
+	ErrInvalidQuota  = errors.New("invalid quota config")
+
+	ErrInvalidDomainPattern = errors.New("invalid domain pattern")
+	ErrInvalidShortNames    = errors.New("invalid short name config")
+
+	ErrInvalidRedirectPathMode = errors.New("invalid REDIRECT_PATH_MODE")
 )